@@ -103,11 +103,13 @@ func main() {
 	log.Println("\n🗄️  Initializing repositories...")
 	stopRepo := postgres.NewStopRepository(db)
 	segmentRepo := postgres.NewSegmentRepository(db)
+	cityRepo := postgres.NewCityRepository(db)
+	fareRuleRepo := postgres.NewFareRuleRepository(db)
 	log.Println("✓ Repositories initialized")
 
 	// Create sync service
 	log.Println("\n🔄 Creating sync service...")
-	syncer := syncpkg.New(garsClient, aviasalesClient, rzdClient, stopRepo, segmentRepo)
+	syncer := syncpkg.New(garsClient, aviasalesClient, rzdClient, stopRepo, segmentRepo, cityRepo, fareRuleRepo)
 	log.Println("✓ Sync service created")
 
 	// Check current data
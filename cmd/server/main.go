@@ -13,6 +13,7 @@ import (
 	"github.com/joho/godotenv"
 	httphandler "github.com/lenalink/backend/internal/handler/http"
 	"github.com/lenalink/backend/internal/config"
+	"github.com/lenalink/backend/internal/domain"
 	postgres "github.com/lenalink/backend/internal/repository/postgres"
 	"github.com/lenalink/backend/internal/service"
 	"github.com/lenalink/backend/pkg/utils"
@@ -72,13 +73,47 @@ func main() {
 	log.Println("🗄️  Initializing repositories...")
 	routeRepo := postgres.NewRouteRepository(db)
 	bookingRepo := postgres.NewBookingRepository(db)
+	bookingChangeRepo := postgres.NewBookingChangeRepository(db)
+	segmentRepo := postgres.NewSegmentRepository(db)
+	stopRepo := postgres.NewStopRepository(db)
+	cityRepo := postgres.NewCityRepository(db)
+	seatRepo := postgres.NewSeatInventoryRepository(db)
+	sagaRepo := postgres.NewSagaRepository(db)
+	fareRuleRepo := postgres.NewFareRuleRepository(db)
+	disruptionRepo := postgres.NewDisruptionRepository(db)
+	insuranceClaimRepo := postgres.NewInsuranceClaimRepository(db)
+	webhookEventRepo := postgres.NewWebhookEventRepository(db)
 	log.Println("✓ Repositories initialized")
 
 	// Initialize services
 	log.Println("⚙️  Initializing services...")
-	routeService := service.NewRouteService(routeRepo)
+	exchangeRates, err := domain.ParseExchangeRates(cfg.Payment.ExchangeRates)
+	if err != nil {
+		log.Fatalf("Invalid exchange rates: %v", err)
+	}
+	routeSearchConfig := service.DefaultRouteSearchConfig()
+	routeSearchConfig.ExchangeRates = exchangeRates
+	routeSearchConfig.MinTransferTime = cfg.Routing.MinTransferTime
+	routeSearchConfig.SearchWindow = cfg.Routing.SearchWindow
+	routeSearchConfig.Transfers.MaxWalkDistance = float64(cfg.Routing.WalkRadius) / 1000
+	routeSearchConfig.Transfers.MaxTaxiDistance = float64(cfg.Routing.TaxiRadius)
+	routeSearchConfig.ConnectionRules.Default = cfg.Routing.MinTransferTime
+	if cfg.Routing.ConnectionRules != "" {
+		rules, err := service.LoadMinConnectionTable(cfg.Routing.ConnectionRules, cfg.Routing.MinTransferTime)
+		if err != nil {
+			log.Fatalf("Failed to load connection rules: %v", err)
+		}
+		routeSearchConfig.ConnectionRules = rules
+		log.Printf("✓ Loaded %d minimum connection time rules", len(rules.Rules))
+	}
+	cityResolver := service.NewCityResolver(cityRepo)
+	routeService := service.NewRouteService(routeRepo, segmentRepo, stopRepo, cityResolver, routeCache, routeSearchConfig)
+	stopService := service.NewStopService(stopRepo)
 	commissionSvc := service.NewCommissionService(service.DefaultCommissionConfig())
-	insuranceSvc := service.NewInsuranceService(service.DefaultInsuranceConfig())
+	insuranceConfig := service.DefaultInsuranceConfig()
+	insuranceConfig.ConnectionRules = routeSearchConfig.ConnectionRules
+	insuranceSvc := service.NewInsuranceService(insuranceConfig, service.NewMockInsuranceProvider())
+	fareRuleSvc := service.NewFareRuleService(fareRuleRepo, commissionSvc)
 
 	// Initialize payment gateway based on configuration
 	var paymentGateway service.PaymentGateway
@@ -96,21 +131,104 @@ func main() {
 		log.Println("✓ Mock gateway initialized (for development)")
 	}
 
-	paymentSvc := service.NewPaymentService(paymentGateway)
+	// YooKassa (or the mock) takes card and YooKassa payments; other methods get their own gateway
+	gateways := service.NewGatewayRegistry(paymentGateway)
+	if cfg.CloudPayments.PublicID != "" && cfg.CloudPayments.APISecret != "" {
+		gateways.Register(domain.PaymentCloudPay, service.NewCloudPaymentsGateway(
+			cfg.CloudPayments.BaseURL,
+			cfg.CloudPayments.PublicID,
+			cfg.CloudPayments.APISecret,
+		))
+		log.Println("✓ CloudPayments gateway initialized")
+	}
+	if cfg.SberPay.UserName != "" && cfg.SberPay.Password != "" {
+		gateways.Register(domain.PaymentSberPay, service.NewSberPayGateway(
+			cfg.SberPay.BaseURL,
+			cfg.SberPay.UserName,
+			cfg.SberPay.Password,
+			cfg.SberPay.CallbackToken,
+			cfg.SberPay.ReturnURL,
+		))
+		log.Println("✓ SberPay gateway initialized")
+	}
+
+	paymentSvc := service.NewPaymentService(gateways)
 	providerBooking := service.NewMockProviderBookingService(0.0)
+	seatSvc := service.NewSeatInventoryService(seatRepo, service.DefaultSeatHoldConfig())
+	sagaSvc := service.NewSagaService(sagaRepo, bookingRepo, providerBooking, seatSvc, service.DefaultSagaConfig())
+	bookingConfig := service.DefaultBookingConfig()
+	bookingConfig.PaymentFlow = service.PaymentFlow(cfg.Payment.Flow)
+	bookingConfig.ExchangeRates = exchangeRates
 	bookingService := service.NewBookingService(
-		routeRepo,
+		routeService,
+		segmentRepo,
 		bookingRepo,
+		bookingChangeRepo,
 		commissionSvc,
 		insuranceSvc,
 		paymentSvc,
 		providerBooking,
+		seatSvc,
+		sagaSvc,
+		fareRuleSvc,
+		bookingConfig,
+	)
+	paymentExpiryConfig := service.DefaultPaymentExpiryConfig()
+	paymentExpiryConfig.PollAfter = cfg.Payment.PollAfter
+	paymentExpiryConfig.TTL = cfg.Payment.TTL
+	paymentExpirySvc := service.NewPaymentExpiryService(bookingRepo, paymentSvc, bookingService, paymentExpiryConfig)
+	disruptionConfig := service.DefaultDisruptionConfig()
+	disruptionConfig.ConnectionRules = routeSearchConfig.ConnectionRules
+	disruptionSvc := service.NewDisruptionService(
+		bookingRepo,
+		segmentRepo,
+		disruptionRepo,
+		routeService,
+		bookingService,
+		service.NewMockNotifier(),
+		disruptionConfig,
 	)
+	claimConfig := service.DefaultInsuranceClaimConfig()
+	claimConfig.ConnectionRules = routeSearchConfig.ConnectionRules
+	claimConfig.Receipts = bookingConfig.Receipts
+	claimSvc := service.NewInsuranceClaimService(
+		bookingRepo,
+		segmentRepo,
+		insuranceClaimRepo,
+		insuranceSvc,
+		bookingService,
+		paymentSvc,
+		claimConfig,
+	)
+	webhookConfig := service.DefaultWebhookConfig()
+	if cfg.YooKassa.WebhookIPs != "" {
+		allowlist, err := service.ParseAllowlist(cfg.YooKassa.WebhookIPs)
+		if err != nil {
+			log.Fatalf("Invalid YOOKASSA_WEBHOOK_IPS: %v", err)
+		}
+		webhookConfig.Allowlist = allowlist
+	}
+	if cfg.YooKassa.ProxyIPs != "" {
+		proxies, err := service.ParseAllowlist(cfg.YooKassa.ProxyIPs)
+		if err != nil {
+			log.Fatalf("Invalid YOOKASSA_WEBHOOK_PROXIES: %v", err)
+		}
+		webhookConfig.TrustedProxies = proxies
+	}
+	webhookSvc := service.NewWebhookService(webhookEventRepo, paymentSvc, bookingService, webhookConfig)
 	log.Println("✓ Services initialized")
 
+	// Compensate bookings interrupted by the previous shutdown or crash
+	recovered, err := sagaSvc.Recover(context.Background())
+	if err != nil {
+		log.Printf("Warning: failed to recover booking sagas: %v", err)
+	} else if recovered > 0 {
+		log.Printf("✓ Recovered %d unfinished booking sagas", recovered)
+	}
+
 	// Initialize router with handlers
 	log.Println("🛣️  Setting up HTTP routes...")
-	router := httphandler.NewRouter(routeService, bookingService, paymentSvc)
+	router := httphandler.NewRouter(routeService, stopService, bookingService, paymentSvc, sagaSvc, fareRuleSvc, disruptionSvc, claimSvc, webhookSvc)
 	log.Println("✓ HTTP routes configured")
 
 	// Server configuration
@@ -126,6 +244,49 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// Background jobs stop with the server
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Release seat holds whose booking was never paid
+	go runPeriodic(jobsCtx, seatSvc.SweepInterval(), "release expired seat holds", func(ctx context.Context) error {
+		released, err := seatSvc.ReleaseExpired(ctx)
+		if released > 0 {
+			log.Printf("Released %d expired seat holds", released)
+		}
+		return err
+	})
+
+	// Retry failed booking compensations with backoff
+	go runPeriodic(jobsCtx, sagaSvc.RetryInterval(), "retry booking compensations", sagaSvc.RetryCompensations)
+
+	// Settle bookings whose payment webhook is late or lost, expire unpaid ones
+	go runPeriodic(jobsCtx, paymentExpirySvc.SweepInterval(), "reconcile pending payments", func(ctx context.Context) error {
+		settled, err := paymentExpirySvc.ReconcilePending(ctx)
+		if settled > 0 {
+			log.Printf("Settled %d bookings awaiting payment", settled)
+		}
+		return err
+	})
+
+	// Retry refunds of cancelled tickets that failed at the gateway
+	go runPeriodic(jobsCtx, paymentExpirySvc.SweepInterval(), "retry ticket refunds", func(ctx context.Context) error {
+		refunded, err := bookingService.RetryRefunds(ctx)
+		if refunded > 0 {
+			log.Printf("Refunded %d cancelled tickets", refunded)
+		}
+		return err
+	})
+
+	// Re-route journeys broken by cancelled or delayed segments
+	go runPeriodic(jobsCtx, disruptionSvc.CheckInterval(), "check bookings for disruptions", func(ctx context.Context) error {
+		handled, err := disruptionSvc.CheckBookings(ctx)
+		if handled > 0 {
+			log.Printf("Handled %d journey disruptions", handled)
+		}
+		return err
+	})
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("🌐 HTTP server listening on http://%s\n", server.Addr)
@@ -153,6 +314,7 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	stopJobs()
 	log.Println("🛑 Shutting down server gracefully...")
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
@@ -163,3 +325,20 @@ func main() {
 	routeCache.Stop()
 	log.Println("✓ Server stopped gracefully")
 }
+
+// runPeriodic runs fn every interval until ctx is cancelled; failures are logged and
+// retried on the next tick
+func runPeriodic(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("Warning: failed to %s: %v", name, err)
+			}
+		}
+	}
+}
@@ -3,12 +3,9 @@ module github.com/lenalink/backend
 go 1.22
 
 require (
+	github.com/google/uuid v1.3.1
 	github.com/gorilla/mux v1.8.1
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
-)
-
-require (
-	github.com/google/uuid v1.3.1 // indirect
-	github.com/rvinnie/yookassa-sdk-go v0.1.4 // indirect
+	github.com/rvinnie/yookassa-sdk-go v0.1.4
 )
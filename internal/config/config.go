@@ -9,10 +9,14 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
-	YooKassa YooKassaConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Logger        LoggerConfig
+	YooKassa      YooKassaConfig
+	CloudPayments CloudPaymentsConfig
+	SberPay       SberPayConfig
+	Payment       PaymentConfig
+	Routing       RoutingConfig
 }
 
 // ServerConfig represents HTTP server configuration
@@ -53,6 +57,42 @@ type YooKassaConfig struct {
 	WebhookURL string
 	ReturnURL  string
 	TestMode   bool
+	WebhookIPs string // Comma-separated networks notifications are accepted from (empty = any)
+	ProxyIPs   string // Comma-separated proxies trusted to forward the client address (X-Forwarded-For)
+}
+
+// CloudPaymentsConfig represents CloudPayments payment gateway configuration
+type CloudPaymentsConfig struct {
+	PublicID  string
+	APISecret string // Also signs notifications
+	BaseURL   string
+}
+
+// SberPayConfig represents SberPay (Sberbank internet acquiring) payment gateway configuration
+type SberPayConfig struct {
+	UserName      string
+	Password      string
+	CallbackToken string // Signs callbacks
+	BaseURL       string
+	ReturnURL     string
+}
+
+// PaymentConfig represents the payment flow and reconciliation of payments confirmed by redirect
+type PaymentConfig struct {
+	Flow      string        // ticket_first (issue tickets, then charge) or pay_first (authorize, issue tickets, capture)
+	PollAfter time.Duration // Pending payments this old are checked with the gateway
+	TTL       time.Duration // Pending payments this old expire and their booking fails
+
+	ExchangeRates string // Rubles per unit of other currencies providers quote in, e.g. "USD=92.5,EUR=100.1"
+}
+
+// RoutingConfig represents live route composition configuration
+type RoutingConfig struct {
+	MinTransferTime time.Duration // Minimum time between arrival and next departure
+	SearchWindow    time.Duration // How far ahead of the departure date segments are considered
+	WalkRadius      int           // Meters; nearby stops within it are connected on foot
+	TaxiRadius      int           // Kilometers; nearby stops within it are connected by taxi
+	ConnectionRules string        // Path to a JSON minimum connection time table (empty = built-in rules)
 }
 
 // Load loads configuration from environment variables and defaults
@@ -89,6 +129,34 @@ func Load() *Config {
 			WebhookURL: getEnv("YOOKASSA_WEBHOOK_URL", ""),
 			ReturnURL:  getEnv("YOOKASSA_RETURN_URL", "http://localhost:3000/payment/success"),
 			TestMode:   getEnvBool("YOOKASSA_TEST_MODE", true),
+			WebhookIPs: getEnv("YOOKASSA_WEBHOOK_IPS", ""),
+			ProxyIPs:   getEnv("YOOKASSA_WEBHOOK_PROXIES", ""),
+		},
+		CloudPayments: CloudPaymentsConfig{
+			PublicID:  getEnv("CLOUDPAYMENTS_PUBLIC_ID", ""),
+			APISecret: getEnv("CLOUDPAYMENTS_API_SECRET", ""),
+			BaseURL:   getEnv("CLOUDPAYMENTS_BASE_URL", "https://api.cloudpayments.ru"),
+		},
+		SberPay: SberPayConfig{
+			UserName:      getEnv("SBERPAY_USERNAME", ""),
+			Password:      getEnv("SBERPAY_PASSWORD", ""),
+			CallbackToken: getEnv("SBERPAY_CALLBACK_TOKEN", ""),
+			BaseURL:       getEnv("SBERPAY_BASE_URL", "https://securepayments.sberbank.ru/payment/rest"),
+			ReturnURL:     getEnv("SBERPAY_RETURN_URL", "http://localhost:3000/payment/success"),
+		},
+		Payment: PaymentConfig{
+			Flow:      getEnv("PAYMENT_FLOW", "ticket_first"),
+			PollAfter: getEnvDuration("PAYMENT_POLL_AFTER", 2*time.Minute),
+			TTL:       getEnvDuration("PAYMENT_TTL", 15*time.Minute),
+
+			ExchangeRates: getEnv("EXCHANGE_RATES", ""),
+		},
+		Routing: RoutingConfig{
+			MinTransferTime: getEnvDuration("ROUTING_MIN_TRANSFER_TIME", 60*time.Minute),
+			SearchWindow:    getEnvDuration("ROUTING_SEARCH_WINDOW", 72*time.Hour),
+			WalkRadius:      getEnvInt("ROUTING_WALK_RADIUS_M", 1000),
+			TaxiRadius:      getEnvInt("ROUTING_TAXI_RADIUS_KM", 30),
+			ConnectionRules: getEnv("ROUTING_CONNECTION_RULES", ""),
 		},
 	}
 }
@@ -163,6 +231,10 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Payment.Flow != "ticket_first" && c.Payment.Flow != "pay_first" {
+		return fmt.Errorf("invalid payment flow: %s (expected ticket_first or pay_first)", c.Payment.Flow)
+	}
+
 	// YooKassa configuration warning (not required for development)
 	if c.YooKassa.ShopID == "" {
 		fmt.Println("Warning: YooKassa not configured - using mock payment gateway")
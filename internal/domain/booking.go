@@ -6,22 +6,24 @@ import "time"
 type BookingStatus string
 
 const (
-	BookingPending        BookingStatus = "pending"         // Awaiting payment
-	BookingPendingPayment BookingStatus = "pending_payment" // Waiting for user to complete payment (YooKassa redirect)
-	BookingConfirmed      BookingStatus = "confirmed"       // Payment successful, all segments booked
-	BookingFailed         BookingStatus = "failed"          // Booking or payment failed
-	BookingCancelled      BookingStatus = "cancelled"       // User cancelled
-	BookingRefunded       BookingStatus = "refunded"        // Refund processed
+	BookingPending            BookingStatus = "pending"             // Awaiting payment
+	BookingPendingPayment     BookingStatus = "pending_payment"     // Waiting for user to complete payment (YooKassa redirect)
+	BookingConfirmed          BookingStatus = "confirmed"           // Payment successful, all segments booked
+	BookingFailed             BookingStatus = "failed"              // Booking or payment failed
+	BookingCancelled          BookingStatus = "cancelled"           // User cancelled
+	BookingPartiallyCancelled BookingStatus = "partially_cancelled" // Some tickets cancelled, the rest still valid
+	BookingRefunded           BookingStatus = "refunded"            // Refund processed
 )
 
 // PaymentStatus represents the status of a payment
 type PaymentStatus string
 
 const (
-	PaymentPending   PaymentStatus = "pending"
-	PaymentCompleted PaymentStatus = "completed"
-	PaymentFailed    PaymentStatus = "failed"
-	PaymentRefunded  PaymentStatus = "refunded"
+	PaymentPending    PaymentStatus = "pending"
+	PaymentAuthorized PaymentStatus = "authorized" // Funds held by the gateway, not captured yet (two-stage payment)
+	PaymentCompleted  PaymentStatus = "completed"
+	PaymentFailed     PaymentStatus = "failed"
+	PaymentRefunded   PaymentStatus = "refunded"
 )
 
 // PaymentMethod represents payment method
@@ -34,41 +36,58 @@ const (
 	PaymentSberPay    PaymentMethod = "sberpay"
 )
 
+// PassengerType represents the fare category of a passenger
+type PassengerType string
+
+const (
+	PassengerAdult  PassengerType = "adult"  // 12 years and older
+	PassengerChild  PassengerType = "child"  // 2 to 11 years
+	PassengerInfant PassengerType = "infant" // Under 2 years, travels on an adult's lap
+)
+
 // Passenger represents a passenger
 type Passenger struct {
-	FirstName      string    `json:"first_name"`
-	LastName       string    `json:"last_name"`
-	MiddleName     string    `json:"middle_name,omitempty"`
-	DateOfBirth    time.Time `json:"date_of_birth"`
-	PassportNumber string    `json:"passport_number"`
-	Email          string    `json:"email"`
-	Phone          string    `json:"phone"`
+	ID             string        `json:"id"`
+	Type           PassengerType `json:"type"`
+	FirstName      string        `json:"first_name"`
+	LastName       string        `json:"last_name"`
+	MiddleName     string        `json:"middle_name,omitempty"`
+	DateOfBirth    time.Time     `json:"date_of_birth"`
+	PassportNumber string        `json:"passport_number"` // Birth certificate number for children
+	Email          string        `json:"email,omitempty"` // Required for the lead passenger only
+	Phone          string        `json:"phone,omitempty"`
 }
 
 // BookedSegment represents a single booked segment in a multi-segment journey
 type BookedSegment struct {
-	ID              string        `json:"id"`
-	SegmentID       string        `json:"segment_id"`       // Reference to original segment
-	Provider        string        `json:"provider"`         // Provider who issued ticket
-	TransportType   TransportType `json:"transport_type"`
-	From            Stop          `json:"from"`
-	To              Stop          `json:"to"`
-	DepartureTime   time.Time     `json:"departure_time"`
-	ArrivalTime     time.Time     `json:"arrival_time"`
-	TicketNumber    string        `json:"ticket_number"`    // Ticket issued by provider
-	Price           float64       `json:"price"`            // Provider's price
-	Commission      float64       `json:"commission"`       // Our markup
-	TotalPrice      float64       `json:"total_price"`      // price + commission
-	BookingStatus   BookingStatus `json:"booking_status"`
-	ProviderBookingRef string     `json:"provider_booking_ref"` // Provider's booking reference
+	ID                 string        `json:"id"`
+	SegmentID          string        `json:"segment_id"`   // Reference to original segment
+	PassengerID        string        `json:"passenger_id"` // Passenger the ticket is issued to
+	Provider           string        `json:"provider"`     // Provider who issued ticket
+	TransportType      TransportType `json:"transport_type"`
+	From               Stop          `json:"from"`
+	To                 Stop          `json:"to"`
+	DepartureTime      time.Time     `json:"departure_time"`
+	ArrivalTime        time.Time     `json:"arrival_time"`
+	TicketNumber       string        `json:"ticket_number"` // Ticket issued by provider
+	Price              Money         `json:"price"`         // Provider's price
+	Commission         Money         `json:"commission"`    // Our markup
+	TotalPrice         Money         `json:"total_price"`   // price + commission
+	BookingStatus      BookingStatus `json:"booking_status"`
+	ProviderBookingRef string        `json:"provider_booking_ref"`    // Provider's booking reference
+	Source             string        `json:"source,omitempty"`        // Fare rule provider (see Segment.Source)
+	Tariff             string        `json:"tariff,omitempty"`        // Fare rule tariff
+	RefundAmount       Money         `json:"refund_amount,omitempty"` // Returned to the customer when cancelled
+	RefundPending      bool          `json:"refund_pending,omitempty"` // RefundAmount failed at the gateway and is retried
+	CancelledAt        *time.Time    `json:"cancelled_at,omitempty"`
+	ReplacedBy         string        `json:"replaced_by,omitempty"` // Ticket that replaced this one in a booking change
 }
 
 // Payment represents a payment transaction
 type Payment struct {
 	ID                string        `json:"id"`
 	OrderID           string        `json:"order_id"`
-	Amount            float64       `json:"amount"`
-	Currency          string        `json:"currency"`
+	Amount            Money         `json:"amount"` // In the settlement currency
 	Method            PaymentMethod `json:"method"`
 	Status            PaymentStatus `json:"status"`
 	ProviderPaymentID string        `json:"provider_payment_id,omitempty"` // Payment gateway transaction ID
@@ -76,61 +95,165 @@ type Payment struct {
 	CreatedAt         time.Time     `json:"created_at"`
 	CompletedAt       *time.Time    `json:"completed_at,omitempty"`
 	FailureReason     string        `json:"failure_reason,omitempty"`
+	RefundedAmount    Money         `json:"refunded_amount,omitempty"` // Sum of partial and full refunds
+	TwoStage          bool          `json:"two_stage,omitempty"`       // Authorized first, captured once the tickets are issued
+	Receipt           *Receipt      `json:"receipt,omitempty"`         // Fiscal receipt (54-FZ) of everything paid
+}
+
+// RefundableAmount returns what has been paid and not yet refunded
+func (p *Payment) RefundableAmount() Money {
+	return p.Amount.Sub(p.RefundedAmount)
 }
 
 // Booking represents a complete multi-segment booking
 type Booking struct {
 	ID                string          `json:"id"` // Order ID
 	RouteID           string          `json:"route_id"`
-	Passenger         Passenger       `json:"passenger"`
-	Segments          []BookedSegment `json:"segments"`
-	TotalPrice        float64         `json:"total_price"`        // Sum of all segment prices
-	TotalCommission   float64         `json:"total_commission"`   // Sum of all commissions
-	GrandTotal        float64         `json:"grand_total"`        // totalPrice + totalCommission
-	InsurancePremium  float64         `json:"insurance_premium,omitempty"`
+	Passenger         Passenger       `json:"passenger"`  // Lead passenger: contact details and payer
+	Passengers        []Passenger     `json:"passengers"` // Everyone travelling, lead passenger first
+	Segments          []BookedSegment `json:"segments"`   // One ticket per passenger per leg
+	TotalPrice        Money           `json:"total_price"`        // Sum of all segment prices
+	TotalCommission   Money           `json:"total_commission"`   // Sum of all commissions
+	GrandTotal        Money           `json:"grand_total"`        // totalPrice + totalCommission
+	InsurancePremium  Money           `json:"insurance_premium,omitempty"`
 	IncludeInsurance  bool            `json:"include_insurance"`
 	Status            BookingStatus   `json:"status"`
+	Version           int             `json:"version"` // Incremented by each booking change
 	Payment           *Payment        `json:"payment,omitempty"`
+	Charges           []Payment       `json:"charges,omitempty"` // Taken after the booking was paid, e.g. the fare difference of a change
+	Policy            *InsurancePolicy `json:"policy,omitempty"` // Issued once the booking is confirmed with insurance
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
 	ConfirmedAt       *time.Time      `json:"confirmed_at,omitempty"`
 	CancelledAt       *time.Time      `json:"cancelled_at,omitempty"`
 	CancellationReason string         `json:"cancellation_reason,omitempty"`
+	Transitions       []StatusTransition `json:"-"` // Status changes not yet written to the audit trail
 }
 
 // AddSegment adds a booked segment to the booking
 func (b *Booking) AddSegment(segment BookedSegment) {
 	b.Segments = append(b.Segments, segment)
-	b.TotalPrice += segment.Price
-	b.TotalCommission += segment.Commission
-	b.GrandTotal = b.TotalPrice + b.TotalCommission
+	b.TotalPrice = b.TotalPrice.Add(segment.Price)
+	b.TotalCommission = b.TotalCommission.Add(segment.Commission)
+	b.GrandTotal = b.TotalPrice.Add(b.TotalCommission)
 	if b.IncludeInsurance {
-		b.GrandTotal += b.InsurancePremium
+		b.GrandTotal = b.GrandTotal.Add(b.InsurancePremium)
 	}
 }
 
 // MarkAsConfirmed marks booking as confirmed
-func (b *Booking) MarkAsConfirmed() {
-	b.Status = BookingConfirmed
-	now := time.Now()
-	b.ConfirmedAt = &now
-	b.UpdatedAt = now
+func (b *Booking) MarkAsConfirmed(actor string) error {
+	return b.TransitionTo(BookingConfirmed, actor, "")
 }
 
 // MarkAsFailed marks booking as failed
-func (b *Booking) MarkAsFailed(reason string) {
-	b.Status = BookingFailed
-	b.CancellationReason = reason
-	b.UpdatedAt = time.Now()
+func (b *Booking) MarkAsFailed(actor, reason string) error {
+	return b.TransitionTo(BookingFailed, actor, reason)
 }
 
 // MarkAsCancelled marks booking as cancelled
-func (b *Booking) MarkAsCancelled(reason string) {
-	b.Status = BookingCancelled
-	now := time.Now()
-	b.CancelledAt = &now
-	b.CancellationReason = reason
-	b.UpdatedAt = now
+func (b *Booking) MarkAsCancelled(actor, reason string) error {
+	return b.TransitionTo(BookingCancelled, actor, reason)
+}
+
+// RefundablePayments returns the completed payments of the booking, newest first,
+// which is the order refunds draw on them
+func (b *Booking) RefundablePayments() []*Payment {
+	payments := make([]*Payment, 0, len(b.Charges)+1)
+	for i := len(b.Charges) - 1; i >= 0; i-- {
+		if b.Charges[i].Status == PaymentCompleted {
+			payments = append(payments, &b.Charges[i])
+		}
+	}
+	if b.Payment != nil && b.Payment.Status == PaymentCompleted {
+		payments = append(payments, b.Payment)
+	}
+	return payments
+}
+
+// RefundableAmount returns what has been paid over all of the booking's payments and not yet refunded
+func (b *Booking) RefundableAmount() Money {
+	total := Money{Currency: SettlementCurrency}
+	for _, payment := range b.RefundablePayments() {
+		total = total.Add(payment.RefundableAmount())
+	}
+	return total
+}
+
+// FindPayment returns the booking's payment or charge the gateway knows by the given ID
+func (b *Booking) FindPayment(providerPaymentID string) (*Payment, bool) {
+	if b.Payment != nil && b.Payment.ProviderPaymentID == providerPaymentID {
+		return b.Payment, true
+	}
+	for i := range b.Charges {
+		if b.Charges[i].ProviderPaymentID == providerPaymentID {
+			return &b.Charges[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindSegment returns the booked segment (ticket) with the given ID
+func (b *Booking) FindSegment(id string) (*BookedSegment, bool) {
+	for i := range b.Segments {
+		if b.Segments[i].ID == id {
+			return &b.Segments[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindPassenger returns the passenger with the given ID, or nil
+func (b *Booking) FindPassenger(id string) *Passenger {
+	for i := range b.Passengers {
+		if b.Passengers[i].ID == id {
+			return &b.Passengers[i]
+		}
+	}
+	return nil
+}
+
+// ActiveSegments returns the tickets that have not been cancelled
+func (b *Booking) ActiveSegments() []BookedSegment {
+	segments := make([]BookedSegment, 0, len(b.Segments))
+	for _, segment := range b.Segments {
+		if segment.BookingStatus != BookingCancelled {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// LastArrival returns when the last active ticket arrives; zero when none is active
+func (b *Booking) LastArrival() time.Time {
+	var last time.Time
+	for _, segment := range b.Segments {
+		if segment.BookingStatus != BookingCancelled && segment.ArrivalTime.After(last) {
+			last = segment.ArrivalTime
+		}
+	}
+	return last
+}
+
+// SegmentsFor returns the tickets issued to a passenger
+func (b *Booking) SegmentsFor(passengerID string) []BookedSegment {
+	segments := make([]BookedSegment, 0)
+	for _, segment := range b.Segments {
+		if segment.PassengerID == passengerID {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// AwaitingTickets checks if the booking was paid for first and its tickets are not issued yet
+func (b *Booking) AwaitingTickets() bool {
+	for _, segment := range b.Segments {
+		if segment.BookingStatus == BookingPending {
+			return true
+		}
+	}
+	return false
 }
 
 // AllSegmentsBooked checks if all segments are successfully booked
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ChangeRequest asks to move a ticket to another segment of the same leg
+type ChangeRequest struct {
+	BookedSegmentID string `json:"booked_segment_id"`
+	SegmentID       string `json:"segment_id"` // Replacement segment, e.g. a later bus
+}
+
+// TicketChange is one ticket swapped for a ticket on another segment
+type TicketChange struct {
+	OldBookedSegmentID string `json:"old_booked_segment_id"`
+	NewBookedSegmentID string `json:"new_booked_segment_id"`
+	FareDifference     Money  `json:"fare_difference"` // New ticket total minus the old one
+	ChangeFee          Money  `json:"change_fee"`      // From the old ticket's fare rule
+}
+
+// BookingChange records a change of a booking; each change creates a new booking version
+type BookingChange struct {
+	ID             string         `json:"id"`
+	BookingID      string         `json:"booking_id"`
+	Version        int            `json:"version"` // Booking version the change created
+	Tickets        []TicketChange `json:"tickets"`
+	FareDifference Money          `json:"fare_difference"`
+	ChangeFee      Money          `json:"change_fee"`
+	Amount         Money          `json:"amount"`               // Charged when positive, refunded when negative
+	PaymentID      string         `json:"payment_id,omitempty"` // Gateway payment of the charge
+	Actor          string         `json:"actor"`
+	Reason         string         `json:"reason,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// IsChangeable reports whether tickets of the booking can be changed
+func (b *Booking) IsChangeable() bool {
+	return b.Status == BookingConfirmed || b.Status == BookingPartiallyCancelled
+}
+
+// ReplaceSegment cancels a ticket in favour of its replacement and updates the totals
+func (b *Booking) ReplaceSegment(oldID string, replacement BookedSegment, at time.Time) bool {
+	old, ok := b.FindSegment(oldID)
+	if !ok {
+		return false
+	}
+
+	old.BookingStatus = BookingCancelled
+	old.ReplacedBy = replacement.ID
+	old.CancelledAt = &at
+	b.TotalPrice = b.TotalPrice.Sub(old.Price)
+	b.TotalCommission = b.TotalCommission.Sub(old.Commission)
+
+	b.AddSegment(replacement)
+	return true
+}
+
+// SameLeg reports whether a segment runs between the same cities as a ticket
+func (s *BookedSegment) SameLeg(segment *Segment) bool {
+	return sameCity(s.From, segment.StartStop) && sameCity(s.To, segment.EndStop)
+}
+
+// sameCity reports whether two stops are in the same city
+func sameCity(a, b Stop) bool {
+	if a.CityID != "" && b.CityID != "" {
+		return a.CityID == b.CityID
+	}
+	return a.ID == b.ID || strings.EqualFold(a.City, b.City)
+}
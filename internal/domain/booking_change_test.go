@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplaceSegment(t *testing.T) {
+	yakutsk := Stop{ID: "yks-bus", City: "Якутск"}
+	pokrovsk := Stop{ID: "pkr-bus", City: "Покровск"}
+
+	booking := &Booking{Status: BookingConfirmed}
+	booking.AddSegment(BookedSegment{ID: "bs-1", SegmentID: "bus-0800", From: yakutsk, To: pokrovsk, Price: Rubles(1000), Commission: Rubles(80), BookingStatus: BookingConfirmed})
+
+	later := &Segment{ID: "bus-1400", StartStop: Stop{ID: "yks-avt", City: "якутск"}, EndStop: pokrovsk}
+	if !booking.Segments[0].SameLeg(later) {
+		t.Fatal("expected a bus between the same cities to be the same leg")
+	}
+	if booking.Segments[0].SameLeg(&Segment{StartStop: yakutsk, EndStop: Stop{ID: "mirny", City: "Мирный"}}) {
+		t.Fatal("expected a different destination to be another leg")
+	}
+
+	at := time.Now()
+	replaced := booking.ReplaceSegment("bs-1", BookedSegment{ID: "bs-2", SegmentID: later.ID, Price: Rubles(1200), Commission: Rubles(96), BookingStatus: BookingConfirmed}, at)
+	if !replaced {
+		t.Fatal("expected the ticket to be replaced")
+	}
+
+	old := booking.Segments[0]
+	if old.BookingStatus != BookingCancelled || old.ReplacedBy != "bs-2" || old.CancelledAt == nil {
+		t.Fatalf("old ticket not cancelled in favour of the new one: %+v", old)
+	}
+	if len(booking.ActiveSegments()) != 1 || booking.TotalPrice != Rubles(1200) || booking.GrandTotal != Rubles(1296) {
+		t.Fatalf("totals should follow the new ticket: price %v grand total %v", booking.TotalPrice, booking.GrandTotal)
+	}
+	if booking.ReplaceSegment("missing", BookedSegment{}, at) {
+		t.Fatal("expected an unknown ticket not to be replaced")
+	}
+}
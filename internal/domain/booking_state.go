@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Actors that change booking status, recorded in the audit trail
+const (
+	ActorSystem        = "system"                // Booking flow, background jobs
+	ActorCustomer      = "customer"              // Passenger through the public API
+	ActorYooKassa      = "webhook:yookassa"      // Payment provider notification
+	ActorCloudPayments = "webhook:cloudpayments" // Payment provider notification
+	ActorSberPay       = "webhook:sberpay"       // Payment provider notification
+)
+
+// bookingTransitions lists the statuses each status may move to
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:            {BookingPendingPayment, BookingConfirmed, BookingFailed},
+	BookingPendingPayment:     {BookingConfirmed, BookingFailed},
+	BookingConfirmed:          {BookingPartiallyCancelled, BookingCancelled, BookingRefunded},
+	BookingPartiallyCancelled: {BookingPartiallyCancelled, BookingCancelled, BookingRefunded}, // Each further ticket cancellation is audited
+	BookingCancelled:          {BookingRefunded},
+	BookingFailed:             {},
+	BookingRefunded:           {},
+}
+
+// CanTransition reports whether a booking may move from one status to another
+func CanTransition(from, to BookingStatus) bool {
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned for a status change the transition table does not allow
+type TransitionError struct {
+	BookingID string
+	From      BookingStatus
+	To        BookingStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("booking %s cannot move from %s to %s", e.BookingID, e.From, e.To)
+}
+
+// StatusTransition is one recorded status change of a booking
+type StatusTransition struct {
+	From   BookingStatus `json:"from"`
+	To     BookingStatus `json:"to"`
+	Actor  string        `json:"actor"`
+	Reason string        `json:"reason,omitempty"`
+	At     time.Time     `json:"at"`
+}
+
+// TransitionTo moves the booking to a new status if the transition table allows it
+// The change is queued in Transitions for the repository to write to the audit trail.
+func (b *Booking) TransitionTo(to BookingStatus, actor, reason string) error {
+	if !CanTransition(b.Status, to) {
+		return &TransitionError{BookingID: b.ID, From: b.Status, To: to}
+	}
+
+	now := time.Now()
+	b.Transitions = append(b.Transitions, StatusTransition{
+		From:   b.Status,
+		To:     to,
+		Actor:  actor,
+		Reason: reason,
+		At:     now,
+	})
+
+	b.Status = to
+	b.UpdatedAt = now
+
+	switch to {
+	case BookingConfirmed:
+		b.ConfirmedAt = &now
+	case BookingCancelled:
+		b.CancelledAt = &now
+		b.CancellationReason = reason
+	case BookingFailed:
+		b.CancellationReason = reason
+	}
+
+	return nil
+}
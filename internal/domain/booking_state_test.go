@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBookingTransitionsRecordActorAndReason(t *testing.T) {
+	booking := Booking{ID: "b1", Status: BookingPending}
+
+	if err := booking.MarkAsConfirmed(ActorSystem); err != nil {
+		t.Fatalf("pending -> confirmed: %v", err)
+	}
+	if err := booking.MarkAsCancelled(ActorCustomer, "plans changed"); err != nil {
+		t.Fatalf("confirmed -> cancelled: %v", err)
+	}
+
+	if booking.ConfirmedAt == nil || booking.CancelledAt == nil || booking.CancellationReason != "plans changed" {
+		t.Fatalf("timestamps or reason not set: %+v", booking)
+	}
+	if len(booking.Transitions) != 2 {
+		t.Fatalf("expected 2 transitions got %d", len(booking.Transitions))
+	}
+	last := booking.Transitions[1]
+	if last.From != BookingConfirmed || last.To != BookingCancelled || last.Actor != ActorCustomer {
+		t.Fatalf("unexpected transition %+v", last)
+	}
+}
+
+func TestBookingRejectsLateConfirmation(t *testing.T) {
+	booking := Booking{ID: "b1", Status: BookingRefunded}
+
+	err := booking.MarkAsConfirmed(ActorYooKassa)
+
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("expected TransitionError got %v", err)
+	}
+	if booking.Status != BookingRefunded || len(booking.Transitions) != 0 {
+		t.Fatalf("rejected transition changed the booking: %+v", booking)
+	}
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// City is a canonical city that stops, routes and search criteria resolve to
+// Providers spell cities differently ("Yakutsk", "Якутск", "YKS"); the ID is
+// stable across all of them.
+type City struct {
+	ID          string   `json:"id"`                  // Canonical ID, e.g. "yakutsk"
+	Name        string   `json:"name"`                // Russian name, e.g. "Якутск"
+	NameEn      string   `json:"name_en,omitempty"`   // English name, e.g. "Yakutsk"
+	IATACode    string   `json:"iata_code,omitempty"` // Aviasales city code, e.g. "YKS"
+	RZDCode     string   `json:"rzd_code,omitempty"`  // RZD express code of the main station
+	CountryCode string   `json:"country_code,omitempty"`
+	Latitude    float64  `json:"latitude,omitempty"`
+	Longitude   float64  `json:"longitude,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"` // Other spellings, e.g. "Moscow" for "Москва"
+}
+
+// Names returns every known spelling of the city
+func (c *City) Names() []string {
+	names := make([]string, 0, len(c.Aliases)+4)
+	for _, name := range append([]string{c.Name, c.NameEn, c.IATACode, c.RZDCode}, c.Aliases...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CityKey normalizes a city name for matching: case, ё, punctuation and
+// script are ignored, so "Усть-Кут", "усть кут" and "Ust-Kut" share a key
+func CityKey(name string) string {
+	latin := strings.ToLower(utils.ToLatin(strings.TrimSpace(name)))
+
+	// Fold common alternative romanizations
+	latin = strings.NewReplacer("j", "y", "kh", "h", "iy", "y", "yy", "y").Replace(latin)
+
+	var b strings.Builder
+	for _, r := range latin {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NewCity creates a city known only by name, filling the other script by transliteration
+func NewCity(name string) City {
+	name = strings.TrimSpace(name)
+
+	city := City{Name: name, NameEn: utils.ToLatin(name)}
+	if city.NameEn == name {
+		// Latin input: the Russian name is a best-effort guess
+		city.Name = utils.ToCyrillic(name)
+	}
+	city.ID = CityKey(city.NameEn)
+
+	return city
+}
+
+// CityDirectory resolves city spellings, IATA and RZD codes to canonical cities
+type CityDirectory struct {
+	cities map[string]*City // by ID
+	byKey  map[string]*City // by CityKey of any name or alias
+	byCode map[string]*City // by IATA or RZD code
+}
+
+// NewCityDirectory creates a directory from the given cities
+func NewCityDirectory(cities []City) *CityDirectory {
+	d := &CityDirectory{
+		cities: make(map[string]*City),
+		byKey:  make(map[string]*City),
+		byCode: make(map[string]*City),
+	}
+	for i := range cities {
+		d.Add(cities[i])
+	}
+	return d
+}
+
+// Add registers a city, merging it with an existing entry of the same ID
+func (d *CityDirectory) Add(city City) *City {
+	existing, ok := d.cities[city.ID]
+	if !ok {
+		existing = &City{ID: city.ID}
+		d.cities[city.ID] = existing
+	}
+	existing.merge(city)
+
+	for _, name := range existing.Names() {
+		if key := CityKey(name); key != "" {
+			if _, taken := d.byKey[key]; !taken {
+				d.byKey[key] = existing
+			}
+		}
+	}
+	for _, code := range []string{existing.IATACode, existing.RZDCode} {
+		if code != "" {
+			d.byCode[strings.ToUpper(code)] = existing
+		}
+	}
+
+	return existing
+}
+
+// Resolve finds the canonical city for a name, alias or code
+func (d *CityDirectory) Resolve(input string) (*City, bool) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, false
+	}
+
+	if city, ok := d.byCode[strings.ToUpper(input)]; ok {
+		return city, true
+	}
+	if city, ok := d.cities[input]; ok {
+		return city, true
+	}
+	city, ok := d.byKey[CityKey(input)]
+	return city, ok
+}
+
+// Len returns the number of cities in the directory
+func (d *CityDirectory) Len() int {
+	return len(d.cities)
+}
+
+// merge fills empty fields and collects new aliases from other
+func (c *City) merge(other City) {
+	if c.Name == "" {
+		c.Name = other.Name
+	}
+	if c.NameEn == "" {
+		c.NameEn = other.NameEn
+	}
+	if c.IATACode == "" {
+		c.IATACode = other.IATACode
+	}
+	if c.RZDCode == "" {
+		c.RZDCode = other.RZDCode
+	}
+	if c.CountryCode == "" {
+		c.CountryCode = other.CountryCode
+	}
+	if c.Latitude == 0 && c.Longitude == 0 {
+		c.Latitude = other.Latitude
+		c.Longitude = other.Longitude
+	}
+
+	known := make(map[string]bool)
+	for _, name := range c.Names() {
+		known[CityKey(name)] = true
+	}
+	for _, name := range append([]string{other.Name, other.NameEn}, other.Aliases...) {
+		key := CityKey(name)
+		if name == "" || known[key] {
+			continue
+		}
+		known[key] = true
+		c.Aliases = append(c.Aliases, name)
+	}
+}
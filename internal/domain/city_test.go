@@ -0,0 +1,66 @@
+package domain
+
+import "testing"
+
+func TestCityDirectoryResolvesSpellingsAndCodes(t *testing.T) {
+	directory := NewCityDirectory([]City{
+		{ID: "yakutsk", Name: "Якутск", NameEn: "Yakutsk", IATACode: "YKS"},
+		{ID: "moscow", Name: "Москва", NameEn: "Moscow", IATACode: "MOW", RZDCode: "2000000"},
+		{ID: "ust-kut", Name: "Усть-Кут"},
+		{ID: "mirny", Name: "Мирный", NameEn: "Mirny"},
+	})
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"Yakutsk", "yakutsk"},
+		{"Якутск", "yakutsk"},
+		{"якутск", "yakutsk"},
+		{"YKS", "yakutsk"},
+		{"yks", "yakutsk"},
+		{"Moskva", "moscow"},
+		{"2000000", "moscow"},
+		{"ust kut", "ust-kut"},
+		{"Mirnyy", "mirny"},
+	}
+
+	for _, tc := range cases {
+		city, ok := directory.Resolve(tc.input)
+		if !ok {
+			t.Fatalf("%q: expected %s, got no match", tc.input, tc.expected)
+		}
+		if city.ID != tc.expected {
+			t.Fatalf("%q: expected %s got %s", tc.input, tc.expected, city.ID)
+		}
+	}
+
+	if _, ok := directory.Resolve("Tiksi"); ok {
+		t.Fatal("expected unknown city not to resolve")
+	}
+}
+
+func TestCityDirectoryMergesAliases(t *testing.T) {
+	directory := NewCityDirectory([]City{{ID: "yakutsk", Name: "Якутск"}})
+	directory.Add(City{ID: "yakutsk", NameEn: "Yakutsk", IATACode: "YKS", Aliases: []string{"Dyokuuskay"}})
+
+	city, ok := directory.Resolve("Dyokuuskay")
+	if !ok || city.Name != "Якутск" || city.IATACode != "YKS" {
+		t.Fatalf("expected merged city, got %+v", city)
+	}
+	if directory.Len() != 1 {
+		t.Fatalf("expected 1 city got %d", directory.Len())
+	}
+}
+
+func TestNewCityTransliteratesName(t *testing.T) {
+	city := NewCity("Нерюнгри")
+	if city.NameEn != "Neryungri" || city.ID != "neryungri" {
+		t.Fatalf("unexpected city %+v", city)
+	}
+
+	city = NewCity("Batagay")
+	if city.Name != "Батагай" || city.ID != "batagay" {
+		t.Fatalf("unexpected city %+v", city)
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import "time"
+
+// MinConnectionRule defines the minimum connection time (MCT) for a transfer.
+// Empty fields match anything, so a rule can target a stop, a city,
+// a transport pair or any combination of them.
+type MinConnectionRule struct {
+	StopID     string        `json:"stop_id,omitempty"`  // Stop where the traveller changes
+	City       string        `json:"city,omitempty"`     // City where the traveller changes
+	Inbound    TransportType `json:"inbound,omitempty"`  // Arriving transport
+	Outbound   TransportType `json:"outbound,omitempty"` // Departing transport
+	MinMinutes int           `json:"min_minutes"`
+}
+
+// MinTime returns the rule's minimum connection time
+func (r MinConnectionRule) MinTime() time.Duration {
+	return time.Duration(r.MinMinutes) * time.Minute
+}
+
+// matches reports whether the rule applies to a transfer
+func (r MinConnectionRule) matches(stopID, city string, inbound, outbound TransportType) bool {
+	return (r.StopID == "" || r.StopID == stopID) &&
+		(r.City == "" || r.City == city) &&
+		(r.Inbound == "" || r.Inbound == inbound) &&
+		(r.Outbound == "" || r.Outbound == outbound)
+}
+
+// specificity ranks matching rules: stop beats city beats transport pair
+func (r MinConnectionRule) specificity() int {
+	score := 0
+	if r.StopID != "" {
+		score += 8
+	}
+	if r.City != "" {
+		score += 4
+	}
+	if r.Inbound != "" {
+		score += 2
+	}
+	if r.Outbound != "" {
+		score++
+	}
+	return score
+}
+
+// MinConnectionTable is a set of MCT rules with a fallback
+type MinConnectionTable struct {
+	Default time.Duration       `json:"-"`
+	Rules   []MinConnectionRule `json:"rules"`
+}
+
+// DefaultMinConnectionTable returns typical connection times for Yakutia routes
+func DefaultMinConnectionTable() *MinConnectionTable {
+	return &MinConnectionTable{
+		Default: 60 * time.Minute,
+		Rules: []MinConnectionRule{
+			{Inbound: TransportAir, Outbound: TransportAir, MinMinutes: 90},    // Same airport, re-check baggage
+			{Inbound: TransportAir, Outbound: TransportRiver, MinMinutes: 120}, // Airport to river port
+			{Inbound: TransportRiver, Outbound: TransportAir, MinMinutes: 120}, // River delays, check-in closes early
+			{Inbound: TransportRail, Outbound: TransportRail, MinMinutes: 30},  // Same station
+			{Inbound: TransportBus, Outbound: TransportBus, MinMinutes: 20},    // Same terminal
+			{Outbound: TransportFerry, MinMinutes: 30},                         // Queue for the crossing
+			{Inbound: TransportIceRoad, MinMinutes: 90},                        // Winter road timing is unreliable
+		},
+	}
+}
+
+// MinConnectionTime returns the minimum time needed to change
+// from inbound to outbound transport at the given stop
+func (t *MinConnectionTable) MinConnectionTime(stopID, city string, inbound, outbound TransportType) time.Duration {
+	best := -1
+	minTime := t.Default
+
+	for _, rule := range t.Rules {
+		if !rule.matches(stopID, city, inbound, outbound) {
+			continue
+		}
+		if score := rule.specificity(); score > best {
+			best = score
+			minTime = rule.MinTime()
+		}
+	}
+
+	return minTime
+}
+
+// Check fills in the connection's Gap and IsValid:
+// after moving between stops the traveller must still have the minimum connection time
+func (t *MinConnectionTable) Check(connection *Connection) {
+	if connection.From == nil || connection.To == nil {
+		return
+	}
+
+	connection.Gap = connection.To.DepartureTime.Sub(connection.From.ArrivalTime)
+	connection.MinConnectionTime = t.MinConnectionTime(
+		connection.To.StartStop.ID,
+		connection.To.StartStop.City,
+		connection.From.TransportType,
+		connection.To.TransportType,
+	)
+
+	available := connection.Gap
+	if connection.TransferType != "" {
+		available -= connection.TransferDuration
+	}
+	connection.IsValid = available >= connection.MinConnectionTime
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMinConnectionTimeUsesMostSpecificRule(t *testing.T) {
+	table := &MinConnectionTable{
+		Default: time.Hour,
+		Rules: []MinConnectionRule{
+			{Inbound: TransportAir, Outbound: TransportAir, MinMinutes: 90},
+			{City: "Yakutsk", Inbound: TransportAir, Outbound: TransportAir, MinMinutes: 120},
+			{StopID: "yks", Inbound: TransportAir, MinMinutes: 45},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		stopID   string
+		city     string
+		expected time.Duration
+	}{
+		{"transport pair", "svo", "Moscow", 90 * time.Minute},
+		{"city beats pair", "other", "Yakutsk", 120 * time.Minute},
+		{"stop beats city", "yks", "Yakutsk", 45 * time.Minute},
+	}
+
+	for _, tc := range cases {
+		if got := table.MinConnectionTime(tc.stopID, tc.city, TransportAir, TransportAir); got != tc.expected {
+			t.Fatalf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+
+	if got := table.MinConnectionTime("svo", "Moscow", TransportBus, TransportRail); got != time.Hour {
+		t.Fatalf("expected default %s got %s", time.Hour, got)
+	}
+}
+
+func TestMinConnectionTableCheckAccountsForTransfer(t *testing.T) {
+	arrival := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	from := &Segment{TransportType: TransportAir, ArrivalTime: arrival}
+	to := &Segment{TransportType: TransportRiver, DepartureTime: arrival.Add(150 * time.Minute)}
+
+	connection := Connection{
+		From:             from,
+		To:               to,
+		TransferType:     TransportTaxi,
+		TransferDuration: 40 * time.Minute,
+	}
+	DefaultMinConnectionTable().Check(&connection)
+
+	if connection.Gap != 150*time.Minute {
+		t.Fatalf("expected gap 150m got %s", connection.Gap)
+	}
+	if connection.IsValid {
+		t.Fatalf("expected connection to be invalid: 150m gap - 40m taxi < 120m air->river MCT")
+	}
+}
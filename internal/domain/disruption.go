@@ -0,0 +1,169 @@
+package domain
+
+import "time"
+
+// DisruptionType defines what broke a booked journey
+type DisruptionType string
+
+const (
+	DisruptionCancelled DisruptionType = "cancelled" // The carrier cancelled a booked segment
+	DisruptionDelayed   DisruptionType = "delayed"   // A booked segment was delayed past the next connection
+)
+
+// DisruptionStatus defines how a disruption was handled
+type DisruptionStatus string
+
+const (
+	DisruptionProposed   DisruptionStatus = "proposed"   // Alternatives were offered to the passenger
+	DisruptionRebooked   DisruptionStatus = "rebooked"   // The passenger was moved to an alternative automatically
+	DisruptionUnresolved DisruptionStatus = "unresolved" // No alternative reaches the destination
+)
+
+// Disruption is a passenger's journey broken by a cancelled or delayed segment
+type Disruption struct {
+	ID              string           `json:"id"`
+	BookingID       string           `json:"booking_id"`
+	PassengerID     string           `json:"passenger_id"`
+	SegmentID       string           `json:"segment_id"`        // The cancelled or delayed segment
+	BookedSegmentID string           `json:"booked_segment_id"` // The passenger's ticket on it
+	Type            DisruptionType   `json:"type"`
+	Delay           time.Duration    `json:"delay,omitempty"`
+	Connection      *Connection      `json:"connection,omitempty"` // The connection that can no longer be made (IsValid = false)
+	Replaces        []string         `json:"replaces"`             // Tickets the passenger can no longer use, in travel order
+	FromCity        string           `json:"from_city"`            // Where the journey has to be re-routed from
+	FromCityID      string           `json:"from_city_id,omitempty"`
+	ToCity          string           `json:"to_city"` // The passenger's final destination
+	ToCityID        string           `json:"to_city_id,omitempty"`
+	DepartAfter     time.Time        `json:"depart_after"` // Earliest departure of an alternative
+	Alternatives    []Route          `json:"alternatives"`
+	Status          DisruptionStatus `json:"status"`
+	ChangeID        string           `json:"change_id,omitempty"` // Booking change made when rebooked
+	DetectedAt      time.Time        `json:"detected_at"`
+	NotifiedAt      *time.Time       `json:"notified_at,omitempty"`
+}
+
+// Matches checks if two disruptions describe the same problem of the same passenger
+func (d *Disruption) Matches(other *Disruption) bool {
+	return d.BookingID == other.BookingID &&
+		d.PassengerID == other.PassengerID &&
+		d.SegmentID == other.SegmentID &&
+		d.Type == other.Type
+}
+
+// DetectDisruptions compares a booking's tickets with the current state of their segments
+// current maps segment IDs to the latest synced segments; a segment missing from it no
+// longer runs. Each passenger gets at most one disruption, at the first point where their
+// journey breaks: a cancelled segment that has not departed yet, or a delay that makes
+// the next connection shorter than the minimum connection time.
+func DetectDisruptions(booking *Booking, current map[string]*Segment, rules *MinConnectionTable, now time.Time) []Disruption {
+	disruptions := make([]Disruption, 0)
+
+	for _, passenger := range booking.Passengers {
+		tickets := make([]BookedSegment, 0)
+		for _, ticket := range booking.SegmentsFor(passenger.ID) {
+			if ticket.BookingStatus != BookingCancelled {
+				tickets = append(tickets, ticket)
+			}
+		}
+		if len(tickets) == 0 {
+			continue
+		}
+		destination := tickets[len(tickets)-1].To
+
+		for i := range tickets {
+			ticket := &tickets[i]
+			segment := current[ticket.SegmentID]
+
+			if segment == nil || segment.IsCancelled() {
+				if !ticket.DepartureTime.After(now) {
+					continue
+				}
+
+				disruption := newDisruption(booking, passenger.ID, ticket, DisruptionCancelled, tickets[i:], destination)
+				disruption.DepartAfter = now
+				if i > 0 {
+					// Arriving on the previous leg leads nowhere now
+					previous := segmentOrTicket(current, &tickets[i-1])
+					connection := Connection{From: previous, To: ticket.asSegment()}
+					rules.Check(&connection)
+					connection.IsValid = false
+					disruption.Connection = &connection
+					if previous.ArrivalTime.After(now) {
+						disruption.DepartAfter = previous.ArrivalTime
+					}
+				}
+				disruptions = append(disruptions, disruption)
+				break
+			}
+
+			if i == len(tickets)-1 {
+				break
+			}
+			next := &tickets[i+1]
+			delay := segment.ArrivalTime.Sub(ticket.ArrivalTime)
+			if delay <= 0 || !next.DepartureTime.After(now) {
+				continue
+			}
+
+			connection := Connection{From: segment, To: segmentOrTicket(current, next)}
+			rules.Check(&connection)
+			if connection.IsValid {
+				continue
+			}
+
+			disruption := newDisruption(booking, passenger.ID, ticket, DisruptionDelayed, tickets[i+1:], destination)
+			disruption.Delay = delay
+			disruption.Connection = &connection
+			disruption.DepartAfter = segment.ArrivalTime.Add(connection.MinConnectionTime)
+			disruptions = append(disruptions, disruption)
+			break
+		}
+	}
+
+	return disruptions
+}
+
+// newDisruption describes a broken journey to be re-routed from the first replaced ticket
+func newDisruption(booking *Booking, passengerID string, ticket *BookedSegment, disruptionType DisruptionType, replaced []BookedSegment, destination Stop) Disruption {
+	replaces := make([]string, len(replaced))
+	for i := range replaced {
+		replaces[i] = replaced[i].ID
+	}
+
+	return Disruption{
+		BookingID:       booking.ID,
+		PassengerID:     passengerID,
+		SegmentID:       ticket.SegmentID,
+		BookedSegmentID: ticket.ID,
+		Type:            disruptionType,
+		Replaces:        replaces,
+		FromCity:        replaced[0].From.City,
+		FromCityID:      replaced[0].From.CityID,
+		ToCity:          destination.City,
+		ToCityID:        destination.CityID,
+		Status:          DisruptionUnresolved,
+	}
+}
+
+// segmentOrTicket returns the current segment of a ticket, or the segment as it was booked
+func segmentOrTicket(current map[string]*Segment, ticket *BookedSegment) *Segment {
+	if segment := current[ticket.SegmentID]; segment != nil {
+		return segment
+	}
+	return ticket.asSegment()
+}
+
+// asSegment describes the segment of a ticket as it was booked
+func (s *BookedSegment) asSegment() *Segment {
+	return &Segment{
+		ID:            s.SegmentID,
+		TransportType: s.TransportType,
+		Provider:      s.Provider,
+		StartStop:     s.From,
+		EndStop:       s.To,
+		DepartureTime: s.DepartureTime,
+		ArrivalTime:   s.ArrivalTime,
+		Source:        s.Source,
+		Tariff:        s.Tariff,
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectDisruptions(t *testing.T) {
+	now := time.Date(2026, 7, 1, 6, 0, 0, 0, time.UTC)
+	yakutsk := Stop{ID: "yks", City: "Якутск"}
+	mirny := Stop{ID: "mjz", City: "Мирный"}
+	lensk := Stop{ID: "ulk", City: "Ленск"}
+
+	flight := Segment{ID: "air", TransportType: TransportAir, StartStop: yakutsk, EndStop: mirny, DepartureTime: now.Add(2 * time.Hour), ArrivalTime: now.Add(4 * time.Hour)}
+	bus := Segment{ID: "bus", TransportType: TransportBus, StartStop: mirny, EndStop: lensk, DepartureTime: now.Add(6 * time.Hour), ArrivalTime: now.Add(10 * time.Hour)}
+
+	booking := &Booking{ID: "b1", Passengers: []Passenger{{ID: "p1"}}}
+	booking.Segments = []BookedSegment{ticketOn(flight, "p1"), ticketOn(bus, "p1")}
+	rules := &MinConnectionTable{Default: time.Hour}
+
+	current := map[string]*Segment{"air": &flight, "bus": &bus}
+	if got := DetectDisruptions(booking, current, rules, now); len(got) != 0 {
+		t.Fatalf("expected no disruption on schedule, got %+v", got)
+	}
+
+	// 30 minutes late still leaves 90 minutes in Mirny
+	late := flight
+	late.ArrivalTime = flight.ArrivalTime.Add(30 * time.Minute)
+	current["air"] = &late
+	if got := DetectDisruptions(booking, current, rules, now); len(got) != 0 {
+		t.Fatalf("expected the connection to hold, got %+v", got)
+	}
+
+	late.ArrivalTime = flight.ArrivalTime.Add(90 * time.Minute)
+	got := DetectDisruptions(booking, current, rules, now)
+	if len(got) != 1 || got[0].Type != DisruptionDelayed {
+		t.Fatalf("expected a delay past the connection, got %+v", got)
+	}
+	delayed := got[0]
+	if delayed.SegmentID != "air" || delayed.Delay != 90*time.Minute || delayed.Connection.IsValid {
+		t.Fatalf("unexpected delay %+v", delayed)
+	}
+	if delayed.FromCity != "Мирный" || delayed.ToCity != "Ленск" || len(delayed.Replaces) != 1 || delayed.Replaces[0] != booking.Segments[1].ID {
+		t.Fatalf("expected to re-route the bus leg from Mirny, got %+v", delayed)
+	}
+	if want := late.ArrivalTime.Add(time.Hour); !delayed.DepartAfter.Equal(want) {
+		t.Fatalf("expected alternatives after %s, got %s", want, delayed.DepartAfter)
+	}
+
+	// A cancelled flight breaks the whole journey
+	cancelled := flight
+	cancelled.Status = SegmentCancelled
+	current["air"] = &cancelled
+	got = DetectDisruptions(booking, current, rules, now)
+	if len(got) != 1 || got[0].Type != DisruptionCancelled || got[0].FromCity != "Якутск" || len(got[0].Replaces) != 2 {
+		t.Fatalf("expected to re-route from Yakutsk, got %+v", got)
+	}
+
+	// A segment that no longer exists is cancelled too
+	delete(current, "air")
+	current["bus"] = &bus
+	if got := DetectDisruptions(booking, current, rules, now); len(got) != 1 || got[0].Type != DisruptionCancelled {
+		t.Fatalf("expected a missing segment to count as cancelled, got %+v", got)
+	}
+}
+
+// ticketOn books the segment for a passenger
+func ticketOn(s Segment, passengerID string) BookedSegment {
+	return BookedSegment{
+		ID:            "bs-" + s.ID,
+		SegmentID:     s.ID,
+		PassengerID:   passengerID,
+		TransportType: s.TransportType,
+		From:          s.StartStop,
+		To:            s.EndStop,
+		DepartureTime: s.DepartureTime,
+		ArrivalTime:   s.ArrivalTime,
+		BookingStatus: BookingConfirmed,
+	}
+}
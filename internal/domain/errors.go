@@ -26,6 +26,7 @@ var (
 	ErrSearchFailed       = DomainError{Code: "SEARCH_FAILED", Message: "Route search failed"}
 	ErrDatabaseError      = DomainError{Code: "DATABASE_ERROR", Message: "Database error"}
 	ErrValidationFailed   = DomainError{Code: "VALIDATION_FAILED", Message: "Validation failed"}
+	ErrChargeNotSupported = DomainError{Code: "CHARGE_NOT_SUPPORTED", Message: "The payment cannot be charged without the customer"}
 )
 
 // NewDomainError creates a new domain error
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Fare rule sources: where a rule came from
+const (
+	FareRuleSourceDefault = "default" // Built-in rule for the transport type
+	FareRuleSourceConfig  = "config"  // Maintained by hand in the fare_rules table
+	FareRuleSourceGARS    = "gars"    // Synced from GARS fees and service prices
+)
+
+// PenaltyTier is the cancellation penalty that applies from some time before departure
+type PenaltyTier struct {
+	Before  time.Duration `json:"before"`  // Applies when cancelling at least this long before departure (negative = after)
+	Penalty float64       `json:"penalty"` // Share of the fare kept by the carrier (0.1 = 10%)
+	Fee     Money         `json:"fee"`     // Fixed fee on top of the share
+}
+
+// FareRule describes refund and change conditions of a provider tariff
+type FareRule struct {
+	ID            string        `json:"id"`
+	Provider      string        `json:"provider"`       // gars, aviasales, rzd (Segment.Source); empty for defaults
+	Tariff        string        `json:"tariff"`         // Empty = every tariff of the provider
+	TransportType TransportType `json:"transport_type"` // Defaults only
+	Refundable    bool          `json:"refundable"`     // Non-refundable tickets return nothing
+	Tiers         []PenaltyTier `json:"tiers"`          // Earliest first; no matching tier = no refund
+	Changeable    bool          `json:"changeable"`
+	ChangeFee     Money         `json:"change_fee"` // Fixed fee for rebooking
+	Source        string        `json:"source"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// TierAt returns the penalty tier for a cancellation made the given time before departure
+func (r *FareRule) TierAt(before time.Duration) (PenaltyTier, bool) {
+	if !r.Refundable {
+		return PenaltyTier{}, false
+	}
+
+	tiers := append([]PenaltyTier(nil), r.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Before > tiers[j].Before })
+
+	for _, tier := range tiers {
+		if before >= tier.Before {
+			return tier, true
+		}
+	}
+	return PenaltyTier{}, false
+}
+
+// Penalty returns what the carrier keeps of a fare cancelled the given time before departure
+func (r *FareRule) Penalty(fare Money, before time.Duration) Money {
+	tier, ok := r.TierAt(before)
+	if !ok {
+		return fare
+	}
+	return MinMoney(fare.Mul(tier.Penalty).Add(tier.Fee), fare)
+}
+
+// FareRules holds provider and tariff specific rules with per transport type defaults
+type FareRules struct {
+	Rules    []FareRule
+	Defaults map[TransportType]FareRule
+}
+
+// DefaultFareRules returns typical Russian carrier rules for voluntary cancellation:
+// buses follow the federal passenger transport rules, trains the RZD fee schedule,
+// economy flights lose a quarter of the fare a day before departure
+func DefaultFareRules() FareRules {
+	bus := FareRule{
+		Refundable: true,
+		Tiers: []PenaltyTier{
+			{Before: 2 * time.Hour, Penalty: 0.05},
+			{Before: 0, Penalty: 0.15},
+			{Before: -3 * time.Hour, Penalty: 0.25},
+		},
+		Changeable: true,
+	}
+	river := FareRule{
+		Refundable: true,
+		Tiers: []PenaltyTier{
+			{Before: 24 * time.Hour, Penalty: 0.10},
+			{Before: 0, Penalty: 0.25},
+		},
+		Changeable: true,
+	}
+	transfer := FareRule{Refundable: true, Tiers: []PenaltyTier{{Before: 0}}, Changeable: true}
+
+	defaults := map[TransportType]FareRule{
+		TransportAir: {
+			Refundable: true,
+			Tiers: []PenaltyTier{
+				{Before: 24 * time.Hour, Penalty: 0.25},
+				{Before: 0, Penalty: 0.50},
+			},
+			Changeable: true,
+			ChangeFee:  Rubles(3000),
+		},
+		TransportRail: {
+			Refundable: true,
+			Tiers: []PenaltyTier{
+				{Before: 8 * time.Hour, Fee: Rubles(230)},
+				{Before: 2 * time.Hour, Penalty: 0.25, Fee: Rubles(230)},
+				{Before: 0, Penalty: 0.50, Fee: Rubles(230)},
+			},
+			Changeable: true,
+			ChangeFee:  Rubles(230),
+		},
+		TransportBus:     bus,
+		TransportIceRoad: bus,
+		TransportRiver:   river,
+		TransportFerry:   river,
+		TransportTaxi:    transfer,
+		TransportWalk:    transfer,
+	}
+	for transportType, rule := range defaults {
+		rule.TransportType = transportType
+		rule.Source = FareRuleSourceDefault
+		defaults[transportType] = rule
+	}
+
+	return FareRules{Defaults: defaults}
+}
+
+// For returns the rule of a provider tariff, falling back to the provider-wide rule
+// and then to the default for the transport type; unknown types are non-refundable
+func (r FareRules) For(provider, tariff string, transportType TransportType) FareRule {
+	if provider != "" {
+		var providerWide *FareRule
+		for i := range r.Rules {
+			rule := &r.Rules[i]
+			if rule.Provider != provider {
+				continue
+			}
+			if rule.Tariff == tariff {
+				return *rule
+			}
+			if rule.Tariff == "" {
+				providerWide = rule
+			}
+		}
+		if providerWide != nil {
+			return *providerWide
+		}
+	}
+
+	if rule, ok := r.Defaults[transportType]; ok {
+		return rule
+	}
+	return FareRule{TransportType: transportType, Source: FareRuleSourceDefault}
+}
+
+// ForSegment returns the rule that applies to a route segment
+func (r FareRules) ForSegment(segment *Segment) FareRule {
+	return r.For(segment.Source, segment.Tariff, segment.TransportType)
+}
+
+// SegmentRefund is the breakdown of the money returned for one cancelled ticket
+type SegmentRefund struct {
+	BookedSegmentID    string `json:"booked_segment_id"`
+	Fare               Money  `json:"fare"`                // Provider's price paid for the ticket
+	Penalty            Money  `json:"penalty"`             // Kept by the carrier
+	CommissionRefunded Money  `json:"commission_refunded"` // Our markup returned
+	CommissionRetained Money  `json:"commission_retained"` // Our markup kept
+	Amount             Money  `json:"amount"`              // Returned to the customer
+}
+
+// RefundFor returns the refund for a ticket cancelled at the given time:
+// the fare minus the carrier penalty of the matching tier
+// The commission is retained; callers refund it through RefundCommission.
+func (r FareRules) RefundFor(segment *BookedSegment, at time.Time) SegmentRefund {
+	rule := r.For(segment.Source, segment.Tariff, segment.TransportType)
+	penalty := rule.Penalty(segment.Price, segment.DepartureTime.Sub(at))
+
+	return SegmentRefund{
+		BookedSegmentID:    segment.ID,
+		Fare:               segment.Price,
+		Penalty:            penalty,
+		CommissionRetained: segment.Commission,
+		CommissionRefunded: Money{Currency: segment.Commission.Currency},
+		Amount:             segment.Price.Sub(penalty),
+	}
+}
+
+// RefundCommission adds part of the commission to the refund
+func (s *SegmentRefund) RefundCommission(amount Money) {
+	amount = MinMoney(amount, s.CommissionRetained)
+	s.CommissionRefunded = s.CommissionRefunded.Add(amount)
+	s.CommissionRetained = s.CommissionRetained.Sub(amount)
+	s.Amount = s.Amount.Add(amount)
+}
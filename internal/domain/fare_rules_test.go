@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefundForTiers(t *testing.T) {
+	rules := DefaultFareRules()
+	departure := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	segment := &BookedSegment{ID: "bs-1", TransportType: TransportAir, Price: Rubles(10000), Commission: Rubles(700), DepartureTime: departure}
+
+	refund := rules.RefundFor(segment, departure.Add(-48*time.Hour))
+	if refund.Penalty != Rubles(2500) || refund.Amount != Rubles(7500) || refund.CommissionRetained != Rubles(700) {
+		t.Fatalf("unexpected early air refund: %+v", refund)
+	}
+
+	if refund := rules.RefundFor(segment, departure.Add(-time.Hour)); refund.Amount != Rubles(5000) {
+		t.Fatalf("expected half the fare back on the day of departure got %v", refund.Amount)
+	}
+
+	if refund := rules.RefundFor(segment, departure.Add(time.Minute)); !refund.Amount.IsZero() || refund.Penalty != Rubles(10000) {
+		t.Fatalf("expected nothing back after departure: %+v", refund)
+	}
+
+	// Bus passengers who miss the departure still get 75% back within 3 hours
+	bus := &BookedSegment{ID: "bs-2", TransportType: TransportBus, Price: Rubles(1234.55), DepartureTime: departure}
+	if refund := rules.RefundFor(bus, departure.Add(time.Hour)); refund.Amount != Rubles(925.91) {
+		t.Fatalf("expected missed bus refund 925.91 got %v", refund.Amount)
+	}
+}
+
+func TestFareRulesFor(t *testing.T) {
+	rules := DefaultFareRules()
+	rules.Rules = []FareRule{
+		{Provider: "rzd", Refundable: true, Tiers: []PenaltyTier{{Before: 0, Fee: Rubles(230)}}},
+		{Provider: "rzd", Tariff: "Купе", Refundable: false},
+	}
+
+	if rule := rules.For("rzd", "Купе", TransportRail); rule.Refundable {
+		t.Fatalf("expected tariff rule, got %+v", rule)
+	}
+	if rule := rules.For("rzd", "Плацкарт", TransportRail); !rule.Refundable || rule.Tiers[0].Fee != Rubles(230) {
+		t.Fatalf("expected provider-wide rule, got %+v", rule)
+	}
+	if rule := rules.For("", "", TransportRiver); rule.Source != FareRuleSourceDefault {
+		t.Fatalf("expected default river rule, got %+v", rule)
+	}
+
+	refund := SegmentRefund{Amount: Rubles(900), CommissionRetained: Rubles(70)}
+	refund.RefundCommission(Rubles(100))
+	if refund.Amount != Rubles(970) || refund.CommissionRefunded != Rubles(70) || !refund.CommissionRetained.IsZero() {
+		t.Fatalf("commission refund capped at the commission: %+v", refund)
+	}
+}
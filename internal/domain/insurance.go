@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// PolicyStatus represents the status of an insurance policy
+type PolicyStatus string
+
+const (
+	PolicyActive    PolicyStatus = "active"
+	PolicyCancelled PolicyStatus = "cancelled" // The premium was refunded with the booking
+)
+
+// InsurancePolicy is the travel insurance issued for a booking
+type InsurancePolicy struct {
+	Number        string       `json:"number"`   // Policy number issued by the insurer
+	Provider      string       `json:"provider"` // Insurer
+	Premium       Money        `json:"premium"`
+	CoverageLimit Money        `json:"coverage_limit"` // Most that is paid out over all claims
+	PaidOut       Money        `json:"paid_out"`       // Sum of the claims paid out
+	Status        PolicyStatus `json:"status"`
+	ValidFrom     time.Time    `json:"valid_from"`
+	ValidUntil    time.Time    `json:"valid_until"`
+	IssuedAt      time.Time    `json:"issued_at"`
+}
+
+// Remaining returns what can still be paid out under the policy
+func (p *InsurancePolicy) Remaining() Money {
+	remaining := p.CoverageLimit.Sub(p.PaidOut)
+	if remaining.IsNegative() {
+		return Money{Currency: remaining.Currency}
+	}
+	return remaining
+}
+
+// Covers checks if the policy is in force at the given time
+func (p *InsurancePolicy) Covers(t time.Time) bool {
+	return p.Status == PolicyActive && !t.Before(p.ValidFrom) && !t.After(p.ValidUntil)
+}
+
+// ClaimType defines what an insurance claim is made for
+type ClaimType string
+
+const (
+	ClaimMissedConnection ClaimType = "missed_connection" // A late arrival made the passenger miss the next ticket
+)
+
+// ClaimStatus defines the outcome of an insurance claim
+type ClaimStatus string
+
+const (
+	ClaimPending  ClaimStatus = "pending"  // Approved, being paid out (or the payout failed and is settled manually)
+	ClaimPaid     ClaimStatus = "paid"     // Approved and paid out through a refund
+	ClaimRejected ClaimStatus = "rejected" // The connection could still be made, or the insurer declined
+)
+
+// InsuranceClaim is a claim made under a booking's insurance policy
+type InsuranceClaim struct {
+	ID               string        `json:"id"`
+	BookingID        string        `json:"booking_id"`
+	PolicyNumber     string        `json:"policy_number"`
+	Type             ClaimType     `json:"type"`
+	PassengerID      string        `json:"passenger_id"`
+	BookedSegmentID  string        `json:"booked_segment_id"` // The ticket that was missed
+	InboundTicketID  string        `json:"inbound_ticket_id"` // The ticket the passenger arrived on
+	ScheduledArrival time.Time     `json:"scheduled_arrival"` // Arrival of the inbound ticket as booked
+	ActualArrival    time.Time     `json:"actual_arrival"`    // Arrival of the inbound segment as last synced
+	Delay            time.Duration `json:"delay"`
+	Connection       *Connection   `json:"connection,omitempty"` // The connection as it turned out
+	Status           ClaimStatus   `json:"status"`
+	Payout           Money         `json:"payout"`
+	RejectionReason  string        `json:"rejection_reason,omitempty"`
+	ProviderClaimID  string        `json:"provider_claim_id,omitempty"` // Insurer's reference of the claim
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// InboundTicket returns the ticket a passenger arrives on before taking the given one
+// Cancelled tickets are skipped; the first leg of a journey has no inbound ticket.
+func (b *Booking) InboundTicket(ticket *BookedSegment) (*BookedSegment, bool) {
+	tickets := make([]BookedSegment, 0)
+	for _, other := range b.SegmentsFor(ticket.PassengerID) {
+		if other.BookingStatus != BookingCancelled && other.ID != ticket.ID && other.DepartureTime.Before(ticket.DepartureTime) {
+			tickets = append(tickets, other)
+		}
+	}
+	if len(tickets) == 0 {
+		return nil, false
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].DepartureTime.Before(tickets[j].DepartureTime)
+	})
+	return &tickets[len(tickets)-1], true
+}
+
+// CheckMissedConnection checks if a late arrival made a passenger miss the next ticket
+// arrived is the inbound ticket's segment as last synced, its arrival time being the
+// actual one. The connection is missed when the inbound segment arrived later than
+// booked and the time left is shorter than the minimum connection time.
+func CheckMissedConnection(inbound *BookedSegment, arrived *Segment, missed *BookedSegment, rules *MinConnectionTable) (Connection, time.Duration, bool) {
+	connection := Connection{From: arrived, To: missed.asSegment()}
+	rules.Check(&connection)
+
+	delay := arrived.ArrivalTime.Sub(inbound.ArrivalTime)
+	return connection, delay, delay > 0 && !connection.IsValid
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckMissedConnection(t *testing.T) {
+	start := time.Date(2026, 7, 1, 6, 0, 0, 0, time.UTC)
+	yakutsk := Stop{ID: "yks", City: "Якутск"}
+	mirny := Stop{ID: "mjz", City: "Мирный"}
+	lensk := Stop{ID: "ulk", City: "Ленск"}
+
+	flight := Segment{ID: "air", TransportType: TransportAir, StartStop: yakutsk, EndStop: mirny, DepartureTime: start, ArrivalTime: start.Add(2 * time.Hour)}
+	bus := Segment{ID: "bus", TransportType: TransportBus, StartStop: mirny, EndStop: lensk, DepartureTime: start.Add(4 * time.Hour), ArrivalTime: start.Add(8 * time.Hour)}
+
+	booking := &Booking{ID: "b1", Passengers: []Passenger{{ID: "p1"}, {ID: "p2"}}}
+	// Tickets are listed leg by leg, not in travel order per passenger
+	booking.Segments = []BookedSegment{ticketOn(bus, "p1"), ticketOn(flight, "p1"), ticketOn(flight, "p2")}
+	rules := &MinConnectionTable{Default: time.Hour}
+
+	missed := &booking.Segments[0]
+	inbound, ok := booking.InboundTicket(missed)
+	if !ok || inbound.SegmentID != "air" || inbound.PassengerID != "p1" {
+		t.Fatalf("expected the passenger's flight to be the inbound ticket, got %+v", inbound)
+	}
+	if _, ok := booking.InboundTicket(&booking.Segments[1]); ok {
+		t.Fatal("expected the first leg to have no inbound ticket")
+	}
+
+	// 30 minutes late still leaves 90 minutes in Mirny
+	arrived := flight
+	arrived.ArrivalTime = flight.ArrivalTime.Add(30 * time.Minute)
+	if _, delay, missedConnection := CheckMissedConnection(inbound, &arrived, missed, rules); missedConnection || delay != 30*time.Minute {
+		t.Fatalf("expected the connection to hold after a %s delay", delay)
+	}
+
+	arrived.ArrivalTime = flight.ArrivalTime.Add(90 * time.Minute)
+	connection, delay, missedConnection := CheckMissedConnection(inbound, &arrived, missed, rules)
+	if !missedConnection || delay != 90*time.Minute || connection.Gap != 30*time.Minute || connection.IsValid {
+		t.Fatalf("expected a missed connection with 30 minutes left, got %+v (%s)", connection, delay)
+	}
+
+	// A tight connection on time was the passenger's choice, not a missed one
+	tight := *missed
+	tight.DepartureTime = flight.ArrivalTime.Add(30 * time.Minute)
+	if _, _, missedConnection := CheckMissedConnection(inbound, &flight, &tight, rules); missedConnection {
+		t.Fatal("expected no claim without a delay")
+	}
+}
+
+func TestInsurancePolicyCoverage(t *testing.T) {
+	from := time.Date(2026, 7, 1, 6, 0, 0, 0, time.UTC)
+	policy := &InsurancePolicy{
+		CoverageLimit: Rubles(10000),
+		PaidOut:       Rubles(7500),
+		Status:        PolicyActive,
+		ValidFrom:     from,
+		ValidUntil:    from.Add(48 * time.Hour),
+	}
+
+	if remaining := policy.Remaining(); remaining != Rubles(2500) {
+		t.Fatalf("expected 2500 RUB left, got %v", remaining)
+	}
+	if !policy.Covers(from.Add(time.Hour)) || policy.Covers(from.Add(-time.Hour)) {
+		t.Fatal("expected the policy to cover its validity period only")
+	}
+
+	policy.Status = PolicyCancelled
+	if policy.Covers(from.Add(time.Hour)) {
+		t.Fatal("expected a cancelled policy to cover nothing")
+	}
+}
@@ -0,0 +1,331 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	CurrencyRUB Currency = "RUB"
+	CurrencyUSD Currency = "USD"
+	CurrencyEUR Currency = "EUR"
+	CurrencyCNY Currency = "CNY"
+)
+
+// SettlementCurrency is the currency bookings are priced, paid and refunded in
+// Provider prices quoted in other currencies are converted into it (see ExchangeRates).
+const SettlementCurrency = CurrencyRUB
+
+// ErrNoExchangeRate is returned when an amount cannot be converted into the settlement currency
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// ErrUnsupportedCurrency is returned for a currency whose minor unit is not a hundredth
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// minorUnits lists the ISO 4217 currencies whose minor unit is not a hundredth,
+// with the number of decimals they have; every other currency has two
+var minorUnits = map[Currency]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimals of the currency's minor unit
+func (c Currency) MinorUnits() int {
+	if decimals, ok := minorUnits[c]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// checkHundredths rejects currencies that Money cannot count in hundredths
+func checkHundredths(currency Currency) error {
+	if currency.MinorUnits() != 2 {
+		return fmt.Errorf("%w %s: its amounts have %d decimals, not 2", ErrUnsupportedCurrency, currency, currency.MinorUnits())
+	}
+	return nil
+}
+
+// ParseCurrency normalizes a currency code such as "rub" or " RUB"
+func ParseCurrency(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q", code)
+		}
+	}
+	return Currency(code), nil
+}
+
+// Money is an amount in minor units (kopecks, cents) of a currency
+// Every supported currency has 100 minor units (see Currency.MinorUnits); exchange
+// rates reject the others. Amounts of different currencies
+// never mix: adding them is a programming error and panics, they are converted
+// first. A zero Money without a currency takes the currency of the other operand.
+type Money struct {
+	Amount   int64    `json:"amount"` // Minor units
+	Currency Currency `json:"currency"`
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// MoneyFromFloat creates an amount from major units, rounded to whole minor units
+func MoneyFromFloat(amount float64, currency Currency) Money {
+	return Money{Amount: int64(math.Round(amount * 100)), Currency: currency}
+}
+
+// Rubles creates an amount in rubles, rounded to whole kopecks
+func Rubles(amount float64) Money {
+	return MoneyFromFloat(amount, CurrencyRUB)
+}
+
+// ParseMoney parses a decimal amount in major units such as "1500.5" exactly
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+
+	if len(fraction) > 2 {
+		// Digits beyond kopecks (DECIMAL columns with a larger scale) must be zero
+		if strings.Trim(fraction[2:], "0") != "" {
+			return Money{}, fmt.Errorf("invalid amount %q: more than two decimals", amount)
+		}
+		fraction = fraction[:2]
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || whole == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	if negative {
+		units = -units
+	}
+	return Money{Amount: units, Currency: currency}, nil
+}
+
+// Major returns the amount in major units (rubles), e.g. for API responses
+func (m Money) Major() float64 {
+	return float64(m.Amount) / 100
+}
+
+// Decimal formats the amount in major units with two decimals, e.g. "1500.50"
+func (m Money) Decimal() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// String formats the amount with its currency, e.g. "1500.50 RUB"
+func (m Money) String() string {
+	return strings.TrimSpace(m.Decimal() + " " + string(m.Currency))
+}
+
+// IsZero checks if the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive checks if the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative checks if the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currencyWith(other)}
+}
+
+// Sub returns the difference of two amounts of the same currency
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currencyWith(other)}
+}
+
+// Neg returns the amount with the opposite sign
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Mul returns the amount multiplied by a factor (a share or a count), rounded to whole minor units
+func (m Money) Mul(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// Cmp compares two amounts of the same currency: -1 if less, 0 if equal, +1 if greater
+func (m Money) Cmp(other Money) int {
+	m.currencyWith(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MinMoney returns the smaller of two amounts of the same currency
+func MinMoney(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// currencyWith returns the currency of an operation on two amounts
+func (m Money) currencyWith(other Money) Currency {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency
+	case m.Currency == "" && m.Amount == 0:
+		return other.Currency
+	case other.Currency == "" && other.Amount == 0:
+		return m.Currency
+	default:
+		panic(fmt.Sprintf("domain: cannot combine %s and %s", m, other))
+	}
+}
+
+// UnmarshalJSON reads an amount object, or a bare number in rubles as stored before
+// amounts had a currency (e.g. in disruption alternatives and booking change tickets)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var rubles float64
+	if err := json.Unmarshal(data, &rubles); err == nil {
+		*m = Rubles(rubles)
+		return nil
+	}
+
+	type money Money
+	var value money
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*m = Money(value)
+	return nil
+}
+
+// Scan reads a DECIMAL column in major units
+// Columns hold amounts in the settlement currency unless the row records another
+// currency, which the repository sets after scanning.
+func (m *Money) Scan(src interface{}) error {
+	currency := m.Currency
+	if currency == "" {
+		currency = SettlementCurrency
+	}
+
+	switch value := src.(type) {
+	case nil:
+		*m = Money{Currency: currency}
+	case []byte:
+		parsed, err := ParseMoney(string(value), currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(value, currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case float64:
+		*m = MoneyFromFloat(value, currency)
+	case int64:
+		*m = Money{Amount: value * 100, Currency: currency}
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+// Value writes the amount to a DECIMAL column in major units
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// ExchangeRates converts provider prices into the settlement currency
+type ExchangeRates struct {
+	Base  Currency             // Currency amounts are converted into
+	Rates map[Currency]float64 // Units of Base per unit of the currency
+}
+
+// DefaultExchangeRates returns rates that only accept the settlement currency
+func DefaultExchangeRates() *ExchangeRates {
+	return &ExchangeRates{Base: SettlementCurrency, Rates: map[Currency]float64{}}
+}
+
+// ParseExchangeRates parses a comma-separated list of rates such as "USD=92.5,EUR=100.1"
+// Currencies whose minor unit is not a hundredth (JPY, KWD, ...) are rejected.
+func ParseExchangeRates(list string) (*ExchangeRates, error) {
+	rates := DefaultExchangeRates()
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q: expected CODE=rate", entry)
+		}
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkHundredths(currency); err != nil {
+			return nil, err
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q", entry)
+		}
+		rates.Rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// Convert converts an amount into the base currency, rounded to whole minor units
+// Nil rates only accept the settlement currency; amounts in a currency whose minor unit
+// is not a hundredth fail with ErrUnsupportedCurrency.
+func (r *ExchangeRates) Convert(amount Money) (Money, error) {
+	if r == nil {
+		r = DefaultExchangeRates()
+	}
+	if amount.Currency == r.Base || amount.Currency == "" {
+		return Money{Amount: amount.Amount, Currency: r.Base}, nil
+	}
+	if err := checkHundredths(amount.Currency); err != nil {
+		return Money{}, err
+	}
+
+	rate, ok := r.Rates[amount.Currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w from %s to %s", ErrNoExchangeRate, amount.Currency, r.Base)
+	}
+	return Money{Amount: int64(math.Round(float64(amount.Amount) * rate)), Currency: r.Base}, nil
+}
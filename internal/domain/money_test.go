@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]int64{
+		"1500":    150000,
+		"1500.5":  150050,
+		"1500.50": 150050,
+		"0.07":    7,
+		"-12.30":  -1230,
+		"99.9900": 9999, // DECIMAL(10,4)
+	}
+	for input, want := range cases {
+		got, err := ParseMoney(input, CurrencyRUB)
+		if err != nil || got != NewMoney(want, CurrencyRUB) {
+			t.Errorf("ParseMoney(%q) = %v (%v), want %d kopecks", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1.005", ".5"} {
+		if _, err := ParseMoney(input, CurrencyRUB); err == nil {
+			t.Errorf("expected ParseMoney(%q) to fail", input)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is exact in kopecks
+	if sum := Rubles(0.1).Add(Rubles(0.2)); sum != Rubles(0.3) || sum.Decimal() != "0.30" {
+		t.Fatalf("expected 0.30 got %v", sum)
+	}
+	if diff := Rubles(100).Sub(Rubles(150.25)); diff.String() != "-50.25 RUB" {
+		t.Fatalf("expected -50.25 RUB got %v", diff)
+	}
+	if share := Rubles(1234.55).Mul(0.75); share != Rubles(925.91) {
+		t.Fatalf("expected a share rounded to kopecks got %v", share)
+	}
+
+	// A zero amount without a currency adopts the other operand's currency
+	if total := (Money{}).Add(NewMoney(500, CurrencyUSD)); total.Currency != CurrencyUSD {
+		t.Fatalf("expected USD got %v", total)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected adding rubles to dollars to panic")
+		}
+	}()
+	Rubles(1).Add(NewMoney(1, CurrencyUSD))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	// Amounts stored before they had a currency are bare numbers in rubles
+	var legacy Money
+	if err := json.Unmarshal([]byte(`1500.5`), &legacy); err != nil || legacy != Rubles(1500.5) {
+		t.Fatalf("expected legacy rubles got %v (%v)", legacy, err)
+	}
+
+	data, err := json.Marshal(NewMoney(999, CurrencyEUR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Money
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != NewMoney(999, CurrencyEUR) {
+		t.Fatalf("round trip of %s gave %v (%v)", data, decoded, err)
+	}
+}
+
+func TestExchangeRates(t *testing.T) {
+	rates, err := ParseExchangeRates("usd=92.5, CNY=12.75")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if converted, err := rates.Convert(NewMoney(1000, CurrencyUSD)); err != nil || converted != Rubles(925) {
+		t.Fatalf("expected 925 RUB got %v (%v)", converted, err)
+	}
+	if converted, err := rates.Convert(Rubles(10)); err != nil || converted != Rubles(10) {
+		t.Fatalf("expected rubles to pass through got %v (%v)", converted, err)
+	}
+	if _, err := rates.Convert(NewMoney(100, CurrencyEUR)); !errors.Is(err, ErrNoExchangeRate) {
+		t.Fatalf("expected no rate for EUR got %v", err)
+	}
+
+	for _, list := range []string{"USD", "USD=0", "DOLLAR=92.5"} {
+		if _, err := ParseExchangeRates(list); err == nil {
+			t.Errorf("expected %q to be rejected", list)
+		}
+	}
+
+	// Yen have no minor unit and dinars a thousandth: Money cannot count them in hundredths
+	for _, list := range []string{"JPY=0.62", "USD=92.5,KWD=300"} {
+		if _, err := ParseExchangeRates(list); !errors.Is(err, ErrUnsupportedCurrency) {
+			t.Errorf("expected %q to be rejected as unsupported, got %v", list, err)
+		}
+	}
+	if _, err := rates.Convert(NewMoney(1000, "JPY")); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Fatalf("expected a yen amount to be rejected got %v", err)
+	}
+}
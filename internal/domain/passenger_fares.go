@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Age limits of passenger fare categories (age on the departure date)
+const (
+	InfantMaxAge = 2  // Infants are younger than 2
+	ChildMaxAge  = 12 // Children are younger than 12
+)
+
+// PassengerTypeOn returns the fare category for a passenger born on dateOfBirth travelling on date
+func PassengerTypeOn(dateOfBirth, date time.Time) PassengerType {
+	age := date.Year() - dateOfBirth.Year()
+	if date.Month() < dateOfBirth.Month() || (date.Month() == dateOfBirth.Month() && date.Day() < dateOfBirth.Day()) {
+		age--
+	}
+
+	switch {
+	case age < InfantMaxAge:
+		return PassengerInfant
+	case age < ChildMaxAge:
+		return PassengerChild
+	default:
+		return PassengerAdult
+	}
+}
+
+// PassengerFares holds the share of the adult fare paid by children and infants
+// per transport type (1 = full fare, 0 = free)
+type PassengerFares map[TransportType]map[PassengerType]float64
+
+// DefaultPassengerFares returns typical Russian carrier discounts:
+// air children pay 75% and infants without a seat 10%; rail, bus and river
+// children pay half and infants travel free
+func DefaultPassengerFares() PassengerFares {
+	ground := map[PassengerType]float64{PassengerChild: 0.5, PassengerInfant: 0}
+
+	return PassengerFares{
+		TransportAir:     {PassengerChild: 0.75, PassengerInfant: 0.1},
+		TransportRail:    ground,
+		TransportBus:     ground,
+		TransportRiver:   ground,
+		TransportFerry:   ground,
+		TransportIceRoad: ground,
+	}
+}
+
+// Multiplier returns the share of the adult fare paid by the passenger type
+func (f PassengerFares) Multiplier(transportType TransportType, passengerType PassengerType) float64 {
+	if passengerType == PassengerAdult || passengerType == "" {
+		return 1
+	}
+	if byType, ok := f[transportType]; ok {
+		if multiplier, ok := byType[passengerType]; ok {
+			return multiplier
+		}
+	}
+	return 1
+}
+
+// Fare returns the price the passenger type pays for a segment with the given adult price
+func (f PassengerFares) Fare(transportType TransportType, passengerType PassengerType, adultPrice Money) Money {
+	return adultPrice.Mul(f.Multiplier(transportType, passengerType))
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPassengerTypeOn(t *testing.T) {
+	travel := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		dob      time.Time
+		expected PassengerType
+	}{
+		{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), PassengerInfant},
+		{time.Date(2023, 7, 2, 0, 0, 0, 0, time.UTC), PassengerInfant}, // turns 2 the day after
+		{time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC), PassengerChild},
+		{time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), PassengerChild},
+		{time.Date(2013, 6, 30, 0, 0, 0, 0, time.UTC), PassengerAdult},
+	}
+
+	for _, tc := range cases {
+		if got := PassengerTypeOn(tc.dob, travel); got != tc.expected {
+			t.Fatalf("born %s: expected %s got %s", tc.dob.Format("2006-01-02"), tc.expected, got)
+		}
+	}
+}
+
+func TestPassengerFares(t *testing.T) {
+	fares := DefaultPassengerFares()
+
+	if got := fares.Fare(TransportAir, PassengerChild, Rubles(10000)); got != Rubles(7500) {
+		t.Fatalf("expected air child fare 7500 got %v", got)
+	}
+	if got := fares.Fare(TransportRiver, PassengerInfant, Rubles(3500)); !got.IsZero() {
+		t.Fatalf("expected free river infant got %v", got)
+	}
+	if got := fares.Fare(TransportTaxi, PassengerChild, Rubles(500)); got != Rubles(500) {
+		t.Fatalf("expected full fare for unlisted transport got %v", got)
+	}
+}
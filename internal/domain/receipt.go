@@ -0,0 +1,198 @@
+package domain
+
+// VATCode is the VAT rate of a receipt item (54-FZ), numbered as YooKassa's vat_code
+type VATCode int
+
+const (
+	VATNone VATCode = 1 // Not subject to VAT (e.g. insurance)
+	VAT0    VATCode = 2
+	VAT10   VATCode = 3
+	VAT20   VATCode = 4
+)
+
+// ReceiptSubject is what a receipt item is paid for (54-FZ tag 1212)
+type ReceiptSubject string
+
+const (
+	ReceiptSubjectService         ReceiptSubject = "service"          // Tickets and insurance
+	ReceiptSubjectAgentCommission ReceiptSubject = "agent_commission" // Our commission
+)
+
+// ReceiptCustomer is who the receipt is sent to; at least one contact is required
+type ReceiptCustomer struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"` // Digits only, e.g. 79001234567
+}
+
+// ReceiptItem is one line of a fiscal receipt
+type ReceiptItem struct {
+	Description     string         `json:"description"`
+	Amount          Money          `json:"amount"` // Quantity is always 1
+	VAT             VATCode        `json:"vat_code"`
+	Subject         ReceiptSubject `json:"subject"`
+	BookedSegmentID string         `json:"booked_segment_id,omitempty"` // Ticket the item is for; not sent to the gateway
+}
+
+// Receipt is the online cash register receipt (54-FZ) of a payment or refund
+type Receipt struct {
+	Customer ReceiptCustomer `json:"customer"`
+	Items    []ReceiptItem   `json:"items"`
+}
+
+// Total returns the sum of the receipt's items
+func (r *Receipt) Total() Money {
+	var total Money
+	for _, item := range r.Items {
+		total = total.Add(item.Amount)
+	}
+	return total
+}
+
+// Split returns the receipt of the first items up to an amount, and the receipt of the rest
+// The item reaching past the amount is cut in two. A nil receipt splits into nil receipts.
+func (r *Receipt) Split(amount Money) (*Receipt, *Receipt) {
+	if r == nil {
+		return nil, nil
+	}
+
+	head := &Receipt{Customer: r.Customer}
+	rest := &Receipt{Customer: r.Customer}
+	left := amount.Amount
+	for _, item := range r.Items {
+		switch {
+		case left >= item.Amount.Amount:
+			head.Items = append(head.Items, item)
+			left -= item.Amount.Amount
+		case left > 0:
+			cut := item
+			cut.Amount = Money{Amount: left, Currency: item.Amount.Currency}
+			head.Items = append(head.Items, cut)
+			item.Amount = item.Amount.Sub(cut.Amount)
+			rest.Items = append(rest.Items, item)
+			left = 0
+		default:
+			rest.Items = append(rest.Items, item)
+		}
+	}
+	return head, rest
+}
+
+// RefundPart is what one of a booking's payments gives back of a refund
+type RefundPart struct {
+	Payment *Payment
+	Amount  Money
+	Receipt *Receipt // nil when the refund has no receipt
+}
+
+// SplitRefund splits a refund over the booking's payments, newest first, each up to what
+// it has left. A receipt item goes to the payments that were taken for it: a ticket to the
+// payments of the ticket or of the tickets it replaced, the commission and insurance to the
+// payment whose receipt has them. Whatever that leaves is cut from the rest of the receipt
+// in turn, so the amounts always add up.
+func (b *Booking) SplitRefund(amount Money, receipt *Receipt) []RefundPart {
+	payments := b.RefundablePayments()
+	parts := make([]RefundPart, len(payments))
+	left := make([]Money, len(payments))
+	for i, payment := range payments {
+		parts[i] = RefundPart{Payment: payment, Amount: Money{Currency: amount.Currency}}
+		if receipt != nil {
+			parts[i].Receipt = &Receipt{Customer: receipt.Customer}
+		}
+		left[i] = payment.RefundableAmount()
+	}
+
+	// 1. Items to the payments taken for them
+	if receipt != nil {
+		rest := &Receipt{Customer: receipt.Customer}
+		for _, item := range receipt.Items {
+			for i, payment := range payments {
+				if !item.Amount.IsPositive() || !amount.IsPositive() {
+					break
+				}
+				if !b.paidFor(payment, item) {
+					continue
+				}
+				take := MinMoney(MinMoney(item.Amount, left[i]), amount)
+				if !take.IsPositive() {
+					continue
+				}
+
+				cut := item
+				cut.Amount = take
+				parts[i].Receipt.Items = append(parts[i].Receipt.Items, cut)
+				parts[i].Amount = parts[i].Amount.Add(take)
+				left[i] = left[i].Sub(take)
+				amount = amount.Sub(take)
+				item.Amount = item.Amount.Sub(take)
+			}
+			if item.Amount.IsPositive() {
+				rest.Items = append(rest.Items, item)
+			}
+		}
+		receipt = rest
+	}
+
+	// 2. The rest newest first
+	for i := range parts {
+		if !amount.IsPositive() {
+			break
+		}
+		part := MinMoney(amount, left[i])
+		if !part.IsPositive() {
+			continue
+		}
+		var head *Receipt
+		head, receipt = receipt.Split(part)
+		if head != nil {
+			parts[i].Receipt.Items = append(parts[i].Receipt.Items, head.Items...)
+		}
+		parts[i].Amount = parts[i].Amount.Add(part)
+		amount = amount.Sub(part)
+	}
+
+	refunded := make([]RefundPart, 0, len(parts))
+	for _, part := range parts {
+		if part.Amount.IsPositive() {
+			refunded = append(refunded, part)
+		}
+	}
+	return refunded
+}
+
+// paidFor reports whether a payment was taken for a receipt item, going by the payment's
+// own receipt: the item's ticket or a ticket it replaced, or for an item of no ticket
+// (commission, insurance) an item of the same kind
+func (b *Booking) paidFor(payment *Payment, item ReceiptItem) bool {
+	if payment.Receipt == nil {
+		return false
+	}
+
+	for _, paid := range payment.Receipt.Items {
+		switch {
+		case item.BookedSegmentID == "":
+			if paid.BookedSegmentID == "" && paid.Subject == item.Subject {
+				return true
+			}
+		case paid.BookedSegmentID != "":
+			if b.replacedBy(paid.BookedSegmentID, item.BookedSegmentID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replacedBy reports whether ticket from is ticket to, or was replaced by it in booking changes
+func (b *Booking) replacedBy(from, to string) bool {
+	for id, hops := from, 0; id != "" && hops <= len(b.Segments); hops++ {
+		if id == to {
+			return true
+		}
+		ticket, ok := b.FindSegment(id)
+		if !ok {
+			return false
+		}
+		id = ticket.ReplacedBy
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package domain
+
+import "testing"
+
+func TestReceiptSplit(t *testing.T) {
+	receipt := &Receipt{
+		Customer: ReceiptCustomer{Email: "ivan@example.com"},
+		Items: []ReceiptItem{
+			{Description: "Билет Якутск — Нерюнгри", Amount: Rubles(1000), VAT: VAT0},
+			{Description: "Сервисный сбор LenaLink", Amount: Rubles(70), VAT: VAT20, Subject: ReceiptSubjectAgentCommission},
+			{Description: "Страховой полис", Amount: Rubles(300), VAT: VATNone},
+		},
+	}
+
+	// The commission is cut where the amount runs out; each part keeps its item's VAT
+	head, rest := receipt.Split(Rubles(1035))
+	if head.Total() != Rubles(1035) || rest.Total() != Rubles(335) || head.Customer != receipt.Customer {
+		t.Fatalf("expected 1035 + 335 RUB, got %v + %v", head.Total(), rest.Total())
+	}
+	if len(head.Items) != 2 || head.Items[1].Amount != Rubles(35) || head.Items[1].VAT != VAT20 {
+		t.Fatalf("unexpected first part: %+v", head.Items)
+	}
+	if len(rest.Items) != 2 || rest.Items[0].Amount != Rubles(35) || rest.Items[1].VAT != VATNone {
+		t.Fatalf("unexpected rest: %+v", rest.Items)
+	}
+
+	if whole, rest := receipt.Split(receipt.Total()); len(whole.Items) != 3 || len(rest.Items) != 0 {
+		t.Fatalf("expected the whole receipt to fit: %+v", whole)
+	}
+	if head, rest := (*Receipt)(nil).Split(Rubles(10)); head != nil || rest != nil {
+		t.Fatal("expected no receipts without a receipt")
+	}
+}
+
+func TestBookingSplitRefund(t *testing.T) {
+	customer := ReceiptCustomer{Email: "ivan@example.com"}
+	booking := &Booking{
+		Segments: []BookedSegment{
+			{ID: "t1", BookingStatus: BookingConfirmed},
+			{ID: "t2", BookingStatus: BookingCancelled, ReplacedBy: "t3"},
+			{ID: "t3", BookingStatus: BookingConfirmed},
+		},
+		Payment: &Payment{ID: "pay", Amount: Rubles(6600), Status: PaymentCompleted, Receipt: &Receipt{Customer: customer, Items: []ReceiptItem{
+			{Description: "Билет 1", Amount: Rubles(3000), BookedSegmentID: "t1", Subject: ReceiptSubjectService},
+			{Description: "Билет 2", Amount: Rubles(3000), BookedSegmentID: "t2", Subject: ReceiptSubjectService},
+			{Description: "Сервисный сбор LenaLink", Amount: Rubles(600), Subject: ReceiptSubjectAgentCommission},
+		}}},
+		// The surcharge of changing t2 to the dearer t3
+		Charges: []Payment{{ID: "charge", Amount: Rubles(1500), Status: PaymentCompleted, Receipt: &Receipt{Customer: customer, Items: []ReceiptItem{
+			{Description: "Доплата за обмен билета", Amount: Rubles(1500), BookedSegmentID: "t3", Subject: ReceiptSubjectService},
+		}}}},
+	}
+
+	// A ticket the surcharge did not cover is refunded from the booking's payment alone
+	parts := booking.SplitRefund(Rubles(3000), &Receipt{Customer: customer, Items: []ReceiptItem{
+		{Description: "Билет 1", Amount: Rubles(2700), BookedSegmentID: "t1", Subject: ReceiptSubjectService},
+		{Description: "Сервисный сбор LenaLink", Amount: Rubles(300), Subject: ReceiptSubjectAgentCommission},
+	}})
+	if len(parts) != 1 || parts[0].Payment.ID != "pay" || parts[0].Amount != Rubles(3000) || len(parts[0].Receipt.Items) != 2 {
+		t.Fatalf("expected the whole refund from the booking's payment, got %+v", parts)
+	}
+
+	// The changed ticket is refunded from the surcharge first, then from what was paid for t2
+	parts = booking.SplitRefund(Rubles(4500), &Receipt{Customer: customer, Items: []ReceiptItem{
+		{Description: "Билет 3", Amount: Rubles(4500), BookedSegmentID: "t3", Subject: ReceiptSubjectService},
+	}})
+	if len(parts) != 2 || parts[0].Payment.ID != "charge" || parts[0].Amount != Rubles(1500) || parts[1].Amount != Rubles(3000) {
+		t.Fatalf("expected 1500 from the surcharge and 3000 from the payment, got %+v", parts)
+	}
+	if parts[0].Receipt.Total() != parts[0].Amount || parts[1].Receipt.Items[0].BookedSegmentID != "t3" {
+		t.Fatalf("expected each receipt to list the refunded ticket, got %+v and %+v", parts[0].Receipt, parts[1].Receipt)
+	}
+
+	// Without a receipt the payments are drawn on newest first
+	parts = booking.SplitRefund(Rubles(2000), nil)
+	if len(parts) != 2 || parts[0].Amount != Rubles(1500) || parts[0].Receipt != nil || parts[1].Amount != Rubles(500) {
+		t.Fatalf("expected 1500 + 500 without receipts, got %+v", parts)
+	}
+}
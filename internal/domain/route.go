@@ -6,12 +6,14 @@ import "time"
 type TransportType string
 
 const (
-	TransportAir    TransportType = "air"
-	TransportRail   TransportType = "rail"
-	TransportBus    TransportType = "bus"
-	TransportRiver  TransportType = "river"
-	TransportTaxi   TransportType = "taxi"
-	TransportWalk   TransportType = "walk"
+	TransportAir     TransportType = "air"
+	TransportRail    TransportType = "rail"
+	TransportBus     TransportType = "bus"
+	TransportRiver   TransportType = "river"
+	TransportTaxi    TransportType = "taxi"
+	TransportWalk    TransportType = "walk"
+	TransportFerry   TransportType = "ferry"    // River crossings, run during navigation
+	TransportIceRoad TransportType = "ice_road" // Winter roads (zimniks) and ice crossings
 )
 
 // Stop represents a location on a route
@@ -19,10 +21,13 @@ type Stop struct {
 	ID          string     `json:"id"`
 	Name        string     `json:"name"`
 	City        string     `json:"city"`
+	CityID      string     `json:"city_id,omitempty"` // Canonical city (see City)
 	Latitude    float64    `json:"latitude"`
 	Longitude   float64    `json:"longitude"`
 	ArrivalAt   *time.Time `json:"arrival_at,omitempty"`
 	DepartureAt *time.Time `json:"departure_at,omitempty"`
+	Season      Season     `json:"season,omitempty"` // e.g. ice crossing landings; empty = all year
+	Type        string     `json:"type,omitempty"`   // airport, port, station, terminal
 }
 
 // Segment represents a single transport leg
@@ -34,22 +39,42 @@ type Segment struct {
 	EndStop         Stop          `json:"end_stop"`
 	DepartureTime   time.Time     `json:"departure_time"`
 	ArrivalTime     time.Time     `json:"arrival_time"`
-	Price           float64       `json:"price"`
+	Price           Money         `json:"price"` // As quoted by the provider, converted for pricing
 	Duration        time.Duration `json:"duration"`
 	SeatCount       int           `json:"seat_count"`
 	ReliabilityRate float64       `json:"reliability_rate"`
 	Distance        int           `json:"distance"`
+	Season          Season        `json:"season,omitempty"` // Empty = default for the transport type
+	Source          string        `json:"source,omitempty"` // Provider the segment was synced from: gars, aviasales, rzd
+	Tariff          string        `json:"tariff,omitempty"` // Provider tariff or service class, selects the fare rule
+	Status          SegmentStatus `json:"status,omitempty"` // Empty = scheduled
+}
+
+// SegmentStatus defines whether a synced segment still runs
+type SegmentStatus string
+
+const (
+	SegmentScheduled SegmentStatus = "scheduled"
+	SegmentCancelled SegmentStatus = "cancelled" // Cancelled by the carrier
+)
+
+// IsCancelled checks if the carrier cancelled the segment
+func (s *Segment) IsCancelled() bool {
+	return s.Status == SegmentCancelled
 }
 
 // Connection represents a transfer between segments
 type Connection struct {
 	From              *Segment      `json:"from,omitempty"`
 	To                *Segment      `json:"to,omitempty"`
-	TransferDuration  time.Duration `json:"transfer_duration"`
-	TransferDistance  int           `json:"transfer_distance"`
+	TransferDuration  time.Duration `json:"transfer_duration"`        // Walk/taxi time between stops, or the whole gap at the same stop
+	TransferDistance  int           `json:"transfer_distance"`        // km
+	TransferType      TransportType `json:"transfer_type,omitempty"`  // walk or taxi between different stops
+	TransferPrice     Money         `json:"transfer_price,omitempty"` // Estimated taxi fare (not included in the route price)
 	RequiresTransport bool          `json:"requires_transport"`
-	IsValid           bool          `json:"is_valid"`
-	Gap               time.Duration `json:"gap"`
+	IsValid           bool          `json:"is_valid"` // Gap covers the transfer and the minimum connection time
+	Gap               time.Duration `json:"gap"`      // Time between arrival and next departure
+	MinConnectionTime time.Duration `json:"min_connection_time"`
 }
 
 // Route represents a complete journey with multiple segments
@@ -62,28 +87,68 @@ type Route struct {
 	TotalDuration     time.Duration    `json:"total_duration"`
 	Segments          []Segment        `json:"segments"`
 	Connections       []Connection     `json:"connections,omitempty"`
-	TotalPrice        float64          `json:"total_price"`
+	TotalPrice        Money            `json:"total_price"`
 	ReliabilityScore  float64          `json:"reliability_score"`
-	InsurancePremium  float64          `json:"insurance_premium"`
+	InsurancePremium  Money            `json:"insurance_premium"`
 	InsuranceIncluded bool             `json:"insurance_included"`
 	TransportTypes    []TransportType  `json:"transport_types"`
 	SavedAt           time.Time        `json:"saved_at"`
 }
 
+// SearchMode defines where route search takes its routes from
+type SearchMode string
+
+const (
+	SearchModeAuto  SearchMode = "auto"  // Saved routes first, live composition if none found
+	SearchModeSaved SearchMode = "saved" // Only routes stored in the routes table
+	SearchModeLive  SearchMode = "live"  // Routes composed from synced segments
+)
+
+// CostWeights blends route criteria into a single cost used to pick the optimal route
+// Price is weighed per 1000 RUB, time per hour and transfers per change. The weights
+// only rank the routes found; they do not change which routes the search finds.
+type CostWeights struct {
+	Price     float64
+	Time      float64
+	Transfers float64
+}
+
 // RouteSearchCriteria represents search parameters
 type RouteSearchCriteria struct {
 	FromCity         string
 	ToCity           string
+	FromCityID       string // Canonical city IDs, set once the names are resolved
+	ToCityID         string
 	DepartureDate    time.Time
 	PassengerCount   int
 	PreferredTransport []TransportType
 	MaxConnections   int
 	MaxTransferTime  int // minutes
-	BudgetMax        float64
+	BudgetMax        float64 // In the settlement currency
 	BudgetMin        float64
+	Mode             SearchMode
+	Alternatives     int          // Maximum number of ranked alternatives to return
+	Weights          *CostWeights // Optional blend for the optimal route (default: reliability)
+}
+
+// FromCityKey identifies the origin city: its canonical ID when resolved, otherwise the name
+func (c *RouteSearchCriteria) FromCityKey() string {
+	if c.FromCityID != "" {
+		return c.FromCityID
+	}
+	return c.FromCity
+}
+
+// ToCityKey identifies the destination city: its canonical ID when resolved, otherwise the name
+func (c *RouteSearchCriteria) ToCityKey() string {
+	if c.ToCityID != "" {
+		return c.ToCityID
+	}
+	return c.ToCity
 }
 
 // RouteSearchResult contains 3 optimized routes
+// and a ranked list of non-dominated alternatives
 type RouteSearchResult struct {
 	RequestID     string
 	FromCity      string
@@ -93,5 +158,6 @@ type RouteSearchResult struct {
 	OptimalRoute  *Route
 	FastestRoute  *Route
 	CheapestRoute *Route
+	Alternatives  []Route
 	SearchedAt    time.Time
 }
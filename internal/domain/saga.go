@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// SagaStatus defines the state of a booking saga
+type SagaStatus string
+
+const (
+	SagaRunning      SagaStatus = "running"      // Steps are being executed
+	SagaCompleted    SagaStatus = "completed"    // All steps succeeded
+	SagaCompensating SagaStatus = "compensating" // A step failed, completed steps are being undone
+	SagaCompensated  SagaStatus = "compensated"  // All completed steps were undone
+	SagaFailed       SagaStatus = "failed"       // Compensation gave up, needs manual attention
+)
+
+// SagaStepType defines what a saga step does
+type SagaStepType string
+
+const (
+	SagaStepBookSegment    SagaStepType = "book_segment"    // ProviderBookingService.BookSegment
+	SagaStepProcessPayment SagaStepType = "process_payment" // PaymentService.ProcessPayment
+	SagaStepCapturePayment SagaStepType = "capture_payment" // PaymentService.Capture (pay-first flow)
+)
+
+// SagaStepStatus defines the state of a saga step
+type SagaStepStatus string
+
+const (
+	SagaStepPending            SagaStepStatus = "pending"             // Started, outcome unknown
+	SagaStepDone               SagaStepStatus = "done"                // Succeeded
+	SagaStepFailed             SagaStepStatus = "failed"              // Failed, nothing to undo
+	SagaStepCompensated        SagaStepStatus = "compensated"         // Succeeded, then undone
+	SagaStepCompensationFailed SagaStepStatus = "compensation_failed" // Could not be undone
+)
+
+// SagaStep is one recorded action of a booking saga
+type SagaStep struct {
+	ID            string         `json:"id"`
+	SagaID        string         `json:"saga_id"`
+	Sequence      int            `json:"sequence"`
+	Type          SagaStepType   `json:"type"`
+	SegmentID     string         `json:"segment_id,omitempty"`
+	PassengerID   string         `json:"passenger_id,omitempty"`
+	Status        SagaStepStatus `json:"status"`
+	Reference     string         `json:"reference,omitempty"` // Provider booking ref or payment ID, needed to compensate
+	Error         string         `json:"error,omitempty"`
+	Attempts      int            `json:"attempts"` // Compensation attempts
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// NeedsCompensation reports whether the step succeeded (or may have) and has not been undone
+func (s *SagaStep) NeedsCompensation() bool {
+	return s.Status == SagaStepDone || s.Status == SagaStepPending
+}
+
+// BookingSaga records the steps of a multi-segment booking so that
+// a crash or failure midway can be compensated later
+type BookingSaga struct {
+	ID        string     `json:"id"`
+	BookingID string     `json:"booking_id"`
+	Status    SagaStatus `json:"status"`
+	Steps     []SagaStep `json:"steps"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsFinished reports whether the saga needs no further work
+func (s *BookingSaga) IsFinished() bool {
+	return s.Status == SagaCompleted || s.Status == SagaCompensated || s.Status == SagaFailed
+}
+
+// IsStuck reports whether the saga needs manual attention: compensation gave up,
+// or it has not progressed for longer than the given duration
+func (s *BookingSaga) IsStuck(now time.Time, after time.Duration) bool {
+	if s.Status == SagaFailed {
+		return true
+	}
+	return !s.IsFinished() && now.Sub(s.UpdatedAt) > after
+}
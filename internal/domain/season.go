@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeasonWindow is a yearly period when transport runs, in MM-DD format.
+// A window whose From is after To wraps around the new year
+// (e.g. a winter road open from 12-20 to 04-15).
+type SeasonWindow struct {
+	From string `json:"from"` // MM-DD, inclusive
+	To   string `json:"to"`   // MM-DD, inclusive
+}
+
+// Season is a validity calendar made of yearly windows
+// An empty season means the transport runs all year
+type Season []SeasonWindow
+
+// Typical Yakutia seasons used when a segment has no calendar of its own
+var (
+	// SeasonRiverNavigation is the summer navigation window on the Lena
+	SeasonRiverNavigation = Season{{From: "05-25", To: "10-10"}}
+	// SeasonWinterRoad is when zimniks and ice crossings are open
+	SeasonWinterRoad = Season{{From: "12-20", To: "04-15"}}
+)
+
+// DefaultSeason returns the usual season for a transport type
+func DefaultSeason(transportType TransportType) Season {
+	switch transportType {
+	case TransportRiver, TransportFerry:
+		return SeasonRiverNavigation
+	case TransportIceRoad:
+		return SeasonWinterRoad
+	default:
+		return nil
+	}
+}
+
+// Validate checks that every window uses valid MM-DD dates
+func (s Season) Validate() error {
+	for _, window := range s {
+		if _, err := parseMonthDay(window.From); err != nil {
+			return err
+		}
+		if _, err := parseMonthDay(window.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether the transport runs on the given date
+func (s Season) IsOpen(date time.Time) bool {
+	if len(s) == 0 {
+		return true
+	}
+
+	for _, window := range s {
+		if window.Contains(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the date falls inside the window
+// Windows with invalid dates never match
+func (w SeasonWindow) Contains(date time.Time) bool {
+	from, err := parseMonthDay(w.From)
+	if err != nil {
+		return false
+	}
+	to, err := parseMonthDay(w.To)
+	if err != nil {
+		return false
+	}
+
+	day := int(date.Month())*100 + date.Day()
+	if from <= to {
+		return day >= from && day <= to
+	}
+	// Wraps around the new year
+	return day >= from || day <= to
+}
+
+// parseMonthDay converts MM-DD to a comparable MMDD number
+func parseMonthDay(value string) (int, error) {
+	parsed, err := time.Parse("01-02", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid season date %q (use MM-DD): %w", value, err)
+	}
+	return int(parsed.Month())*100 + parsed.Day(), nil
+}
+
+// EffectiveSeason returns the segment's own calendar or the default for its transport type
+func (s *Segment) EffectiveSeason() Season {
+	if len(s.Season) > 0 {
+		return s.Season
+	}
+	return DefaultSeason(s.TransportType)
+}
+
+// IsAvailableOn reports whether the segment and both of its stops are in season
+func (s *Segment) IsAvailableOn(date time.Time) bool {
+	return s.EffectiveSeason().IsOpen(date) &&
+		s.StartStop.Season.IsOpen(date) &&
+		s.EndStop.Season.IsOpen(date)
+}
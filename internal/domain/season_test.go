@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonIsOpen(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name     string
+		season   Season
+		date     time.Time
+		expected bool
+	}{
+		{"all year", nil, date(time.February, 1), true},
+		{"navigation open", SeasonRiverNavigation, date(time.July, 15), true},
+		{"navigation closed", SeasonRiverNavigation, date(time.January, 15), false},
+		{"winter road after new year", SeasonWinterRoad, date(time.February, 1), true},
+		{"winter road before new year", SeasonWinterRoad, date(time.December, 25), true},
+		{"winter road closed", SeasonWinterRoad, date(time.June, 1), false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.season.IsOpen(tc.date); got != tc.expected {
+			t.Fatalf("%s: expected %v got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestSegmentIsAvailableOnUsesStopSeason(t *testing.T) {
+	segment := Segment{
+		TransportType: TransportBus,
+		EndStop:       Stop{Season: SeasonWinterRoad},
+	}
+
+	if segment.IsAvailableOn(time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected segment to a winter-only stop to be unavailable in July")
+	}
+}
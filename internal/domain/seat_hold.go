@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeatHoldStatus defines the state of a seat hold
+type SeatHoldStatus string
+
+const (
+	SeatHoldActive    SeatHoldStatus = "active"    // Seats are reserved until ExpiresAt
+	SeatHoldConfirmed SeatHoldStatus = "confirmed" // Booking paid, seats sold until the provider reflects them
+	SeatHoldReleased  SeatHoldStatus = "released"  // Booking failed or payment cancelled
+	SeatHoldExpired   SeatHoldStatus = "expired"   // Nobody confirmed the hold in time
+)
+
+// SeatHold reserves seats on a segment while a booking is being made
+type SeatHold struct {
+	ID        string         `json:"id"`
+	SegmentID string         `json:"segment_id"`
+	BookingID string         `json:"booking_id"`
+	Seats     int            `json:"seats"`
+	Status    SeatHoldStatus `json:"status"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// IsActive reports whether the hold still blocks seats at the given time
+func (h *SeatHold) IsActive(now time.Time) bool {
+	return h.Status == SeatHoldActive && now.Before(h.ExpiresAt)
+}
+
+// HasSeats reports whether the transport type sells a limited number of seats
+// Walking and taxi transfers are not held.
+func (t TransportType) HasSeats() bool {
+	return t != TransportWalk && t != TransportTaxi
+}
+
+// SeatsRequired returns how many seats the passengers occupy
+// Infants travel on an adult's lap and do not need a seat.
+func SeatsRequired(passengers []Passenger) int {
+	seats := 0
+	for _, passenger := range passengers {
+		if passenger.Type != PassengerInfant {
+			seats++
+		}
+	}
+	return seats
+}
+
+// NewSeatsUnavailableError reports that a segment cannot seat the requested passengers
+func NewSeatsUnavailableError(segmentID string, requested, available int) DomainError {
+	return NewDomainError("SEATS_UNAVAILABLE",
+		fmt.Sprintf("Segment %s has %d seats available, %d requested", segmentID, available, requested))
+}
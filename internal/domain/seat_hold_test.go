@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeatsRequiredSkipsInfants(t *testing.T) {
+	passengers := []Passenger{
+		{Type: PassengerAdult},
+		{Type: PassengerChild},
+		{Type: PassengerInfant},
+	}
+
+	if seats := SeatsRequired(passengers); seats != 2 {
+		t.Fatalf("expected 2 seats got %d", seats)
+	}
+}
+
+func TestSeatHoldExpires(t *testing.T) {
+	now := time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC)
+	hold := SeatHold{Status: SeatHoldActive, ExpiresAt: now.Add(15 * time.Minute)}
+
+	if !hold.IsActive(now) {
+		t.Fatal("expected hold to be active before expiry")
+	}
+	if hold.IsActive(now.Add(15 * time.Minute)) {
+		t.Fatal("expected hold to lapse at expiry")
+	}
+
+	hold.Status = SeatHoldReleased
+	if hold.IsActive(now) {
+		t.Fatal("expected released hold not to block seats")
+	}
+}
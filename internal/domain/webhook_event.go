@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebhookEvent is a payment gateway notification that has been processed
+// Gateways retry a notification until it is acknowledged, so the same event can
+// arrive several times. It is identified by the event type and the object it is about.
+type WebhookEvent struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	Event      string    `json:"event"`     // e.g. payment.succeeded
+	ObjectID   string    `json:"object_id"` // Gateway payment or refund ID
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// NewWebhookEvent identifies a notification of a provider about an object
+func NewWebhookEvent(provider, event, objectID string) *WebhookEvent {
+	return &WebhookEvent{
+		ID:         fmt.Sprintf("%s:%s:%s", provider, event, objectID),
+		Provider:   provider,
+		Event:      event,
+		ObjectID:   objectID,
+		ReceivedAt: time.Now(),
+	}
+}
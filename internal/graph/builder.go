@@ -1,6 +1,8 @@
 package graph
 
 import (
+	"time"
+
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/pkg/utils"
 )
@@ -8,6 +10,7 @@ import (
 // Builder constructs a graph from route data
 type Builder struct {
 	graph *Graph
+	date  time.Time // Travel date used to drop out-of-season segments (zero = keep all)
 }
 
 // NewBuilder creates a new graph builder
@@ -17,6 +20,17 @@ func NewBuilder() *Builder {
 	}
 }
 
+// ForDate makes the builder skip segments and stops that are out of season on the given date
+func (b *Builder) ForDate(date time.Time) *Builder {
+	b.date = date
+	return b
+}
+
+// inSeason reports whether the segment runs on the builder's date
+func (b *Builder) inSeason(segment *domain.Segment) bool {
+	return b.date.IsZero() || segment.IsAvailableOn(b.date)
+}
+
 // BuildFromRoutes constructs a graph from a list of routes
 func (b *Builder) BuildFromRoutes(routes []domain.Route) *Graph {
 	// Add all unique cities/stations as nodes
@@ -24,6 +38,10 @@ func (b *Builder) BuildFromRoutes(routes []domain.Route) *Graph {
 
 	for _, route := range routes {
 		for _, segment := range route.Segments {
+			if !b.inSeason(&segment) {
+				continue
+			}
+
 			// Add start stop as node
 			if !nodeMap[segment.StartStop.ID] {
 				node := NewNode(
@@ -59,10 +77,11 @@ func (b *Builder) BuildFromRoutes(routes []domain.Route) *Graph {
 				segment.Provider,
 				float64(segment.Distance),
 				segment.Duration,
-				segment.Price,
+				segment.Price.Major(),
 			)
 			edge.DepartureTime = segment.DepartureTime
 			edge.ArrivalTime = segment.ArrivalTime
+			edge.Season = segment.EffectiveSeason()
 
 			b.graph.AddEdge(edge)
 		}
@@ -71,6 +90,69 @@ func (b *Builder) BuildFromRoutes(routes []domain.Route) *Graph {
 	return b.graph
 }
 
+// BuildFromSegments constructs a stop-level graph from standalone segments
+// (e.g. synced from GARS, Aviasales and RZD). Each segment becomes one edge,
+// so the edge ID can be used to map a path back to its segment.
+func (b *Builder) BuildFromSegments(segments []domain.Segment) *Graph {
+	for i := range segments {
+		segment := &segments[i]
+		if !b.inSeason(segment) {
+			continue
+		}
+
+		b.addStop(segment.StartStop, segment.TransportType)
+		b.addStop(segment.EndStop, segment.TransportType)
+
+		edge := NewEdge(
+			segment.ID,
+			segment.StartStop.ID,
+			segment.EndStop.ID,
+			string(segment.TransportType),
+			segment.Provider,
+			float64(segment.Distance),
+			segment.Duration,
+			segment.Price.Major(),
+		)
+		edge.DepartureTime = segment.DepartureTime
+		edge.ArrivalTime = segment.ArrivalTime
+		edge.Reliability = segment.ReliabilityRate
+		edge.Season = segment.EffectiveSeason()
+
+		b.graph.AddEdge(edge)
+	}
+
+	return b.graph
+}
+
+// addStop adds a stop as a node unless it is already present
+func (b *Builder) addStop(stop domain.Stop, transportType domain.TransportType) {
+	if _, exists := b.graph.GetNode(stop.ID); exists {
+		return
+	}
+
+	node := NewNode(stop.ID, stop.Name, stop.Latitude, stop.Longitude, nodeTypeFor(transportType))
+	node.City = stop.City
+	node.CityID = stop.CityID
+	node.Season = stop.Season
+	b.graph.AddNode(node)
+}
+
+// nodeTypeFor infers node type from the transport serving the stop
+func nodeTypeFor(transportType domain.TransportType) string {
+	switch transportType {
+	case domain.TransportAir:
+		return "airport"
+	case domain.TransportRail:
+		return "train_station"
+	case domain.TransportBus:
+		return "bus_terminal"
+	case domain.TransportRiver, domain.TransportFerry:
+		return "port"
+	default:
+		return "city_center"
+	}
+}
+
 // AddCity manually adds a city node
 func (b *Builder) AddCity(id, name string, lat, lon float64) {
 	node := NewNode(id, name, lat, lon, "city")
@@ -1,19 +1,26 @@
 package graph
 
-import "time"
+import (
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+)
 
 // Edge represents a connection between two nodes (a transport segment)
 type Edge struct {
 	ID            string        // Unique identifier
 	FromNodeID    string        // Source node
 	ToNodeID      string        // Destination node
-	TransportType string        // air, rail, bus, river, walk, taxi
+	TransportType string        // air, rail, bus, river, ferry, ice_road, walk, taxi
 	Provider      string        // Operator name (S7, RZD, etc.)
 	Distance      float64       // Distance in kilometers
 	Duration      time.Duration // Travel time
 	Price         float64       // Price in currency
 	DepartureTime time.Time     // When it departs (optional, for scheduled transport)
 	ArrivalTime   time.Time     // When it arrives (optional)
+	Reliability   float64       // Provider reliability rating (0-100, optional)
+	Season        domain.Season // When the edge runs (empty = all year)
+	Transfer      bool          // Generated walk/taxi transfer between nearby stops, not a bookable segment
 }
 
 // NewEdge creates a new graph edge
@@ -65,6 +65,21 @@ func (g *Graph) GetNeighbors(nodeID string) []*Edge {
 	return edgesCopy
 }
 
+// NodesInCity returns all nodes that belong to the given city
+// city may be a canonical city ID or a city name
+func (g *Graph) NodesInCity(city string) []*Node {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	nodes := make([]*Node, 0)
+	for _, node := range g.Nodes {
+		if node.City == city || (node.CityID != "" && node.CityID == city) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
 // NodeCount returns the number of nodes in the graph
 func (g *Graph) NodeCount() int {
 	g.mu.RLock()
@@ -1,12 +1,17 @@
 package graph
 
+import "github.com/lenalink/backend/internal/domain"
+
 // Node represents a location in the transportation network
 type Node struct {
-	ID        string  // Unique identifier
-	Name      string  // City or station name
-	Latitude  float64 // Geographic latitude
-	Longitude float64 // Geographic longitude
-	Type      string  // airport, train_station, bus_terminal, port, city_center
+	ID        string        // Unique identifier
+	Name      string        // City or station name
+	City      string        // City the stop belongs to (empty for city-level nodes)
+	CityID    string        // Canonical city ID of the stop, if resolved
+	Latitude  float64       // Geographic latitude
+	Longitude float64       // Geographic longitude
+	Type      string        // airport, train_station, bus_terminal, port, city_center
+	Season    domain.Season // When the stop is served (empty = all year)
 }
 
 // NewNode creates a new graph node
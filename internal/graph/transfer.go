@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// TransferConfig controls generation of walk/taxi edges between nearby stops
+// (e.g. from an Aviasales airport to a GARS bus terminal in the same city)
+type TransferConfig struct {
+	MaxWalkDistance float64       // Stops closer than this (km) are connected on foot
+	MaxTaxiDistance float64       // Stops closer than this (km) are connected by taxi
+	WalkSpeed       float64       // km/h
+	TaxiSpeed       float64       // km/h, average in town including traffic
+	TaxiPickupTime  time.Duration // Waiting for the car
+	TaxiBaseFare    float64       // RUB
+	TaxiPricePerKm  float64       // RUB
+}
+
+// DefaultTransferConfig returns default transfer settings for Yakutia towns
+func DefaultTransferConfig() TransferConfig {
+	return TransferConfig{
+		MaxWalkDistance: 1.0,
+		MaxTaxiDistance: 30.0,
+		WalkSpeed:       4.5,
+		TaxiSpeed:       30.0,
+		TaxiPickupTime:  10 * time.Minute,
+		TaxiBaseFare:    150.0,
+		TaxiPricePerKm:  30.0,
+	}
+}
+
+// kmPerDegreeLatitude is the length of one degree of latitude
+const kmPerDegreeLatitude = 111.0
+
+// AddTransferEdges connects nodes within the configured radius with walk or taxi edges.
+// Distances are computed from the coordinates of the nodes already in the graph, so a
+// search needs no stop lookups; nodes are sorted by latitude and only compared with
+// those in the radius' latitude band. Nodes without coordinates get no transfers.
+func (b *Builder) AddTransferEdges(config TransferConfig) {
+	if config.MaxTaxiDistance <= 0 && config.MaxWalkDistance <= 0 {
+		return
+	}
+
+	radius := math.Max(config.MaxWalkDistance, config.MaxTaxiDistance)
+	band := radius / kmPerDegreeLatitude
+
+	// Snapshot the nodes: edges are only added between existing nodes
+	nodes := make([]*Node, 0, len(b.graph.Nodes))
+	for _, node := range b.graph.Nodes {
+		if node.Latitude == 0 && node.Longitude == 0 {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Latitude < nodes[j].Latitude
+	})
+
+	for i, from := range nodes {
+		for _, to := range nodes[i+1:] {
+			if to.Latitude-from.Latitude > band {
+				break
+			}
+
+			distance := utils.CalculateDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+			if edge := newTransferEdge(from, to, distance, config); edge != nil {
+				b.graph.AddEdge(edge)
+			}
+			if edge := newTransferEdge(to, from, distance, config); edge != nil {
+				b.graph.AddEdge(edge)
+			}
+		}
+	}
+}
+
+// newTransferEdge builds a walk or taxi edge, or returns nil if the stops are too far apart
+func newTransferEdge(from, to *Node, distance float64, config TransferConfig) *Edge {
+	var edge *Edge
+
+	switch {
+	case distance <= config.MaxWalkDistance && config.WalkSpeed > 0:
+		duration := time.Duration(distance / config.WalkSpeed * float64(time.Hour))
+		edge = NewEdge(transferEdgeID(domain.TransportWalk, from.ID, to.ID), from.ID, to.ID,
+			string(domain.TransportWalk), "", distance, duration, 0)
+	case distance <= config.MaxTaxiDistance && config.TaxiSpeed > 0:
+		duration := config.TaxiPickupTime + time.Duration(distance/config.TaxiSpeed*float64(time.Hour))
+		price := math.Round(config.TaxiBaseFare + distance*config.TaxiPricePerKm)
+		edge = NewEdge(transferEdgeID(domain.TransportTaxi, from.ID, to.ID), from.ID, to.ID,
+			string(domain.TransportTaxi), "", distance, duration, price)
+	default:
+		return nil
+	}
+
+	edge.Transfer = true
+	return edge
+}
+
+// transferEdgeID identifies a generated transfer edge
+func transferEdgeID(transportType domain.TransportType, fromID, toID string) string {
+	return fmt.Sprintf("transfer:%s:%s:%s", transportType, fromID, toID)
+}
@@ -0,0 +1,33 @@
+package graph
+
+import "testing"
+
+func TestAddTransferEdges(t *testing.T) {
+	b := NewBuilder()
+	b.AddCity("airport", "Yakutsk Airport", 62.0932, 129.7708)
+	b.AddCity("terminal", "Yakutsk Bus Terminal", 62.0280, 129.7320) // ~7.5 km away
+	b.AddCity("hotel", "Hotel near airport", 62.0900, 129.7700)      // ~0.4 km away
+	b.AddCity("pokrovsk", "Pokrovsk Bus Station", 61.4840, 129.1480) // ~75 km away
+
+	b.AddTransferEdges(DefaultTransferConfig())
+
+	edges := make(map[string]*Edge)
+	for _, edge := range b.graph.GetNeighbors("airport") {
+		edges[edge.ToNodeID] = edge
+	}
+
+	if edge := edges["hotel"]; edge == nil || edge.TransportType != "walk" || edge.Price != 0 {
+		t.Fatalf("expected free walk edge to hotel, got %+v", edge)
+	}
+	if edge := edges["terminal"]; edge == nil || edge.TransportType != "taxi" || edge.Price <= 0 || !edge.Transfer {
+		t.Fatalf("expected priced taxi transfer to terminal, got %+v", edge)
+	}
+	if _, exists := edges["pokrovsk"]; exists {
+		t.Fatalf("expected no transfer beyond the taxi radius")
+	}
+
+	back := b.graph.GetNeighbors("hotel")
+	if len(back) != 2 {
+		t.Fatalf("expected transfers from the hotel to the airport and the terminal, got %d", len(back))
+	}
+}
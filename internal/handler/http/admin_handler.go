@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/lenalink/backend/internal/handler/http/dto"
+	"github.com/lenalink/backend/internal/service"
+)
+
+// AdminHandler handles operational endpoints for support staff
+type AdminHandler struct {
+	sagaService  *service.SagaService
+	errorHandler *ErrorHandler
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(sagaService *service.SagaService) *AdminHandler {
+	return &AdminHandler{
+		sagaService:  sagaService,
+		errorHandler: NewErrorHandler(),
+	}
+}
+
+// ListStuckSagas handles GET /api/v1/admin/sagas/stuck
+func (h *AdminHandler) ListStuckSagas(w http.ResponseWriter, r *http.Request) {
+	sagas, err := h.sagaService.FindStuck(r.Context())
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := dto.SagaListResponse{
+		Sagas: make([]dto.SagaResponse, len(sagas)),
+		Total: len(sagas),
+	}
+	for i := range sagas {
+		resp.Sagas[i] = ToSagaResponse(&sagas[i])
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
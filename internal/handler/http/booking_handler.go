@@ -13,17 +13,21 @@ import (
 
 // BookingHandler handles booking-related HTTP endpoints
 type BookingHandler struct {
-	bookingService *service.BookingService
-	errorHandler   *ErrorHandler
-	validator      *Validator
+	bookingService    *service.BookingService
+	disruptionService *service.DisruptionService
+	claimService      *service.InsuranceClaimService
+	errorHandler      *ErrorHandler
+	validator         *Validator
 }
 
 // NewBookingHandler creates a new booking handler
-func NewBookingHandler(bookingService *service.BookingService) *BookingHandler {
+func NewBookingHandler(bookingService *service.BookingService, disruptionService *service.DisruptionService, claimService *service.InsuranceClaimService) *BookingHandler {
 	return &BookingHandler{
-		bookingService: bookingService,
-		errorHandler:   NewErrorHandler(),
-		validator:      NewValidator(),
+		bookingService:    bookingService,
+		disruptionService: disruptionService,
+		claimService:      claimService,
+		errorHandler:      NewErrorHandler(),
+		validator:         NewValidator(),
 	}
 }
 
@@ -41,8 +45,8 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert passenger request to domain
-	passenger, err := ToDomainPassenger(&req.Passenger)
+	// Convert passenger requests to domain
+	passengers, err := ToDomainPassengers(req.AllPassengers())
 	if err != nil {
 		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_PASSENGER", "Invalid passenger data: "+err.Error())
 		return
@@ -65,7 +69,7 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	booking, err := h.bookingService.CreateBooking(
 		r.Context(),
 		req.RouteID,
-		passenger,
+		passengers,
 		req.IncludeInsurance,
 		paymentMethod,
 	)
@@ -122,7 +126,7 @@ func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cancel booking
-	if err := h.bookingService.CancelBooking(r.Context(), bookingID, req.Reason); err != nil {
+	if err := h.bookingService.CancelBooking(r.Context(), bookingID, domain.ActorCustomer, req.Reason); err != nil {
 		h.errorHandler.RespondWithDomainError(w, err)
 		return
 	}
@@ -142,6 +146,177 @@ func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
 }
 
+// CancelSegment handles POST /api/v1/bookings/{id}/segments/{segmentId}/cancel
+func (h *BookingHandler) CancelSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+	segmentID := vars["segmentId"]
+
+	if bookingID == "" || segmentID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BOOKING_ID", "Booking ID and segment ID are required")
+		return
+	}
+
+	var req dto.CancelBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// Validate cancellation request
+	if err := h.validator.ValidateCancelBookingRequest(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	booking, refund, err := h.bookingService.CancelSegment(r.Context(), bookingID, segmentID, domain.ActorCustomer, req.Reason)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := dto.CancelSegmentResponse{
+		Message: "Ticket cancelled successfully",
+		Refund:  ToSegmentRefundResponse(refund),
+		Booking: ToBookingResponse(booking),
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// ChangeBooking handles POST /api/v1/bookings/{id}/change
+func (h *BookingHandler) ChangeBooking(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+
+	if bookingID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BOOKING_ID", "Booking ID is required")
+		return
+	}
+
+	var req dto.ChangeBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// Validate change request
+	if err := h.validator.ValidateChangeBookingRequest(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	requests := make([]domain.ChangeRequest, len(req.Changes))
+	for i, change := range req.Changes {
+		requests[i] = domain.ChangeRequest{
+			BookedSegmentID: change.BookedSegmentID,
+			SegmentID:       change.SegmentID,
+		}
+	}
+
+	booking, change, err := h.bookingService.ChangeBooking(r.Context(), bookingID, requests, domain.ActorCustomer, req.Reason)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := dto.ChangeBookingResponse{
+		Message: "Booking changed successfully",
+		Change:  ToBookingChangeResponse(change),
+		Booking: ToBookingResponse(booking),
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetBookingChanges handles GET /api/v1/bookings/{id}/changes
+func (h *BookingHandler) GetBookingChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+
+	changes, err := h.bookingService.GetBookingChanges(r.Context(), bookingID)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := make([]dto.BookingChangeResponse, len(changes))
+	for i := range changes {
+		resp[i] = ToBookingChangeResponse(&changes[i])
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetDisruptions handles GET /api/v1/bookings/{id}/disruptions
+func (h *BookingHandler) GetDisruptions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+
+	disruptions, err := h.disruptionService.GetDisruptions(r.Context(), bookingID)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := make([]dto.DisruptionResponse, len(disruptions))
+	for i := range disruptions {
+		resp[i] = ToDisruptionResponse(&disruptions[i])
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// CreateInsuranceClaim handles POST /api/v1/bookings/{id}/insurance/claims
+func (h *BookingHandler) CreateInsuranceClaim(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+
+	if bookingID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BOOKING_ID", "Booking ID is required")
+		return
+	}
+
+	var req dto.InsuranceClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// Validate claim request
+	if err := h.validator.ValidateInsuranceClaimRequest(&req); err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	claim, err := h.claimService.ClaimMissedConnection(r.Context(), bookingID, req.BookedSegmentID)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusCreated, ToInsuranceClaimResponse(claim))
+}
+
+// GetInsuranceClaims handles GET /api/v1/bookings/{id}/insurance/claims
+func (h *BookingHandler) GetInsuranceClaims(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := vars["id"]
+
+	claims, err := h.claimService.GetClaims(r.Context(), bookingID)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	resp := make([]dto.InsuranceClaimResponse, len(claims))
+	for i := range claims {
+		resp[i] = ToInsuranceClaimResponse(&claims[i])
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
+}
+
 // ListBookings handles GET /api/v1/bookings (admin endpoint)
 func (h *BookingHandler) ListBookings(w http.ResponseWriter, r *http.Request) {
 	bookings, err := h.bookingService.ListBookings(r.Context())
@@ -157,7 +332,7 @@ func (h *BookingHandler) ListBookings(w http.ResponseWriter, r *http.Request) {
 			Status:         string(booking.Status),
 			RouteID:        booking.RouteID,
 			PassengerEmail: booking.Passenger.Email,
-			GrandTotal:     booking.GrandTotal,
+			GrandTotal:     booking.GrandTotal.Major(),
 			CreatedAt:      booking.CreatedAt,
 			ConfirmedAt:    booking.ConfirmedAt,
 		}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/internal/handler/http/dto"
+	"github.com/lenalink/backend/internal/routing"
 )
 
 // ToStopResponse converts domain.Stop to DTO
@@ -19,6 +20,34 @@ func ToStopResponse(stop domain.Stop) dto.StopResponse {
 	}
 }
 
+// ToStopSuggestResponse groups ranked stops by city, keeping the order of first appearance
+func ToStopSuggestResponse(query string, stops []domain.Stop) dto.StopSuggestResponse {
+	resp := dto.StopSuggestResponse{
+		Query:  query,
+		Cities: make([]dto.CitySuggestion, 0),
+	}
+
+	cityIndex := make(map[string]int)
+	for _, stop := range stops {
+		i, exists := cityIndex[stop.City]
+		if !exists {
+			i = len(resp.Cities)
+			cityIndex[stop.City] = i
+			resp.Cities = append(resp.Cities, dto.CitySuggestion{City: stop.City})
+		}
+
+		resp.Cities[i].Stops = append(resp.Cities[i].Stops, dto.StopSuggestion{
+			ID:        stop.ID,
+			Name:      stop.Name,
+			Type:      stop.Type,
+			Latitude:  stop.Latitude,
+			Longitude: stop.Longitude,
+		})
+	}
+
+	return resp
+}
+
 // ToSegmentResponse converts domain.Segment to DTO
 func ToSegmentResponse(seg *domain.Segment) dto.SegmentResponse {
 	duration := seg.ArrivalTime.Sub(seg.DepartureTime)
@@ -33,7 +62,7 @@ func ToSegmentResponse(seg *domain.Segment) dto.SegmentResponse {
 		DepartureTime: seg.DepartureTime,
 		ArrivalTime:   seg.ArrivalTime,
 		Duration:      durationStr,
-		Price:         seg.Price,
+		Price:         seg.Price.Major(),
 		Distance:      seg.Distance,
 		SeatCount:     seg.SeatCount,
 	}
@@ -60,18 +89,32 @@ func ToRouteResponse(route *domain.Route, routeType string) dto.RouteResponse {
 		ID:            route.ID,
 		Type:          routeType,
 		Segments:      segments,
-		TotalPrice:    route.TotalPrice,
+		TotalPrice:    route.TotalPrice.Major(),
 		TotalDistance: totalDistance,
 		TotalDuration: formatDuration(totalDuration),
 		ReliabilityScore: route.ReliabilityScore,
 	}
 }
 
+// ToRouteDiagnostics converts routing.PathError to DTO
+func ToRouteDiagnostics(pathErr *routing.PathError) *dto.RouteDiagnostics {
+	return &dto.RouteDiagnostics{
+		From:               pathErr.From,
+		To:                 pathErr.To,
+		FromExists:         pathErr.FromExists,
+		ToExists:           pathErr.ToExists,
+		NearestHub:         pathErr.NearestHub,
+		NearestHubName:     pathErr.NearestHubName,
+		NearestHubDistance: pathErr.NearestHubDistance,
+	}
+}
+
 // ToBookedSegmentResponse converts domain.BookedSegment to DTO
 func ToBookedSegmentResponse(booked *domain.BookedSegment) dto.BookedSegmentResponse {
 	return dto.BookedSegmentResponse{
 		ID:                 booked.ID,
 		SegmentID:          booked.SegmentID,
+		PassengerID:        booked.PassengerID,
 		Provider:           booked.Provider,
 		TransportType:      string(booked.TransportType),
 		From:               ToStopResponse(booked.From),
@@ -79,11 +122,162 @@ func ToBookedSegmentResponse(booked *domain.BookedSegment) dto.BookedSegmentResp
 		DepartureTime:      booked.DepartureTime,
 		ArrivalTime:        booked.ArrivalTime,
 		TicketNumber:       booked.TicketNumber,
-		Price:              booked.Price,
-		Commission:         booked.Commission,
-		TotalPrice:         booked.TotalPrice,
+		Price:              booked.Price.Major(),
+		Commission:         booked.Commission.Major(),
+		TotalPrice:         booked.TotalPrice.Major(),
 		BookingStatus:      string(booked.BookingStatus),
 		ProviderBookingRef: booked.ProviderBookingRef,
+		RefundAmount:       booked.RefundAmount.Major(),
+		RefundPending:      booked.RefundPending,
+		CancelledAt:        booked.CancelledAt,
+		ReplacedBy:         booked.ReplacedBy,
+	}
+}
+
+// ToFareRuleResponse converts the fare rule of a segment to DTO
+func ToFareRuleResponse(segmentID string, rule *domain.FareRule) dto.FareRuleResponse {
+	tiers := make([]dto.PenaltyTierResponse, len(rule.Tiers))
+	for i, tier := range rule.Tiers {
+		tiers[i] = dto.PenaltyTierResponse{
+			BeforeHours: tier.Before.Hours(),
+			Penalty:     tier.Penalty,
+			Fee:         tier.Fee.Major(),
+		}
+	}
+
+	return dto.FareRuleResponse{
+		SegmentID:    segmentID,
+		Provider:     rule.Provider,
+		Tariff:       rule.Tariff,
+		Refundable:   rule.Refundable,
+		PenaltyTiers: tiers,
+		Changeable:   rule.Changeable,
+		ChangeFee:    rule.ChangeFee.Major(),
+		Source:       rule.Source,
+	}
+}
+
+// ToSegmentRefundResponse converts domain.SegmentRefund to DTO
+func ToSegmentRefundResponse(refund *domain.SegmentRefund) dto.SegmentRefundResponse {
+	return dto.SegmentRefundResponse{
+		BookedSegmentID:    refund.BookedSegmentID,
+		Fare:               refund.Fare.Major(),
+		Penalty:            refund.Penalty.Major(),
+		CommissionRefunded: refund.CommissionRefunded.Major(),
+		CommissionRetained: refund.CommissionRetained.Major(),
+		Amount:             refund.Amount.Major(),
+	}
+}
+
+// ToBookingChangeResponse converts domain.BookingChange to DTO
+func ToBookingChangeResponse(change *domain.BookingChange) dto.BookingChangeResponse {
+	tickets := make([]dto.TicketChangeResponse, len(change.Tickets))
+	for i, ticket := range change.Tickets {
+		tickets[i] = dto.TicketChangeResponse{
+			OldBookedSegmentID: ticket.OldBookedSegmentID,
+			NewBookedSegmentID: ticket.NewBookedSegmentID,
+			FareDifference:     ticket.FareDifference.Major(),
+			ChangeFee:          ticket.ChangeFee.Major(),
+		}
+	}
+
+	return dto.BookingChangeResponse{
+		ID:             change.ID,
+		Version:        change.Version,
+		Tickets:        tickets,
+		FareDifference: change.FareDifference.Major(),
+		ChangeFee:      change.ChangeFee.Major(),
+		Amount:         change.Amount.Major(),
+		PaymentID:      change.PaymentID,
+		Actor:          change.Actor,
+		Reason:         change.Reason,
+		CreatedAt:      change.CreatedAt,
+	}
+}
+
+// ToDisruptionResponse converts domain.Disruption to DTO
+func ToDisruptionResponse(disruption *domain.Disruption) dto.DisruptionResponse {
+	alternatives := make([]dto.RouteResponse, len(disruption.Alternatives))
+	for i := range disruption.Alternatives {
+		alternatives[i] = ToRouteResponse(&disruption.Alternatives[i], "alternative")
+	}
+
+	resp := dto.DisruptionResponse{
+		ID:              disruption.ID,
+		PassengerID:     disruption.PassengerID,
+		Type:            string(disruption.Type),
+		SegmentID:       disruption.SegmentID,
+		BookedSegmentID: disruption.BookedSegmentID,
+		DelayMinutes:    int(disruption.Delay.Minutes()),
+		Replaces:        disruption.Replaces,
+		FromCity:        disruption.FromCity,
+		ToCity:          disruption.ToCity,
+		DepartAfter:     disruption.DepartAfter,
+		Alternatives:    alternatives,
+		Status:          string(disruption.Status),
+		ChangeID:        disruption.ChangeID,
+		DetectedAt:      disruption.DetectedAt,
+		NotifiedAt:      disruption.NotifiedAt,
+	}
+
+	resp.Connection = toDisruptedConnectionResponse(disruption.Connection)
+
+	return resp
+}
+
+// toDisruptedConnectionResponse converts a connection that could not be made to DTO
+func toDisruptedConnectionResponse(connection *domain.Connection) *dto.DisruptedConnectionResponse {
+	if connection == nil || connection.From == nil || connection.To == nil {
+		return nil
+	}
+
+	return &dto.DisruptedConnectionResponse{
+		FromSegmentID:        connection.From.ID,
+		ToSegmentID:          connection.To.ID,
+		GapMinutes:           int(connection.Gap.Minutes()),
+		MinConnectionMinutes: int(connection.MinConnectionTime.Minutes()),
+		IsValid:              connection.IsValid,
+	}
+}
+
+// ToInsurancePolicyResponse converts domain.InsurancePolicy to DTO
+func ToInsurancePolicyResponse(policy *domain.InsurancePolicy) *dto.InsurancePolicyResponse {
+	if policy == nil {
+		return nil
+	}
+
+	return &dto.InsurancePolicyResponse{
+		Number:        policy.Number,
+		Provider:      policy.Provider,
+		Premium:       policy.Premium.Major(),
+		CoverageLimit: policy.CoverageLimit.Major(),
+		PaidOut:       policy.PaidOut.Major(),
+		Remaining:     policy.Remaining().Major(),
+		Status:        string(policy.Status),
+		ValidFrom:     policy.ValidFrom,
+		ValidUntil:    policy.ValidUntil,
+		IssuedAt:      policy.IssuedAt,
+	}
+}
+
+// ToInsuranceClaimResponse converts domain.InsuranceClaim to DTO
+func ToInsuranceClaimResponse(claim *domain.InsuranceClaim) dto.InsuranceClaimResponse {
+	return dto.InsuranceClaimResponse{
+		ID:               claim.ID,
+		PolicyNumber:     claim.PolicyNumber,
+		Type:             string(claim.Type),
+		PassengerID:      claim.PassengerID,
+		BookedSegmentID:  claim.BookedSegmentID,
+		InboundTicketID:  claim.InboundTicketID,
+		ScheduledArrival: claim.ScheduledArrival,
+		ActualArrival:    claim.ActualArrival,
+		DelayMinutes:     int(claim.Delay.Minutes()),
+		Connection:       toDisruptedConnectionResponse(claim.Connection),
+		Status:           string(claim.Status),
+		Payout:           claim.Payout.Major(),
+		RejectionReason:  claim.RejectionReason,
+		ProviderClaimID:  claim.ProviderClaimID,
+		CreatedAt:        claim.CreatedAt,
 	}
 }
 
@@ -96,8 +290,8 @@ func ToPaymentResponse(payment *domain.Payment) *dto.PaymentResponse {
 	resp := &dto.PaymentResponse{
 		ID:                payment.ID,
 		OrderID:           payment.OrderID,
-		Amount:            payment.Amount,
-		Currency:          payment.Currency,
+		Amount:            payment.Amount.Major(),
+		Currency:          string(payment.Amount.Currency),
 		Method:            string(payment.Method),
 		Status:            string(payment.Status),
 		ProviderPaymentID: payment.ProviderPaymentID,
@@ -105,6 +299,7 @@ func ToPaymentResponse(payment *domain.Payment) *dto.PaymentResponse {
 		CreatedAt:         payment.CreatedAt,
 		CompletedAt:       payment.CompletedAt,
 		FailureReason:     payment.FailureReason,
+		RefundedAmount:    payment.RefundedAmount.Major(),
 	}
 
 	return resp
@@ -117,18 +312,32 @@ func ToBookingResponse(booking *domain.Booking) dto.BookingResponse {
 		segments[i] = ToBookedSegmentResponse(&seg)
 	}
 
+	passengers := make([]dto.PassengerResponse, len(booking.Passengers))
+	for i := range booking.Passengers {
+		passengers[i] = ToPassengerResponse(&booking.Passengers[i])
+	}
+
+	var charges []dto.PaymentResponse
+	for i := range booking.Charges {
+		charges = append(charges, *ToPaymentResponse(&booking.Charges[i]))
+	}
+
 	return dto.BookingResponse{
 		ID:               booking.ID,
 		RouteID:          booking.RouteID,
 		Status:           string(booking.Status),
+		Version:          booking.Version,
 		Passenger:        ToPassengerResponse(&booking.Passenger),
+		Passengers:       passengers,
 		Segments:         segments,
-		TotalPrice:       booking.TotalPrice,
-		TotalCommission:  booking.TotalCommission,
-		InsurancePremium: booking.InsurancePremium,
-		GrandTotal:       booking.GrandTotal,
+		TotalPrice:       booking.TotalPrice.Major(),
+		TotalCommission:  booking.TotalCommission.Major(),
+		InsurancePremium: booking.InsurancePremium.Major(),
+		GrandTotal:       booking.GrandTotal.Major(),
 		IncludeInsurance: booking.IncludeInsurance,
 		Payment:          ToPaymentResponse(booking.Payment),
+		Charges:          charges,
+		Policy:           ToInsurancePolicyResponse(booking.Policy),
 		CreatedAt:        booking.CreatedAt,
 		ConfirmedAt:      booking.ConfirmedAt,
 		CancelledAt:      booking.CancelledAt,
@@ -139,6 +348,8 @@ func ToBookingResponse(booking *domain.Booking) dto.BookingResponse {
 // ToPassengerResponse converts domain.Passenger to DTO
 func ToPassengerResponse(p *domain.Passenger) dto.PassengerResponse {
 	return dto.PassengerResponse{
+		ID:         p.ID,
+		Type:       string(p.Type),
 		FirstName:  p.FirstName,
 		LastName:   p.LastName,
 		MiddleName: p.MiddleName,
@@ -165,6 +376,47 @@ func ToDomainPassenger(req *dto.PassengerRequest) (domain.Passenger, error) {
 	}, nil
 }
 
+// ToDomainPassengers converts a list of passenger DTOs, keeping their order
+func ToDomainPassengers(reqs []dto.PassengerRequest) ([]domain.Passenger, error) {
+	passengers := make([]domain.Passenger, 0, len(reqs))
+	for i := range reqs {
+		passenger, err := ToDomainPassenger(&reqs[i])
+		if err != nil {
+			return nil, fmt.Errorf("passenger %d: %w", i+1, err)
+		}
+		passengers = append(passengers, passenger)
+	}
+	return passengers, nil
+}
+
+// ToSagaResponse converts domain.BookingSaga to DTO
+func ToSagaResponse(saga *domain.BookingSaga) dto.SagaResponse {
+	steps := make([]dto.SagaStepResponse, len(saga.Steps))
+	for i, step := range saga.Steps {
+		steps[i] = dto.SagaStepResponse{
+			Sequence:      step.Sequence,
+			Type:          string(step.Type),
+			Status:        string(step.Status),
+			SegmentID:     step.SegmentID,
+			PassengerID:   step.PassengerID,
+			Reference:     step.Reference,
+			Error:         step.Error,
+			Attempts:      step.Attempts,
+			NextAttemptAt: step.NextAttemptAt,
+		}
+	}
+
+	return dto.SagaResponse{
+		ID:        saga.ID,
+		BookingID: saga.BookingID,
+		Status:    string(saga.Status),
+		LastError: saga.LastError,
+		Steps:     steps,
+		CreatedAt: saga.CreatedAt,
+		UpdatedAt: saga.UpdatedAt,
+	}
+}
+
 // formatDuration formats duration as "Xh Ym" format
 func formatDuration(d time.Duration) string {
 	hours := int(d.Hours())
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// SagaListResponse represents a list of booking sagas
+type SagaListResponse struct {
+	Sagas []SagaResponse `json:"sagas"`
+	Total int            `json:"total"`
+}
+
+// SagaResponse represents a booking saga in API response
+type SagaResponse struct {
+	ID        string             `json:"id"`
+	BookingID string             `json:"booking_id"`
+	Status    string             `json:"status"` // running, compensating, compensated, completed, failed
+	LastError string             `json:"last_error,omitempty"`
+	Steps     []SagaStepResponse `json:"steps"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// SagaStepResponse represents a recorded saga step
+type SagaStepResponse struct {
+	Sequence      int        `json:"sequence"`
+	Type          string     `json:"type"`   // book_segment, process_payment
+	Status        string     `json:"status"` // pending, done, failed, compensated, compensation_failed
+	SegmentID     string     `json:"segment_id,omitempty"`
+	PassengerID   string     `json:"passenger_id,omitempty"`
+	Reference     string     `json:"reference,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
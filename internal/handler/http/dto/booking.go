@@ -4,10 +4,19 @@ import "time"
 
 // CreateBookingRequest represents a request to create a booking
 type CreateBookingRequest struct {
-	RouteID          string            `json:"route_id" validate:"required"`
-	Passenger        PassengerRequest  `json:"passenger" validate:"required"`
-	IncludeInsurance bool              `json:"include_insurance"`
-	PaymentMethod    string            `json:"payment_method" validate:"required,oneof=card yookassa cloudpay sberpay"`
+	RouteID          string             `json:"route_id" validate:"required"`
+	Passengers       []PassengerRequest `json:"passengers" validate:"omitempty,min=1,max=10"` // Lead passenger first
+	Passenger        *PassengerRequest  `json:"passenger,omitempty"`                          // Single passenger (use passengers instead)
+	IncludeInsurance bool               `json:"include_insurance"`
+	PaymentMethod    string             `json:"payment_method" validate:"required,oneof=card yookassa cloudpay sberpay"`
+}
+
+// AllPassengers returns the passenger list, falling back to the single passenger field
+func (r *CreateBookingRequest) AllPassengers() []PassengerRequest {
+	if len(r.Passengers) == 0 && r.Passenger != nil {
+		return []PassengerRequest{*r.Passenger}
+	}
+	return r.Passengers
 }
 
 // PassengerRequest represents passenger information
@@ -15,18 +24,20 @@ type PassengerRequest struct {
 	FirstName      string `json:"first_name" validate:"required"`
 	LastName       string `json:"last_name" validate:"required"`
 	MiddleName     string `json:"middle_name"`
-	DateOfBirth    string `json:"date_of_birth" validate:"required"` // YYYY-MM-DD
-	PassportNumber string `json:"passport_number" validate:"required"`
-	Email          string `json:"email" validate:"required,email"`
-	Phone          string `json:"phone" validate:"required"`
+	DateOfBirth    string `json:"date_of_birth" validate:"required"`   // YYYY-MM-DD
+	PassportNumber string `json:"passport_number" validate:"required"` // Birth certificate number for children
+	Email          string `json:"email" validate:"omitempty,email"`    // Required for the lead passenger
+	Phone          string `json:"phone"`                               // Required for the lead passenger
 }
 
 // BookingResponse represents a booking in API response
 type BookingResponse struct {
 	ID               string                  `json:"id"`
 	RouteID          string                  `json:"route_id"`
-	Status           string                  `json:"status"` // pending, confirmed, failed, cancelled, refunded
-	Passenger        PassengerResponse       `json:"passenger"`
+	Status           string                  `json:"status"` // pending, confirmed, partially_cancelled, failed, cancelled, refunded
+	Version          int                     `json:"version"` // Incremented by each booking change
+	Passenger        PassengerResponse       `json:"passenger"`  // Lead passenger
+	Passengers       []PassengerResponse     `json:"passengers"` // Everyone travelling
 	Segments         []BookedSegmentResponse `json:"segments"`
 	TotalPrice       float64                 `json:"total_price"`
 	TotalCommission  float64                 `json:"total_commission"`
@@ -34,6 +45,8 @@ type BookingResponse struct {
 	GrandTotal       float64                 `json:"grand_total"`
 	IncludeInsurance bool                    `json:"include_insurance"`
 	Payment          *PaymentResponse        `json:"payment,omitempty"`
+	Charges          []PaymentResponse       `json:"charges,omitempty"` // Taken after the booking was paid, e.g. by a booking change
+	Policy           *InsurancePolicyResponse `json:"insurance_policy,omitempty"` // Issued once confirmed with insurance
 	CreatedAt        time.Time               `json:"created_at"`
 	ConfirmedAt      *time.Time              `json:"confirmed_at,omitempty"`
 	CancelledAt      *time.Time              `json:"cancelled_at,omitempty"`
@@ -42,17 +55,20 @@ type BookingResponse struct {
 
 // PassengerResponse represents passenger information in response
 type PassengerResponse struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"` // adult, child, infant
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
 	MiddleName string `json:"middle_name,omitempty"`
-	Email      string `json:"email"`
-	Phone      string `json:"phone"`
+	Email      string `json:"email,omitempty"`
+	Phone      string `json:"phone,omitempty"`
 }
 
 // BookedSegmentResponse represents a booked segment
 type BookedSegmentResponse struct {
 	ID                 string       `json:"id"`
 	SegmentID          string       `json:"segment_id"`
+	PassengerID        string       `json:"passenger_id"`
 	Provider           string       `json:"provider"`
 	TransportType      string       `json:"transport_type"`
 	From               StopResponse `json:"from"`
@@ -65,6 +81,10 @@ type BookedSegmentResponse struct {
 	TotalPrice         float64      `json:"total_price"`
 	BookingStatus      string       `json:"booking_status"` // confirmed, failed, cancelled
 	ProviderBookingRef string       `json:"provider_booking_ref,omitempty"`
+	RefundAmount       float64      `json:"refund_amount,omitempty"` // Returned when the ticket was cancelled
+	RefundPending      bool         `json:"refund_pending,omitempty"` // The refund failed at the gateway and is retried
+	CancelledAt        *time.Time   `json:"cancelled_at,omitempty"`
+	ReplacedBy         string       `json:"replaced_by,omitempty"` // Ticket that replaced this one in a booking change
 }
 
 // PaymentResponse represents payment information
@@ -80,6 +100,7 @@ type PaymentResponse struct {
 	CreatedAt         time.Time  `json:"created_at"`
 	CompletedAt       *time.Time `json:"completed_at,omitempty"`
 	FailureReason     string     `json:"failure_reason,omitempty"`
+	RefundedAmount    float64    `json:"refunded_amount,omitempty"`
 }
 
 // CancelBookingRequest represents a request to cancel a booking
@@ -87,6 +108,131 @@ type CancelBookingRequest struct {
 	Reason string `json:"reason" validate:"required"`
 }
 
+// SegmentRefundResponse represents the refund for one cancelled ticket
+type SegmentRefundResponse struct {
+	BookedSegmentID    string  `json:"booked_segment_id"`
+	Fare               float64 `json:"fare"`
+	Penalty            float64 `json:"penalty"`             // Kept by the carrier
+	CommissionRefunded float64 `json:"commission_refunded"` // Our markup returned
+	CommissionRetained float64 `json:"commission_retained"` // Our markup kept
+	Amount             float64 `json:"amount"`              // Returned to the customer
+}
+
+// CancelSegmentResponse represents the result of cancelling one ticket
+type CancelSegmentResponse struct {
+	Message string                `json:"message"`
+	Refund  SegmentRefundResponse `json:"refund"`
+	Booking BookingResponse       `json:"booking"`
+}
+
+// ChangeBookingRequest represents a request to move tickets to other segments of the same legs
+type ChangeBookingRequest struct {
+	Changes []TicketChangeRequest `json:"changes" validate:"required,min=1"`
+	Reason  string                `json:"reason" validate:"required"`
+}
+
+// TicketChangeRequest moves one ticket to another segment
+type TicketChangeRequest struct {
+	BookedSegmentID string `json:"booked_segment_id" validate:"required"`
+	SegmentID       string `json:"segment_id" validate:"required"` // Replacement segment
+}
+
+// TicketChangeResponse represents one swapped ticket
+type TicketChangeResponse struct {
+	OldBookedSegmentID string  `json:"old_booked_segment_id"`
+	NewBookedSegmentID string  `json:"new_booked_segment_id"`
+	FareDifference     float64 `json:"fare_difference"` // New ticket total minus the old one
+	ChangeFee          float64 `json:"change_fee"`
+}
+
+// BookingChangeResponse represents a recorded booking change
+type BookingChangeResponse struct {
+	ID             string                 `json:"id"`
+	Version        int                    `json:"version"` // Booking version the change created
+	Tickets        []TicketChangeResponse `json:"tickets"`
+	FareDifference float64                `json:"fare_difference"`
+	ChangeFee      float64                `json:"change_fee"`
+	Amount         float64                `json:"amount"` // Charged when positive, refunded when negative
+	PaymentID      string                 `json:"payment_id,omitempty"`
+	Actor          string                 `json:"actor"`
+	Reason         string                 `json:"reason,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// ChangeBookingResponse represents the result of a booking change
+type ChangeBookingResponse struct {
+	Message string                `json:"message"`
+	Change  BookingChangeResponse `json:"change"`
+	Booking BookingResponse       `json:"booking"`
+}
+
+// DisruptedConnectionResponse represents a connection that can no longer be made
+type DisruptedConnectionResponse struct {
+	FromSegmentID        string `json:"from_segment_id"`
+	ToSegmentID          string `json:"to_segment_id"`
+	GapMinutes           int    `json:"gap_minutes"` // Negative when the next segment leaves before the arrival
+	MinConnectionMinutes int    `json:"min_connection_minutes"`
+	IsValid              bool   `json:"is_valid"`
+}
+
+// DisruptionResponse represents a passenger's journey broken by a cancelled or delayed segment
+type DisruptionResponse struct {
+	ID              string                       `json:"id"`
+	PassengerID     string                       `json:"passenger_id"`
+	Type            string                       `json:"type"` // cancelled, delayed
+	SegmentID       string                       `json:"segment_id"`
+	BookedSegmentID string                       `json:"booked_segment_id"`
+	DelayMinutes    int                          `json:"delay_minutes,omitempty"`
+	Connection      *DisruptedConnectionResponse `json:"connection,omitempty"`
+	Replaces        []string                     `json:"replaces"` // Tickets the passenger can no longer use
+	FromCity        string                       `json:"from_city"`
+	ToCity          string                       `json:"to_city"`
+	DepartAfter     time.Time                    `json:"depart_after"`
+	Alternatives    []RouteResponse              `json:"alternatives"`
+	Status          string                       `json:"status"` // proposed, rebooked, unresolved
+	ChangeID        string                       `json:"change_id,omitempty"`
+	DetectedAt      time.Time                    `json:"detected_at"`
+	NotifiedAt      *time.Time                   `json:"notified_at,omitempty"`
+}
+
+// InsurancePolicyResponse represents the insurance policy of a booking
+type InsurancePolicyResponse struct {
+	Number        string    `json:"number"`
+	Provider      string    `json:"provider"`
+	Premium       float64   `json:"premium"`
+	CoverageLimit float64   `json:"coverage_limit"` // Most that is paid out over all claims
+	PaidOut       float64   `json:"paid_out"`
+	Remaining     float64   `json:"remaining"`
+	Status        string    `json:"status"` // active, cancelled
+	ValidFrom     time.Time `json:"valid_from"`
+	ValidUntil    time.Time `json:"valid_until"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// InsuranceClaimRequest represents a missed-connection claim for a ticket
+type InsuranceClaimRequest struct {
+	BookedSegmentID string `json:"booked_segment_id" validate:"required"` // The ticket that was missed
+}
+
+// InsuranceClaimResponse represents a claim under a booking's insurance policy
+type InsuranceClaimResponse struct {
+	ID               string                       `json:"id"`
+	PolicyNumber     string                       `json:"policy_number"`
+	Type             string                       `json:"type"` // missed_connection
+	PassengerID      string                       `json:"passenger_id"`
+	BookedSegmentID  string                       `json:"booked_segment_id"` // The ticket that was missed
+	InboundTicketID  string                       `json:"inbound_ticket_id"` // The ticket the passenger arrived on
+	ScheduledArrival time.Time                    `json:"scheduled_arrival"`
+	ActualArrival    time.Time                    `json:"actual_arrival"`
+	DelayMinutes     int                          `json:"delay_minutes"`
+	Connection       *DisruptedConnectionResponse `json:"connection,omitempty"` // The connection as it turned out
+	Status           string                       `json:"status"`               // paid, rejected
+	Payout           float64                      `json:"payout"`               // Refunded to the customer's payment
+	RejectionReason  string                       `json:"rejection_reason,omitempty"`
+	ProviderClaimID  string                       `json:"provider_claim_id,omitempty"`
+	CreatedAt        time.Time                    `json:"created_at"`
+}
+
 // BookingListResponse represents a list of bookings
 type BookingListResponse struct {
 	Bookings []BookingSummaryResponse `json:"bookings"`
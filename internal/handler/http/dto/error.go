@@ -11,6 +11,19 @@ type ErrorDetail struct {
 	Message   string `json:"message"`
 	Details   string `json:"details,omitempty"`
 	RequestID string `json:"request_id,omitempty"`
+
+	Diagnostics *RouteDiagnostics `json:"diagnostics,omitempty"`
+}
+
+// RouteDiagnostics explains why no route was found
+type RouteDiagnostics struct {
+	From               string  `json:"from"`
+	To                 string  `json:"to"`
+	FromExists         bool    `json:"from_exists"`
+	ToExists           bool    `json:"to_exists"`
+	NearestHub         string  `json:"nearest_hub,omitempty"`
+	NearestHubName     string  `json:"nearest_hub_name,omitempty"`
+	NearestHubDistance float64 `json:"nearest_hub_distance_km,omitempty"`
 }
 
 // SuccessResponse represents a successful API response
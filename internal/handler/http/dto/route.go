@@ -8,12 +8,15 @@ type SearchRouteRequest struct {
 	To            string `json:"to" validate:"required"`
 	DepartureDate string `json:"departure_date" validate:"required"` // YYYY-MM-DD format
 	Passengers    int    `json:"passengers" validate:"omitempty,min=1,max=10"`
+	Mode          string `json:"mode,omitempty" validate:"omitempty,oneof=auto saved live"`
+	Limit         int    `json:"limit,omitempty" validate:"omitempty,min=1,max=20"` // Number of alternatives
+	Weights       map[string]float64 `json:"weights,omitempty"` // e.g. {"price":0.6,"time":0.4}
 }
 
 // RouteResponse represents a route in API response
 type RouteResponse struct {
 	ID               string            `json:"id"`
-	Type             string            `json:"type"` // optimal, fastest, cheapest
+	Type             string            `json:"type"` // optimal, fastest, cheapest, alternative
 	Segments         []SegmentResponse `json:"segments"`
 	TotalPrice       float64           `json:"total_price"`
 	TotalDistance    int               `json:"total_distance"`
@@ -48,6 +51,7 @@ type StopResponse struct {
 // RouteSearchResponse represents search results
 type RouteSearchResponse struct {
 	Routes         []RouteResponse    `json:"routes"`
+	Alternatives   []RouteResponse    `json:"alternatives,omitempty"`
 	SearchCriteria SearchRouteRequest `json:"search_criteria"`
 }
 
@@ -58,6 +62,26 @@ type RouteDetailsResponse struct {
 	InsuranceAvailable   bool                    `json:"insurance_available"`
 	InsurancePremium     float64                 `json:"insurance_premium,omitempty"`
 	InsuranceBreakdown   *InsuranceBreakdown     `json:"insurance_breakdown,omitempty"`
+	FareRules            []FareRuleResponse      `json:"fare_rules,omitempty"` // One per segment
+}
+
+// FareRuleResponse shows refund and change conditions of a segment
+type FareRuleResponse struct {
+	SegmentID    string                `json:"segment_id"`
+	Provider     string                `json:"provider,omitempty"`
+	Tariff       string                `json:"tariff,omitempty"`
+	Refundable   bool                  `json:"refundable"`
+	PenaltyTiers []PenaltyTierResponse `json:"penalty_tiers"` // Earliest first
+	Changeable   bool                  `json:"changeable"`
+	ChangeFee    float64               `json:"change_fee"`
+	Source       string                `json:"source"` // default, config, gars
+}
+
+// PenaltyTierResponse shows the cancellation penalty from some time before departure
+type PenaltyTierResponse struct {
+	BeforeHours float64 `json:"before_hours"` // Negative = after departure
+	Penalty     float64 `json:"penalty"`      // Share of the fare, e.g. 0.25
+	Fee         float64 `json:"fee"`          // Fixed amount on top
 }
 
 // CommissionBreakdown shows pricing breakdown with commission
@@ -87,3 +111,24 @@ type InsuranceBreakdown struct {
 	MultiSegmentSurcharge      float64 `json:"multi_segment_surcharge,omitempty"`
 	Total                      float64 `json:"total"`
 }
+
+// StopSuggestResponse represents autocomplete results grouped by city
+type StopSuggestResponse struct {
+	Query  string           `json:"query"`
+	Cities []CitySuggestion `json:"cities"`
+}
+
+// CitySuggestion represents a city with its matching stops
+type CitySuggestion struct {
+	City  string           `json:"city"`
+	Stops []StopSuggestion `json:"stops"`
+}
+
+// StopSuggestion represents a single stop in autocomplete results
+type StopSuggestion struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"` // airport, port, station, terminal
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
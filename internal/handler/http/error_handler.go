@@ -2,10 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/internal/handler/http/dto"
+	"github.com/lenalink/backend/internal/routing"
 )
 
 // ErrorHandler provides error response helpers
@@ -18,14 +21,19 @@ func NewErrorHandler() *ErrorHandler {
 
 // RespondWithError sends a standardized error response
 func (eh *ErrorHandler) RespondWithError(w http.ResponseWriter, statusCode int, code, message string) {
+	eh.RespondWithErrorDetail(w, statusCode, dto.ErrorDetail{
+		Code:    code,
+		Message: message,
+	})
+}
+
+// RespondWithErrorDetail sends an error response with additional details
+func (eh *ErrorHandler) RespondWithErrorDetail(w http.ResponseWriter, statusCode int, detail dto.ErrorDetail) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	resp := dto.ErrorResponse{
-		Error: dto.ErrorDetail{
-			Code:    code,
-			Message: message,
-		},
+		Error: detail,
 	}
 
 	json.NewEncoder(w).Encode(resp)
@@ -46,13 +54,25 @@ func (eh *ErrorHandler) RespondWithJSON(w http.ResponseWriter, statusCode int, d
 
 // MapDomainErrorToHTTP maps domain errors to HTTP status codes
 func (eh *ErrorHandler) MapDomainErrorToHTTP(err error) (int, string, string) {
-	if domainErr, ok := err.(domain.DomainError); ok {
+	if errors.Is(err, routing.ErrNoPath) || errors.Is(err, routing.ErrUnknownNode) {
+		return http.StatusNotFound, "NO_ROUTE_FOUND", "No route found between the requested cities"
+	}
+
+	var transitionErr *domain.TransitionError
+	if errors.As(err, &transitionErr) {
+		return http.StatusConflict, "INVALID_STATUS_TRANSITION", fmt.Sprintf("Booking cannot move from %s to %s", transitionErr.From, transitionErr.To)
+	}
+
+	// Services may wrap domain errors with context
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
 		switch domainErr.Code {
 		case "VALIDATION_FAILED", "INVALID_ROUTE", "INVALID_BOOKING", "INVALID_SEGMENT", "INVALID_CONNECTION":
 			return http.StatusBadRequest, domainErr.Code, domainErr.Message
 		case "ROUTE_NOT_FOUND", "BOOKING_NOT_FOUND", "SEGMENT_NOT_FOUND":
 			return http.StatusNotFound, domainErr.Code, domainErr.Message
-		case "BOOKING_FAILED", "SEARCH_FAILED", "TRANSACTION_FAILED":
+		case "BOOKING_FAILED", "SEARCH_FAILED", "TRANSACTION_FAILED", "SEATS_UNAVAILABLE", "SEGMENT_NOT_CANCELLABLE",
+			"CHANGE_NOT_ALLOWED", "CHARGE_NOT_SUPPORTED", "PAYMENT_FAILED", "CLAIM_NOT_ALLOWED":
 			return http.StatusConflict, domainErr.Code, domainErr.Message
 		case "DATABASE_ERROR":
 			return http.StatusInternalServerError, domainErr.Code, domainErr.Message
@@ -68,5 +88,17 @@ func (eh *ErrorHandler) MapDomainErrorToHTTP(err error) (int, string, string) {
 // RespondWithDomainError handles domain errors and sends appropriate HTTP response
 func (eh *ErrorHandler) RespondWithDomainError(w http.ResponseWriter, err error) {
 	statusCode, code, message := eh.MapDomainErrorToHTTP(err)
-	eh.RespondWithError(w, statusCode, code, message)
+
+	detail := dto.ErrorDetail{
+		Code:    code,
+		Message: message,
+	}
+
+	// Explain why routing failed
+	var pathErr *routing.PathError
+	if errors.As(err, &pathErr) {
+		detail.Diagnostics = ToRouteDiagnostics(pathErr)
+	}
+
+	eh.RespondWithErrorDetail(w, statusCode, detail)
 }
@@ -15,16 +15,18 @@ import (
 // RouteHandler handles route-related HTTP endpoints
 type RouteHandler struct {
 	routeService     *service.RouteService
+	fareRuleService  *service.FareRuleService
 	errorHandler     *ErrorHandler
 	validator        *Validator
 }
 
 // NewRouteHandler creates a new route handler
-func NewRouteHandler(routeService *service.RouteService) *RouteHandler {
+func NewRouteHandler(routeService *service.RouteService, fareRuleService *service.FareRuleService) *RouteHandler {
 	return &RouteHandler{
-		routeService: routeService,
-		errorHandler: NewErrorHandler(),
-		validator:    NewValidator(),
+		routeService:    routeService,
+		fareRuleService: fareRuleService,
+		errorHandler:    NewErrorHandler(),
+		validator:       NewValidator(),
 	}
 }
 
@@ -57,6 +59,16 @@ func (h *RouteHandler) SearchRoutes(w http.ResponseWriter, r *http.Request) {
 		PassengerCount:  req.Passengers,
 		MaxConnections:  3,
 		MaxTransferTime: 1440, // 24 hours
+		Mode:            domain.SearchMode(req.Mode),
+		Alternatives:    req.Limit,
+	}
+
+	if len(req.Weights) > 0 {
+		criteria.Weights = &domain.CostWeights{
+			Price:     req.Weights["price"],
+			Time:      req.Weights["time"],
+			Transfers: req.Weights["transfers"],
+		}
 	}
 
 	// Search routes
@@ -78,8 +90,14 @@ func (h *RouteHandler) SearchRoutes(w http.ResponseWriter, r *http.Request) {
 		routes = append(routes, ToRouteResponse(result.CheapestRoute, "cheapest"))
 	}
 
+	alternatives := make([]dto.RouteResponse, 0, len(result.Alternatives))
+	for i := range result.Alternatives {
+		alternatives = append(alternatives, ToRouteResponse(&result.Alternatives[i], "alternative"))
+	}
+
 	searchResp := dto.RouteSearchResponse{
 		Routes:         routes,
+		Alternatives:   alternatives,
 		SearchCriteria: req,
 	}
 
@@ -103,10 +121,22 @@ func (h *RouteHandler) GetRouteByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refund and change conditions of every segment
+	rules, err := h.fareRuleService.RouteRules(r.Context(), route)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+	fareRules := make([]dto.FareRuleResponse, len(rules))
+	for i := range rules {
+		fareRules[i] = ToFareRuleResponse(route.Segments[i].ID, &rules[i])
+	}
+
 	resp := dto.RouteDetailsResponse{
 		Route:              ToRouteResponse(route, "details"),
 		InsuranceAvailable: true,
-		InsurancePremium:   route.InsurancePremium,
+		InsurancePremium:   route.InsurancePremium.Major(),
+		FareRules:          fareRules,
 	}
 
 	h.errorHandler.RespondWithJSON(w, http.StatusOK, resp)
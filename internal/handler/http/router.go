@@ -14,23 +14,33 @@ type Router struct {
 	*mux.Router
 	healthHandler  *HealthHandler
 	routeHandler   *RouteHandler
+	stopHandler    *StopHandler
 	bookingHandler *BookingHandler
 	webhookHandler *WebhookHandler
+	adminHandler   *AdminHandler
 }
 
 // NewRouter creates and configures the HTTP router
 func NewRouter(
 	routeService *service.RouteService,
+	stopService *service.StopService,
 	bookingService *service.BookingService,
 	paymentService *service.PaymentService,
+	sagaService *service.SagaService,
+	fareRuleService *service.FareRuleService,
+	disruptionService *service.DisruptionService,
+	claimService *service.InsuranceClaimService,
+	webhookService *service.WebhookService,
 ) *Router {
 	r := mux.NewRouter()
 
 	// Create handlers
 	healthHandler := NewHealthHandler()
-	routeHandler := NewRouteHandler(routeService)
-	bookingHandler := NewBookingHandler(bookingService)
-	webhookHandler := NewWebhookHandler(bookingService, paymentService)
+	routeHandler := NewRouteHandler(routeService, fareRuleService)
+	stopHandler := NewStopHandler(stopService)
+	bookingHandler := NewBookingHandler(bookingService, disruptionService, claimService)
+	webhookHandler := NewWebhookHandler(paymentService, webhookService)
+	adminHandler := NewAdminHandler(sagaService)
 
 	// Global middleware (applied to all routes)
 	r.Use(middleware.Recovery)
@@ -49,14 +59,28 @@ func NewRouter(
 	api.HandleFunc("/routes/search", routeHandler.SearchRoutes).Methods("POST")
 	api.HandleFunc("/routes/{id}", routeHandler.GetRouteByID).Methods("GET")
 
+	// Stop endpoints
+	api.HandleFunc("/stops/suggest", stopHandler.Suggest).Methods("GET")
+
 	// Booking endpoints
 	api.HandleFunc("/bookings", bookingHandler.CreateBooking).Methods("POST")
 	api.HandleFunc("/bookings", bookingHandler.ListBookings).Methods("GET")
 	api.HandleFunc("/bookings/{id}", bookingHandler.GetBooking).Methods("GET")
 	api.HandleFunc("/bookings/{id}/cancel", bookingHandler.CancelBooking).Methods("POST")
+	api.HandleFunc("/bookings/{id}/segments/{segmentId}/cancel", bookingHandler.CancelSegment).Methods("POST")
+	api.HandleFunc("/bookings/{id}/change", bookingHandler.ChangeBooking).Methods("POST")
+	api.HandleFunc("/bookings/{id}/changes", bookingHandler.GetBookingChanges).Methods("GET")
+	api.HandleFunc("/bookings/{id}/disruptions", bookingHandler.GetDisruptions).Methods("GET")
+	api.HandleFunc("/bookings/{id}/insurance/claims", bookingHandler.CreateInsuranceClaim).Methods("POST")
+	api.HandleFunc("/bookings/{id}/insurance/claims", bookingHandler.GetInsuranceClaims).Methods("GET")
+
+	// Admin endpoints
+	api.HandleFunc("/admin/sagas/stuck", adminHandler.ListStuckSagas).Methods("GET")
 
-	// Webhook endpoints (no auth required for payment provider callbacks)
+	// Webhook endpoints (no auth; callbacks are checked against the payment provider)
 	api.HandleFunc("/webhooks/yookassa", webhookHandler.HandleYooKassaWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/cloudpayments/{type}", webhookHandler.HandleCloudPaymentsWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/sberpay", webhookHandler.HandleSberPayWebhook).Methods("GET", "POST")
 
 	// 404 handler
 	r.NotFoundHandler = r.NewRoute().HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -68,7 +92,9 @@ func NewRouter(
 		Router:         r,
 		healthHandler:  healthHandler,
 		routeHandler:   routeHandler,
+		stopHandler:    stopHandler,
 		bookingHandler: bookingHandler,
 		webhookHandler: webhookHandler,
+		adminHandler:   adminHandler,
 	}
 }
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lenalink/backend/internal/service"
+)
+
+// StopHandler handles stop-related HTTP endpoints
+type StopHandler struct {
+	stopService  *service.StopService
+	errorHandler *ErrorHandler
+}
+
+// NewStopHandler creates a new stop handler
+func NewStopHandler(stopService *service.StopService) *StopHandler {
+	return &StopHandler{
+		stopService:  stopService,
+		errorHandler: NewErrorHandler(),
+	}
+}
+
+// Suggest handles GET /api/v1/stops/suggest?q=
+func (h *StopHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(query)) < 2 {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "VALIDATION_ERROR", "'q' must be at least 2 characters")
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > service.MaxSuggestLimit {
+			h.errorHandler.RespondWithError(w, http.StatusBadRequest, "VALIDATION_ERROR", "'limit' must be between 1 and 50")
+			return
+		}
+		limit = parsed
+	}
+
+	stops, err := h.stopService.Suggest(r.Context(), query, limit)
+	if err != nil {
+		h.errorHandler.RespondWithDomainError(w, err)
+		return
+	}
+
+	h.errorHandler.RespondWithJSON(w, http.StatusOK, ToStopSuggestResponse(query, stops))
+}
@@ -51,6 +51,52 @@ func (v *Validator) ValidateSearchRouteRequest(req *dto.SearchRouteRequest) erro
 		return errors.New("'passengers' cannot exceed 10")
 	}
 
+	validModes := map[string]bool{
+		"":      true,
+		"auto":  true,
+		"saved": true,
+		"live":  true,
+	}
+
+	if !validModes[req.Mode] {
+		return errors.New("'mode' must be one of: auto, saved, live")
+	}
+
+	if req.Limit < 0 || req.Limit > 20 {
+		return errors.New("'limit' must be between 1 and 20")
+	}
+
+	if err := v.validateCostWeights(req.Weights); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateCostWeights validates route cost weights
+func (v *Validator) validateCostWeights(weights map[string]float64) error {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	total := 0.0
+	for key, weight := range weights {
+		switch key {
+		case "price", "time", "transfers":
+		default:
+			return fmt.Errorf("'weights' contains unknown criterion %q (use price, time, transfers)", key)
+		}
+
+		if weight < 0 {
+			return fmt.Errorf("'weights.%s' cannot be negative", key)
+		}
+		total += weight
+	}
+
+	if total == 0 {
+		return errors.New("'weights' must contain at least one positive weight")
+	}
+
 	return nil
 }
 
@@ -60,9 +106,18 @@ func (v *Validator) ValidateCreateBookingRequest(req *dto.CreateBookingRequest)
 		return errors.New("'route_id' field is required")
 	}
 
-	// Validate passenger
-	if err := v.validatePassengerRequest(&req.Passenger); err != nil {
-		return fmt.Errorf("passenger validation failed: %w", err)
+	// Validate passengers (the first one is the lead passenger)
+	passengers := req.AllPassengers()
+	if len(passengers) == 0 {
+		return errors.New("'passengers' must contain at least one passenger")
+	}
+	if len(passengers) > 10 {
+		return errors.New("'passengers' cannot contain more than 10 passengers")
+	}
+	for i := range passengers {
+		if err := v.validatePassengerRequest(&passengers[i], i == 0); err != nil {
+			return fmt.Errorf("passenger %d validation failed: %w", i+1, err)
+		}
 	}
 
 	// Validate payment method
@@ -81,7 +136,9 @@ func (v *Validator) ValidateCreateBookingRequest(req *dto.CreateBookingRequest)
 }
 
 // validatePassengerRequest validates passenger information
-func (v *Validator) validatePassengerRequest(req *dto.PassengerRequest) error {
+// Only the lead passenger must be an adult with a passport and contact details;
+// children travel on birth certificates.
+func (v *Validator) validatePassengerRequest(req *dto.PassengerRequest, lead bool) error {
 	if strings.TrimSpace(req.FirstName) == "" {
 		return errors.New("'first_name' is required")
 	}
@@ -100,13 +157,13 @@ func (v *Validator) validatePassengerRequest(req *dto.PassengerRequest) error {
 		return errors.New("'date_of_birth' must be in YYYY-MM-DD format")
 	}
 
-	// Check passenger age (must be 18+)
+	// Check passenger age (lead passenger must be 18+)
 	age := time.Since(dob).Hours() / 24 / 365
-	if age < 18 {
-		return errors.New("passenger must be at least 18 years old")
+	if lead && age < 18 {
+		return errors.New("lead passenger must be at least 18 years old")
 	}
 
-	if age > 120 {
+	if age < 0 || age > 120 {
 		return errors.New("invalid date of birth")
 	}
 
@@ -114,6 +171,15 @@ func (v *Validator) validatePassengerRequest(req *dto.PassengerRequest) error {
 		return errors.New("'passport_number' is required")
 	}
 
+	if !lead {
+		if req.Email != "" {
+			if _, err := mail.ParseAddress(req.Email); err != nil {
+				return errors.New("'email' must be a valid email address")
+			}
+		}
+		return nil
+	}
+
 	// Validate passport number format (Russian passport: 4 digits space 6 digits)
 	passportPattern := regexp.MustCompile(`^\d{4}\s?\d{6}$`)
 	if !passportPattern.MatchString(strings.ReplaceAll(req.PassportNumber, " ", "")) {
@@ -161,3 +227,29 @@ func (v *Validator) ValidateCancelBookingRequest(req *dto.CancelBookingRequest)
 
 	return nil
 }
+
+// ValidateChangeBookingRequest validates change booking request
+func (v *Validator) ValidateChangeBookingRequest(req *dto.ChangeBookingRequest) error {
+	if len(req.Changes) == 0 {
+		return errors.New("'changes' must contain at least one ticket")
+	}
+
+	for i, change := range req.Changes {
+		if strings.TrimSpace(change.BookedSegmentID) == "" {
+			return fmt.Errorf("changes[%d]: 'booked_segment_id' is required", i)
+		}
+		if strings.TrimSpace(change.SegmentID) == "" {
+			return fmt.Errorf("changes[%d]: 'segment_id' is required", i)
+		}
+	}
+
+	return v.ValidateCancelBookingRequest(&dto.CancelBookingRequest{Reason: req.Reason})
+}
+
+// ValidateInsuranceClaimRequest validates insurance claim request
+func (v *Validator) ValidateInsuranceClaimRequest(req *dto.InsuranceClaimRequest) error {
+	if strings.TrimSpace(req.BookedSegmentID) == "" {
+		return errors.New("'booked_segment_id' is required")
+	}
+	return nil
+}
@@ -3,11 +3,13 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"net/url"
 
+	"github.com/gorilla/mux"
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/internal/service"
 )
@@ -32,27 +34,37 @@ type YooKassaAmount struct {
 }
 
 // WebhookHandler handles webhook notifications
+// Notifications are not trusted as sent: the webhook service re-fetches the payment or refund
+// from the gateway, checks the notification against it and applies it to the booking.
+// An address allowlist and signatures are checked where configured or supported.
 type WebhookHandler struct {
-	bookingService *service.BookingService
 	paymentService *service.PaymentService
+	webhookService *service.WebhookService
 	errorHandler   *ErrorHandler
 }
 
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler(
-	bookingService *service.BookingService,
 	paymentService *service.PaymentService,
+	webhookService *service.WebhookService,
 ) *WebhookHandler {
 	return &WebhookHandler{
-		bookingService: bookingService,
 		paymentService: paymentService,
+		webhookService: webhookService,
 		errorHandler:   NewErrorHandler(),
 	}
 }
 
 // HandleYooKassaWebhook processes YooKassa payment notifications
 func (h *WebhookHandler) HandleYooKassaWebhook(w http.ResponseWriter, r *http.Request) {
-	// 1. Read request body
+	// 1. Only YooKassa may notify
+	if !h.webhookService.Allowed(r.RemoteAddr, r.Header.Get("X-Forwarded-For")) {
+		fmt.Printf("[YooKassa Webhook] Rejected notification from %s\n", r.RemoteAddr)
+		h.errorHandler.RespondWithError(w, http.StatusForbidden, "FORBIDDEN", "Notifications are not accepted from this address")
+		return
+	}
+
+	// 2. Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BODY", "Cannot read request body")
@@ -60,106 +72,204 @@ func (h *WebhookHandler) HandleYooKassaWebhook(w http.ResponseWriter, r *http.Re
 	}
 	defer r.Body.Close()
 
-	// 2. Parse webhook event
+	// 3. Parse webhook event
 	var event YooKassaWebhookEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_JSON", "Cannot parse webhook event")
 		return
 	}
 
-	// 3. Log webhook (for debugging)
-	fmt.Printf("[YooKassa Webhook] Event: %s, Payment ID: %s, Status: %s\n",
+	// 4. Log webhook (for debugging)
+	fmt.Printf("[YooKassa Webhook] Event: %s, Object ID: %s, Status: %s\n",
 		event.Event, event.Object.ID, event.Object.Status)
 
-	// 4. Get order_id from metadata
-	orderID, ok := event.Object.Metadata["order_id"]
-	if !ok {
-		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "MISSING_ORDER_ID", "order_id not found in metadata")
+	if event.Object.ID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "MISSING_OBJECT_ID", "object.id not found in event")
 		return
 	}
 
-	// 5. Process based on event type
-	switch event.Event {
-	case "payment.succeeded":
-		// Payment completed successfully
-		err = h.handlePaymentSucceeded(r.Context(), orderID, event.Object.ID)
-	case "payment.canceled":
-		// Payment was canceled
-		err = h.handlePaymentCanceled(r.Context(), orderID, event.Object.ID)
-	case "refund.succeeded":
-		// Refund completed
-		err = h.handleRefundSucceeded(r.Context(), orderID)
-	default:
-		// Unknown event - log and ignore
-		fmt.Printf("[YooKassa Webhook] Unknown event type: %s\n", event.Event)
-	}
-
+	// 5. Process each notification once, with the state fetched from YooKassa
+	notification := domain.NewWebhookEvent("yookassa", event.Event, event.Object.ID)
+	err = h.webhookService.ProcessOnce(r.Context(), notification, func(ctx context.Context) error {
+		switch event.Event {
+		case "payment.waiting_for_capture":
+			// Two-stage payment authorized (pay-first flow): issue tickets, then capture
+			return h.webhookService.ApplyPayment(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, event.Object.ID, domain.PaymentAuthorized)
+		case "payment.succeeded":
+			// Payment completed successfully
+			return h.webhookService.ApplyPayment(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, event.Object.ID, domain.PaymentCompleted)
+		case "payment.canceled":
+			// Payment was canceled
+			return h.webhookService.ApplyPayment(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, event.Object.ID, domain.PaymentFailed)
+		case "refund.succeeded":
+			// Refund completed
+			return h.webhookService.ApplyRefund(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, event.Object.ID)
+		default:
+			// Unknown event - log and ignore
+			fmt.Printf("[YooKassa Webhook] Unknown event type: %s\n", event.Event)
+			return nil
+		}
+	})
 	if err != nil {
-		fmt.Printf("[YooKassa Webhook] Error processing event: %v\n", err)
-		h.errorHandler.RespondWithError(w, http.StatusInternalServerError, "PROCESSING_ERROR", err.Error())
+		h.respondFailure(w, err)
 		return
 	}
 
 	// 6. Return 200 OK to acknowledge receipt
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "ok"}`))
+	h.acknowledge(w)
 }
 
-func (h *WebhookHandler) handlePaymentSucceeded(ctx context.Context, orderID, providerPaymentID string) error {
-	// Get booking
-	booking, err := h.bookingService.GetBooking(ctx, orderID)
+// HandleCloudPaymentsWebhook processes CloudPayments notifications
+// (POST /webhooks/cloudpayments/{type}, type being pay, confirm, cancel, refund or fail)
+func (h *WebhookHandler) HandleCloudPaymentsWebhook(w http.ResponseWriter, r *http.Request) {
+	kind := mux.Vars(r)["type"]
+
+	// 1. Read request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BODY", "Cannot read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	// 2. Only CloudPayments can sign the body with our API secret
+	if err := h.paymentService.VerifyNotification(domain.PaymentCloudPay, body, r.Header.Get("Content-HMAC")); err != nil {
+		fmt.Printf("[CloudPayments Webhook] Rejected notification from %s: %v\n", r.RemoteAddr, err)
+		h.errorHandler.RespondWithError(w, http.StatusForbidden, "FORBIDDEN", "Invalid notification signature")
+		return
+	}
+
+	// 3. Parse notification
+	form, err := url.ParseQuery(string(body))
 	if err != nil {
-		return fmt.Errorf("booking not found: %w", err)
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BODY", "Cannot parse notification")
+		return
 	}
+	transactionID := form.Get("TransactionId")
+
+	fmt.Printf("[CloudPayments Webhook] Notification: %s, Transaction: %s, Status: %s\n",
+		kind, transactionID, form.Get("Status"))
 
-	// Update payment status
-	if booking.Payment != nil {
-		booking.Payment.Status = domain.PaymentCompleted
-		now := time.Now()
-		booking.Payment.CompletedAt = &now
-		booking.Payment.ProviderPaymentID = providerPaymentID
+	if transactionID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "MISSING_OBJECT_ID", "TransactionId not found in notification")
+		return
 	}
 
-	// Confirm booking
-	booking.MarkAsConfirmed()
+	// 4. Process each notification once, with the state fetched from CloudPayments
+	notification := domain.NewWebhookEvent("cloudpayments", kind, transactionID)
+	err = h.webhookService.ProcessOnce(r.Context(), notification, func(ctx context.Context) error {
+		switch kind {
+		case "pay", "confirm", "cancel":
+			// Payment authorized, completed (one-stage, or confirmed) or voided
+			return h.webhookService.ApplyPayment(ctx, domain.PaymentCloudPay, domain.ActorCloudPayments, transactionID, "")
+		case "refund":
+			// Refund completed
+			return h.webhookService.ApplyRefund(ctx, domain.PaymentCloudPay, domain.ActorCloudPayments, transactionID)
+		case "fail":
+			// A declined attempt: the customer may still pay on the same order page
+			return nil
+		default:
+			fmt.Printf("[CloudPayments Webhook] Unknown notification type: %s\n", kind)
+			return nil
+		}
+	})
+	if err != nil {
+		h.respondFailure(w, err)
+		return
+	}
 
-	// Save to database
-	return h.bookingService.UpdateBooking(ctx, booking)
+	// 5. CloudPayments expects code 0 to acknowledge receipt
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"code": 0}`))
 }
 
-func (h *WebhookHandler) handlePaymentCanceled(ctx context.Context, orderID, providerPaymentID string) error {
-	// Get booking
-	booking, err := h.bookingService.GetBooking(ctx, orderID)
+// HandleSberPayWebhook processes SberPay callbacks (GET or POST /webhooks/sberpay)
+func (h *WebhookHandler) HandleSberPayWebhook(w http.ResponseWriter, r *http.Request) {
+	// 1. Read callback parameters (query string or form body)
+	raw := r.URL.RawQuery
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BODY", "Cannot read request body")
+			return
+		}
+		defer r.Body.Close()
+		raw = string(body)
+	}
+
+	params, err := url.ParseQuery(raw)
 	if err != nil {
-		return fmt.Errorf("booking not found: %w", err)
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "INVALID_BODY", "Cannot parse callback")
+		return
 	}
 
-	// Mark payment as failed
-	if booking.Payment != nil {
-		booking.Payment.Status = domain.PaymentFailed
-		booking.Payment.FailureReason = "Payment canceled by user or provider"
+	// 2. Only SberPay can sign the callback with our callback token
+	if err := h.paymentService.VerifyNotification(domain.PaymentSberPay, []byte(raw), params.Get("checksum")); err != nil {
+		fmt.Printf("[SberPay Webhook] Rejected callback from %s: %v\n", r.RemoteAddr, err)
+		h.errorHandler.RespondWithError(w, http.StatusForbidden, "FORBIDDEN", "Invalid callback checksum")
+		return
 	}
 
-	// Mark booking as failed
-	booking.MarkAsFailed("Payment canceled by user or provider")
+	orderID := params.Get("mdOrder")
+	operation := params.Get("operation")
 
-	// Rollback provider bookings if any
-	// (in real implementation, cancel tickets with providers)
+	fmt.Printf("[SberPay Webhook] Operation: %s, Order: %s, Status: %s\n",
+		operation, orderID, params.Get("status"))
 
-	return h.bookingService.UpdateBooking(ctx, booking)
-}
+	if orderID == "" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, "MISSING_OBJECT_ID", "mdOrder not found in callback")
+		return
+	}
+	if params.Get("status") != "1" {
+		// The operation itself failed, so the order did not change
+		h.acknowledge(w)
+		return
+	}
 
-func (h *WebhookHandler) handleRefundSucceeded(ctx context.Context, orderID string) error {
-	// Update booking status to refunded
-	booking, err := h.bookingService.GetBooking(ctx, orderID)
+	// 3. Process each callback once, with the state fetched from SberPay
+	notification := domain.NewWebhookEvent("sberpay", operation, orderID)
+	if operation == "refunded" {
+		notification, err = h.webhookService.RefundEvent(r.Context(), domain.PaymentSberPay, "sberpay", operation, orderID)
+		if err != nil {
+			h.respondFailure(w, err)
+			return
+		}
+	}
+	err = h.webhookService.ProcessOnce(r.Context(), notification, func(ctx context.Context) error {
+		switch operation {
+		case "approved", "deposited", "reversed", "declinedByTimeout":
+			// Amount held, deposited, or the order reversed or expired
+			return h.webhookService.ApplyPayment(ctx, domain.PaymentSberPay, domain.ActorSberPay, orderID, "")
+		case "refunded":
+			// Refund completed
+			return h.webhookService.ApplyRefund(ctx, domain.PaymentSberPay, domain.ActorSberPay, orderID)
+		default:
+			fmt.Printf("[SberPay Webhook] Unknown operation: %s\n", operation)
+			return nil
+		}
+	})
 	if err != nil {
-		return fmt.Errorf("booking not found: %w", err)
+		h.respondFailure(w, err)
+		return
 	}
 
-	if booking.Payment != nil {
-		booking.Payment.Status = domain.PaymentRefunded
+	// 4. Return 200 OK to acknowledge receipt
+	h.acknowledge(w)
+}
+
+// respondFailure reports a notification that was not applied, so that the gateway retries it
+func (h *WebhookHandler) respondFailure(w http.ResponseWriter, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) && domainErr.Code == "PAYMENT_NOT_VERIFIED" {
+		h.errorHandler.RespondWithError(w, http.StatusBadRequest, domainErr.Code, domainErr.Message)
+		return
 	}
-	booking.Status = domain.BookingRefunded
+	h.errorHandler.RespondWithError(w, http.StatusInternalServerError, "PROCESSING_ERROR", err.Error())
+}
 
-	return h.bookingService.UpdateBooking(ctx, booking)
+// acknowledge tells the gateway the notification needs no retry
+func (h *WebhookHandler) acknowledge(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status": "ok"}`))
 }
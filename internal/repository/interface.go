@@ -48,8 +48,106 @@ type BookingRepository interface {
 	// FindByPassenger finds bookings by passenger email
 	FindByPassenger(ctx context.Context, email string) ([]domain.Booking, error)
 
-	// FindByStatus finds bookings by status
+	// FindByStatus finds bookings by status, with their passengers, tickets and payments
 	FindByStatus(ctx context.Context, status domain.BookingStatus) ([]domain.Booking, error)
+
+	// FindWithPendingRefunds finds bookings with a cancelled ticket whose refund is still
+	// due, with their passengers, tickets and payments
+	FindWithPendingRefunds(ctx context.Context) ([]domain.Booking, error)
+}
+
+// SeatInventoryRepository defines operations for seat holds on segments
+type SeatInventoryRepository interface {
+	// Hold places all holds atomically; it fails with SEATS_UNAVAILABLE if any
+	// segment has fewer free seats than requested
+	Hold(ctx context.Context, holds []domain.SeatHold) error
+
+	// Available returns the provider's free seats on a segment, minus active holds and
+	// holds confirmed since the segment was last synced
+	Available(ctx context.Context, segmentID string) (int, error)
+
+	// ConfirmByBooking turns a booking's holds into sold seats; expired holds are confirmed
+	// only if their segments still have the seats, otherwise it fails with SEATS_UNAVAILABLE
+	ConfirmByBooking(ctx context.Context, bookingID string) error
+
+	// ReleaseByBooking releases a booking's holds, including the seats it had confirmed
+	ReleaseByBooking(ctx context.Context, bookingID string) error
+
+	// ReturnSeats gives back sold seats of a booking on one segment (a cancelled ticket)
+	ReturnSeats(ctx context.Context, bookingID, segmentID string, seats int) error
+
+	// ReleaseHolds releases the given holds if they are not confirmed yet
+	ReleaseHolds(ctx context.Context, holdIDs []string) error
+
+	// ReleaseExpired marks holds past their expiry as expired and returns how many were released
+	ReleaseExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// BookingChangeRepository defines operations for the change history of bookings
+type BookingChangeRepository interface {
+	// Save stores a change; it fails if the booking version was already recorded
+	Save(ctx context.Context, change *domain.BookingChange) error
+
+	// FindByBooking retrieves the changes of a booking, oldest first
+	FindByBooking(ctx context.Context, bookingID string) ([]domain.BookingChange, error)
+}
+
+// DisruptionRepository defines operations for disrupted journeys
+type DisruptionRepository interface {
+	// Save stores a disruption; it fails if the passenger's disruption was already recorded
+	Save(ctx context.Context, disruption *domain.Disruption) error
+
+	// FindByBooking retrieves the disruptions of a booking, oldest first
+	FindByBooking(ctx context.Context, bookingID string) ([]domain.Disruption, error)
+}
+
+// InsuranceClaimRepository defines operations for insurance claims
+type InsuranceClaimRepository interface {
+	// Save stores a claim; an approved claim is rejected with CLAIM_NOT_ALLOWED if the
+	// same ticket has a pending or paid claim already
+	Save(ctx context.Context, claim *domain.InsuranceClaim) error
+
+	// Update stores the outcome of a claim (status, payout, rejection reason, insurer's reference)
+	Update(ctx context.Context, claim *domain.InsuranceClaim) error
+
+	// FindByBooking retrieves the insurance claims of a booking, oldest first
+	FindByBooking(ctx context.Context, bookingID string) ([]domain.InsuranceClaim, error)
+}
+
+// WebhookEventRepository defines operations for processed payment notifications
+type WebhookEventRepository interface {
+	// Claim records an event before it is processed; returns false if it was recorded already
+	Claim(ctx context.Context, event *domain.WebhookEvent) (bool, error)
+
+	// Release forgets an event whose processing failed, so that its retry is processed
+	Release(ctx context.Context, event *domain.WebhookEvent) error
+}
+
+// FareRuleRepository defines operations for provider fare rules
+type FareRuleRepository interface {
+	// FindAll retrieves every provider fare rule
+	FindAll(ctx context.Context) ([]domain.FareRule, error)
+
+	// Upsert inserts or replaces the rule of a provider tariff
+	Upsert(ctx context.Context, rule *domain.FareRule) error
+}
+
+// SagaRepository defines operations for booking saga persistence
+type SagaRepository interface {
+	// Save stores a new saga
+	Save(ctx context.Context, saga *domain.BookingSaga) error
+
+	// SaveStep inserts or updates a saga step and touches the saga's updated_at
+	SaveStep(ctx context.Context, step *domain.SagaStep) error
+
+	// UpdateStatus updates the saga status and last error
+	UpdateStatus(ctx context.Context, saga *domain.BookingSaga) error
+
+	// FindByID retrieves a saga with its steps
+	FindByID(ctx context.Context, id string) (*domain.BookingSaga, error)
+
+	// FindByStatus retrieves sagas in any of the given statuses, with their steps
+	FindByStatus(ctx context.Context, statuses ...domain.SagaStatus) ([]domain.BookingSaga, error)
 }
 
 // Transaction represents a database transaction for ACID guarantees
@@ -96,6 +194,18 @@ type StopRepository interface {
 
 	// FindAll retrieves all stops
 	FindAll(ctx context.Context) ([]domain.Stop, error)
+
+	// Search finds stops by name or city (full-text ranked, with prefix/trigram fallback)
+	Search(ctx context.Context, query string, limit int) ([]domain.Stop, error)
+}
+
+// CityRepository defines operations for canonical city persistence
+type CityRepository interface {
+	// FindAll retrieves all cities with their aliases
+	FindAll(ctx context.Context) ([]domain.City, error)
+
+	// Upsert inserts or updates a city and adds its aliases, recording where they came from
+	Upsert(ctx context.Context, city *domain.City, source string) error
 }
 
 // SegmentRepository defines operations for segment persistence
@@ -109,9 +219,16 @@ type SegmentRepository interface {
 	// FindByID retrieves a segment by ID
 	FindByID(ctx context.Context, id string) (*domain.Segment, error)
 
+	// FindByIDs retrieves the segments with the given IDs; IDs not found are left out
+	FindByIDs(ctx context.Context, ids []string) ([]domain.Segment, error)
+
 	// FindByCriteria searches segments by origin, destination, and date range
+	// fromCity and toCity are canonical city IDs or, for unresolved cities, city names
 	FindByCriteria(ctx context.Context, fromCity, toCity string, departureStart, departureEnd time.Time) ([]domain.Segment, error)
 
+	// FindByDepartureWindow retrieves every segment departing in [departureStart, departureEnd)
+	FindByDepartureWindow(ctx context.Context, departureStart, departureEnd time.Time) ([]domain.Segment, error)
+
 	// DeleteOldSegments removes segments older than specified date
 	DeleteOldSegments(ctx context.Context, beforeDate time.Time) error
 
@@ -124,3 +124,21 @@ func (r *BookingRepository) FindByStatus(ctx context.Context, status domain.Book
 
 	return bookings, nil
 }
+
+// FindWithPendingRefunds finds bookings with a cancelled ticket whose refund is still due
+func (r *BookingRepository) FindWithPendingRefunds(ctx context.Context) ([]domain.Booking, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bookings := make([]domain.Booking, 0)
+	for _, booking := range r.bookings {
+		for _, segment := range booking.Segments {
+			if segment.RefundPending {
+				bookings = append(bookings, *booking)
+				break
+			}
+		}
+	}
+
+	return bookings, nil
+}
@@ -122,10 +122,10 @@ func (r *RouteRepository) FindByCriteria(ctx context.Context, criteria *domain.R
 		}
 
 		// Filter by budget if specified
-		if criteria.BudgetMax > 0 && route.TotalPrice > criteria.BudgetMax {
+		if criteria.BudgetMax > 0 && route.TotalPrice.Major() > criteria.BudgetMax {
 			continue
 		}
-		if criteria.BudgetMin > 0 && route.TotalPrice < criteria.BudgetMin {
+		if criteria.BudgetMin > 0 && route.TotalPrice.Major() < criteria.BudgetMin {
 			continue
 		}
 
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// BookingChangeRepository implements repository.BookingChangeRepository interface for PostgreSQL
+type BookingChangeRepository struct {
+	db *Database
+}
+
+// NewBookingChangeRepository creates a new booking change repository
+func NewBookingChangeRepository(db *Database) repository.BookingChangeRepository {
+	return &BookingChangeRepository{db: db}
+}
+
+// Save stores a change; the (booking_id, version) key rejects a version recorded twice
+func (r *BookingChangeRepository) Save(ctx context.Context, change *domain.BookingChange) error {
+	const query = `
+		INSERT INTO booking_changes (
+			id, booking_id, version, tickets, fare_difference, change_fee,
+			amount, payment_id, actor, reason, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+
+	tickets, err := json.Marshal(change.Tickets)
+	if err != nil {
+		return fmt.Errorf("error encoding changed tickets: %w", err)
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
+		change.ID,
+		change.BookingID,
+		change.Version,
+		tickets,
+		change.FareDifference,
+		change.ChangeFee,
+		change.Amount,
+		nullString(change.PaymentID),
+		change.Actor,
+		nullString(change.Reason),
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving booking change: %w", err)
+	}
+
+	return nil
+}
+
+// FindByBooking retrieves the changes of a booking, oldest first
+func (r *BookingChangeRepository) FindByBooking(ctx context.Context, bookingID string) ([]domain.BookingChange, error) {
+	const query = `
+		SELECT id, booking_id, version, tickets, fare_difference, change_fee,
+		       amount, payment_id, actor, reason, created_at
+		FROM booking_changes
+		WHERE booking_id = $1
+		ORDER BY version
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying booking changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]domain.BookingChange, 0)
+	for rows.Next() {
+		var change domain.BookingChange
+		var tickets []byte
+		var paymentID, reason sql.NullString
+
+		if err := rows.Scan(
+			&change.ID,
+			&change.BookingID,
+			&change.Version,
+			&tickets,
+			&change.FareDifference,
+			&change.ChangeFee,
+			&change.Amount,
+			&paymentID,
+			&change.Actor,
+			&reason,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning booking change: %w", err)
+		}
+
+		if err := json.Unmarshal(tickets, &change.Tickets); err != nil {
+			return nil, fmt.Errorf("error decoding changed tickets: %w", err)
+		}
+		change.PaymentID = paymentID.String
+		change.Reason = reason.String
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -25,7 +26,7 @@ func (r *BookingRepository) FindByID(ctx context.Context, id string) (*domain.Bo
 	const query = `
 		SELECT id, route_id, status, total_price, total_commission, grand_total,
 		       insurance_premium, include_insurance, created_at, updated_at,
-		       confirmed_at, cancelled_at, cancellation_reason,
+		       confirmed_at, cancelled_at, cancellation_reason, version,
 		       passenger_first_name, passenger_last_name, passenger_middle_name,
 		       passenger_date_of_birth, passenger_passport_number,
 		       passenger_email, passenger_phone
@@ -51,6 +52,7 @@ func (r *BookingRepository) FindByID(ctx context.Context, id string) (*domain.Bo
 		&confirmedAt,
 		&cancelledAt,
 		&cancellationReason,
+		&booking.Version,
 		&booking.Passenger.FirstName,
 		&booking.Passenger.LastName,
 		&middleName,
@@ -81,13 +83,7 @@ func (r *BookingRepository) FindByID(ctx context.Context, id string) (*domain.Bo
 		booking.CancellationReason = cancellationReason.String
 	}
 
-	// Fetch booked segments
-	if err := r.fetchBookedSegments(ctx, &booking); err != nil {
-		return nil, err
-	}
-
-	// Fetch payment if exists
-	if err := r.fetchPayment(ctx, &booking); err != nil {
+	if err := r.fetchDetails(ctx, &booking); err != nil {
 		return nil, err
 	}
 
@@ -99,7 +95,7 @@ func (r *BookingRepository) FindAll(ctx context.Context) ([]domain.Booking, erro
 	const query = `
 		SELECT id, route_id, status, total_price, total_commission, grand_total,
 		       insurance_premium, include_insurance, created_at, updated_at,
-		       confirmed_at, cancelled_at, cancellation_reason,
+		       confirmed_at, cancelled_at, cancellation_reason, version,
 		       passenger_first_name, passenger_last_name, passenger_middle_name,
 		       passenger_date_of_birth, passenger_passport_number,
 		       passenger_email, passenger_phone
@@ -134,6 +130,7 @@ func (r *BookingRepository) FindAll(ctx context.Context) ([]domain.Booking, erro
 			&confirmedAt,
 			&cancelledAt,
 			&cancellationReason,
+			&booking.Version,
 			&booking.Passenger.FirstName,
 			&booking.Passenger.LastName,
 			&middleName,
@@ -169,7 +166,7 @@ func (r *BookingRepository) FindByPassenger(ctx context.Context, email string) (
 	const query = `
 		SELECT id, route_id, status, total_price, total_commission, grand_total,
 		       insurance_premium, include_insurance, created_at, updated_at,
-		       confirmed_at, cancelled_at, cancellation_reason,
+		       confirmed_at, cancelled_at, cancellation_reason, version,
 		       passenger_first_name, passenger_last_name, passenger_middle_name,
 		       passenger_date_of_birth, passenger_passport_number,
 		       passenger_email, passenger_phone
@@ -204,6 +201,7 @@ func (r *BookingRepository) FindByPassenger(ctx context.Context, email string) (
 			&confirmedAt,
 			&cancelledAt,
 			&cancellationReason,
+			&booking.Version,
 			&booking.Passenger.FirstName,
 			&booking.Passenger.LastName,
 			&middleName,
@@ -234,23 +232,34 @@ func (r *BookingRepository) FindByPassenger(ctx context.Context, email string) (
 	return bookings, rows.Err()
 }
 
-// FindByStatus finds bookings by status
+// FindByStatus finds bookings by status, with all details (background jobs act on them)
 func (r *BookingRepository) FindByStatus(ctx context.Context, status domain.BookingStatus) ([]domain.Booking, error) {
-	const query = `
+	return r.findWithDetails(ctx, `status = $1`, string(status))
+}
+
+// FindWithPendingRefunds finds bookings with a cancelled ticket whose refund is still due,
+// with all details
+func (r *BookingRepository) FindWithPendingRefunds(ctx context.Context) ([]domain.Booking, error) {
+	return r.findWithDetails(ctx, `id IN (SELECT booking_id FROM booked_segments WHERE refund_pending)`)
+}
+
+// findWithDetails loads the bookings matching a condition with all details
+func (r *BookingRepository) findWithDetails(ctx context.Context, condition string, args ...interface{}) ([]domain.Booking, error) {
+	query := `
 		SELECT id, route_id, status, total_price, total_commission, grand_total,
 		       insurance_premium, include_insurance, created_at, updated_at,
-		       confirmed_at, cancelled_at, cancellation_reason,
+		       confirmed_at, cancelled_at, cancellation_reason, version,
 		       passenger_first_name, passenger_last_name, passenger_middle_name,
 		       passenger_date_of_birth, passenger_passport_number,
 		       passenger_email, passenger_phone
 		FROM bookings
-		WHERE status = $1
+		WHERE ` + condition + `
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.db.QueryContext(ctx, query, string(status))
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error querying bookings by status: %w", err)
+		return nil, fmt.Errorf("error querying bookings: %w", err)
 	}
 	defer rows.Close()
 
@@ -274,6 +283,7 @@ func (r *BookingRepository) FindByStatus(ctx context.Context, status domain.Book
 			&confirmedAt,
 			&cancelledAt,
 			&cancellationReason,
+			&booking.Version,
 			&booking.Passenger.FirstName,
 			&booking.Passenger.LastName,
 			&middleName,
@@ -300,8 +310,18 @@ func (r *BookingRepository) FindByStatus(ctx context.Context, status domain.Book
 
 		bookings = append(bookings, booking)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookings: %w", err)
+	}
+	rows.Close()
 
-	return bookings, rows.Err()
+	for i := range bookings {
+		if err := r.fetchDetails(ctx, &bookings[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return bookings, nil
 }
 
 // Save stores a new booking
@@ -310,13 +330,13 @@ func (r *BookingRepository) Save(ctx context.Context, booking *domain.Booking) e
 		INSERT INTO bookings (
 			id, route_id, status, total_price, total_commission, grand_total,
 			insurance_premium, include_insurance, created_at, updated_at,
-			confirmed_at, cancelled_at, cancellation_reason,
+			confirmed_at, cancelled_at, cancellation_reason, version,
 			passenger_first_name, passenger_last_name, passenger_middle_name,
 			passenger_date_of_birth, passenger_passport_number,
 			passenger_email, passenger_phone
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 		)
 	`
 
@@ -334,6 +354,7 @@ func (r *BookingRepository) Save(ctx context.Context, booking *domain.Booking) e
 		booking.ConfirmedAt,
 		booking.CancelledAt,
 		booking.CancellationReason,
+		max(booking.Version, 1),
 		booking.Passenger.FirstName,
 		booking.Passenger.LastName,
 		booking.Passenger.MiddleName,
@@ -347,6 +368,11 @@ func (r *BookingRepository) Save(ctx context.Context, booking *domain.Booking) e
 		return fmt.Errorf("error saving booking: %w", err)
 	}
 
+	// Save passengers (booked segments reference them)
+	if err := r.savePassengers(ctx, booking); err != nil {
+		return err
+	}
+
 	// Save booked segments
 	if err := r.saveBookedSegments(ctx, booking); err != nil {
 		return err
@@ -357,9 +383,19 @@ func (r *BookingRepository) Save(ctx context.Context, booking *domain.Booking) e
 		if err := r.savePayment(ctx, booking.Payment); err != nil {
 			return err
 		}
+		if err := r.saveCharges(ctx, booking); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// Save insurance policy if issued
+	if booking.Policy != nil {
+		if err := r.savePolicy(ctx, booking.ID, booking.Policy); err != nil {
+			return err
+		}
+	}
+
+	return r.saveTransitions(ctx, booking)
 }
 
 // Update modifies an existing booking
@@ -367,7 +403,8 @@ func (r *BookingRepository) Update(ctx context.Context, booking *domain.Booking)
 	const query = `
 		UPDATE bookings
 		SET status = $2, updated_at = $3, confirmed_at = $4,
-		    cancelled_at = $5, cancellation_reason = $6
+		    cancelled_at = $5, cancellation_reason = $6,
+		    total_price = $7, total_commission = $8, grand_total = $9, version = $10
 		WHERE id = $1
 	`
 
@@ -378,6 +415,10 @@ func (r *BookingRepository) Update(ctx context.Context, booking *domain.Booking)
 		booking.ConfirmedAt,
 		booking.CancelledAt,
 		booking.CancellationReason,
+		booking.TotalPrice,
+		booking.TotalCommission,
+		booking.GrandTotal,
+		max(booking.Version, 1),
 	)
 
 	if err != nil {
@@ -393,14 +434,29 @@ func (r *BookingRepository) Update(ctx context.Context, booking *domain.Booking)
 		return domain.ErrBookingNotFound
 	}
 
-	// Update payment if exists
+	// Update ticket statuses (issuing, cancellations) and add tickets from booking changes
+	if err := r.saveBookedSegments(ctx, booking); err != nil {
+		return err
+	}
+
+	// Update payment if exists, and the charges taken since
 	if booking.Payment != nil {
 		if err := r.updatePayment(ctx, booking.Payment); err != nil {
 			return err
 		}
+		if err := r.saveCharges(ctx, booking); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// Save insurance policy if issued (on confirmation) and its payouts
+	if booking.Policy != nil {
+		if err := r.savePolicy(ctx, booking.ID, booking.Policy); err != nil {
+			return err
+		}
+	}
+
+	return r.saveTransitions(ctx, booking)
 }
 
 // Delete removes a booking
@@ -424,16 +480,38 @@ func (r *BookingRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// fetchDetails loads the passengers, tickets, payment and insurance policy of a booking
+func (r *BookingRepository) fetchDetails(ctx context.Context, booking *domain.Booking) error {
+	// Fetch passengers
+	if err := r.fetchPassengers(ctx, booking); err != nil {
+		return err
+	}
+
+	// Fetch booked segments
+	if err := r.fetchBookedSegments(ctx, booking); err != nil {
+		return err
+	}
+
+	// Fetch payment if exists
+	if err := r.fetchPayment(ctx, booking); err != nil {
+		return err
+	}
+
+	// Fetch insurance policy if issued
+	return r.fetchPolicy(ctx, booking)
+}
+
 // Helper functions
 
 func (r *BookingRepository) fetchBookedSegments(ctx context.Context, booking *domain.Booking) error {
 	const query = `
-		SELECT bs.id, bs.segment_id, bs.provider, bs.transport_type,
+		SELECT bs.id, bs.segment_id, COALESCE(bs.passenger_id, ''), bs.provider, bs.transport_type,
 		       bs.from_stop_id, fs.name, fs.city, fs.latitude, fs.longitude,
 		       bs.to_stop_id, ts.name, ts.city, ts.latitude, ts.longitude,
 		       bs.departure_time, bs.arrival_time,
 		       bs.ticket_number, bs.price, bs.commission, bs.total_price,
-		       bs.booking_status, bs.provider_booking_ref
+		       bs.booking_status, bs.provider_booking_ref, bs.refund_amount, bs.refund_pending, bs.cancelled_at,
+		       COALESCE(bs.source, ''), COALESCE(bs.tariff, ''), COALESCE(bs.replaced_by, '')
 		FROM booked_segments bs
 		JOIN stops fs ON bs.from_stop_id = fs.id
 		JOIN stops ts ON bs.to_stop_id = ts.id
@@ -450,10 +528,12 @@ func (r *BookingRepository) fetchBookedSegments(ctx context.Context, booking *do
 	for rows.Next() {
 		var segment domain.BookedSegment
 		var ticketNumber, providerRef sql.NullString
+		var cancelledAt sql.NullTime
 
 		if err := rows.Scan(
 			&segment.ID,
 			&segment.SegmentID,
+			&segment.PassengerID,
 			&segment.Provider,
 			&segment.TransportType,
 			&segment.From.ID,
@@ -474,6 +554,12 @@ func (r *BookingRepository) fetchBookedSegments(ctx context.Context, booking *do
 			&segment.TotalPrice,
 			&segment.BookingStatus,
 			&providerRef,
+			&segment.RefundAmount,
+			&segment.RefundPending,
+			&cancelledAt,
+			&segment.Source,
+			&segment.Tariff,
+			&segment.ReplacedBy,
 		); err != nil {
 			return fmt.Errorf("error scanning booked segment: %w", err)
 		}
@@ -484,6 +570,9 @@ func (r *BookingRepository) fetchBookedSegments(ctx context.Context, booking *do
 		if providerRef.Valid {
 			segment.ProviderBookingRef = providerRef.String
 		}
+		if cancelledAt.Valid {
+			segment.CancelledAt = &cancelledAt.Time
+		}
 
 		booking.Segments = append(booking.Segments, segment)
 	}
@@ -491,23 +580,116 @@ func (r *BookingRepository) fetchBookedSegments(ctx context.Context, booking *do
 	return rows.Err()
 }
 
-func (r *BookingRepository) fetchPayment(ctx context.Context, booking *domain.Booking) error {
+func (r *BookingRepository) fetchPassengers(ctx context.Context, booking *domain.Booking) error {
 	const query = `
-		SELECT id, order_id, amount, currency, method, status,
-		       provider_payment_id, confirmation_url, created_at, completed_at, failure_reason
-		FROM payments
-		WHERE order_id = $1
+		SELECT id, passenger_type, first_name, last_name, middle_name,
+		       date_of_birth, passport_number, email, phone
+		FROM booking_passengers
+		WHERE booking_id = $1
+		ORDER BY sequence_order
 	`
 
+	rows, err := r.db.db.QueryContext(ctx, query, booking.ID)
+	if err != nil {
+		return fmt.Errorf("error querying booking passengers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var passenger domain.Passenger
+		var middleName, email, phone sql.NullString
+
+		if err := rows.Scan(
+			&passenger.ID,
+			&passenger.Type,
+			&passenger.FirstName,
+			&passenger.LastName,
+			&middleName,
+			&passenger.DateOfBirth,
+			&passenger.PassportNumber,
+			&email,
+			&phone,
+		); err != nil {
+			return fmt.Errorf("error scanning booking passenger: %w", err)
+		}
+
+		passenger.MiddleName = middleName.String
+		passenger.Email = email.String
+		passenger.Phone = phone.String
+
+		booking.Passengers = append(booking.Passengers, passenger)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Bookings made before multi-passenger support only have the lead passenger
+	if len(booking.Passengers) == 0 {
+		booking.Passengers = []domain.Passenger{booking.Passenger}
+		return nil
+	}
+
+	booking.Passenger.ID = booking.Passengers[0].ID
+	booking.Passenger.Type = booking.Passengers[0].Type
+	return nil
+}
+
+const paymentColumns = `
+	id, order_id, amount, currency, method, status,
+	provider_payment_id, confirmation_url, created_at, completed_at, failure_reason, refunded_amount,
+	two_stage, receipt
+`
+
+// fetchPayment loads the booking's payment and the charges taken after it
+func (r *BookingRepository) fetchPayment(ctx context.Context, booking *domain.Booking) error {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 AND charge_of IS NULL`
+
+	payment, err := scanPayment(r.db.db.QueryRowContext(ctx, query, booking.ID))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	booking.Payment = payment
+
+	chargesQuery := `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 AND charge_of IS NOT NULL ORDER BY created_at`
+	rows, err := r.db.db.QueryContext(ctx, chargesQuery, booking.ID)
+	if err != nil {
+		return fmt.Errorf("error querying payment charges: %w", err)
+	}
+	defer rows.Close()
+
+	booking.Charges = nil
+	for rows.Next() {
+		charge, err := scanPayment(rows)
+		if err != nil {
+			return err
+		}
+		booking.Charges = append(booking.Charges, *charge)
+	}
+
+	return rows.Err()
+}
+
+// rowScanner is a single row of a query: *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPayment scans a row of paymentColumns
+func scanPayment(row rowScanner) (*domain.Payment, error) {
 	var payment domain.Payment
 	var completedAt sql.NullTime
+	var currency string
 	var providerPaymentID, confirmationURL, failureReason sql.NullString
+	var receipt []byte
 
-	err := r.db.db.QueryRowContext(ctx, query, booking.ID).Scan(
+	err := row.Scan(
 		&payment.ID,
 		&payment.OrderID,
 		&payment.Amount,
-		&payment.Currency,
+		&currency,
 		&payment.Method,
 		&payment.Status,
 		&providerPaymentID,
@@ -515,41 +697,92 @@ func (r *BookingRepository) fetchPayment(ctx context.Context, booking *domain.Bo
 		&payment.CreatedAt,
 		&completedAt,
 		&failureReason,
+		&payment.RefundedAmount,
+		&payment.TwoStage,
+		&receipt,
 	)
-
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("error querying payment: %w", err)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying payment: %w", err)
 	}
 
-	if err == nil {
-		if completedAt.Valid {
-			payment.CompletedAt = &completedAt.Time
-		}
-		if providerPaymentID.Valid {
-			payment.ProviderPaymentID = providerPaymentID.String
-		}
-		if confirmationURL.Valid {
-			payment.ConfirmationURL = confirmationURL.String
+	payment.Amount.Currency = domain.Currency(currency)
+	payment.RefundedAmount.Currency = domain.Currency(currency)
+	if completedAt.Valid {
+		payment.CompletedAt = &completedAt.Time
+	}
+	if providerPaymentID.Valid {
+		payment.ProviderPaymentID = providerPaymentID.String
+	}
+	if confirmationURL.Valid {
+		payment.ConfirmationURL = confirmationURL.String
+	}
+	if failureReason.Valid {
+		payment.FailureReason = failureReason.String
+	}
+	if len(receipt) > 0 {
+		if err := json.Unmarshal(receipt, &payment.Receipt); err != nil {
+			return nil, fmt.Errorf("error decoding payment receipt: %w", err)
 		}
-		if failureReason.Valid {
-			payment.FailureReason = failureReason.String
+	}
+
+	return &payment, nil
+}
+
+func (r *BookingRepository) savePassengers(ctx context.Context, booking *domain.Booking) error {
+	const query = `
+		INSERT INTO booking_passengers (
+			id, booking_id, passenger_type, first_name, last_name, middle_name,
+			date_of_birth, passport_number, email, phone, sequence_order
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+
+	for i, passenger := range booking.Passengers {
+		_, err := r.db.db.ExecContext(ctx, query,
+			passenger.ID,
+			booking.ID,
+			string(passenger.Type),
+			passenger.FirstName,
+			passenger.LastName,
+			nullString(passenger.MiddleName),
+			passenger.DateOfBirth,
+			passenger.PassportNumber,
+			nullString(passenger.Email),
+			nullString(passenger.Phone),
+			i+1,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving booking passenger: %w", err)
 		}
-		booking.Payment = &payment
 	}
 
 	return nil
 }
 
+// saveBookedSegments inserts new tickets and writes the status and refund of existing ones
 func (r *BookingRepository) saveBookedSegments(ctx context.Context, booking *domain.Booking) error {
 	const query = `
 		INSERT INTO booked_segments (
 			id, booking_id, segment_id, provider, transport_type,
 			from_stop_id, to_stop_id, departure_time, arrival_time,
 			ticket_number, price, commission, total_price,
-			booking_status, provider_booking_ref, sequence_order
+			booking_status, provider_booking_ref, sequence_order, passenger_id,
+			refund_amount, refund_pending, cancelled_at, source, tariff, replaced_by
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 		)
+		ON CONFLICT (id) DO UPDATE SET
+			booking_status = EXCLUDED.booking_status,
+			ticket_number = EXCLUDED.ticket_number,
+			provider_booking_ref = EXCLUDED.provider_booking_ref,
+			refund_amount = EXCLUDED.refund_amount,
+			refund_pending = EXCLUDED.refund_pending,
+			cancelled_at = EXCLUDED.cancelled_at,
+			replaced_by = EXCLUDED.replaced_by
 	`
 
 	for i, segment := range booking.Segments {
@@ -570,6 +803,13 @@ func (r *BookingRepository) saveBookedSegments(ctx context.Context, booking *dom
 			string(segment.BookingStatus),
 			segment.ProviderBookingRef,
 			i+1,
+			nullString(segment.PassengerID),
+			segment.RefundAmount,
+			segment.RefundPending,
+			segment.CancelledAt,
+			nullString(segment.Source),
+			nullString(segment.Tariff),
+			nullString(segment.ReplacedBy),
 		)
 		if err != nil {
 			return fmt.Errorf("error saving booked segment: %w", err)
@@ -579,21 +819,73 @@ func (r *BookingRepository) saveBookedSegments(ctx context.Context, booking *dom
 	return nil
 }
 
+// saveTransitions writes queued status changes to the audit trail
+func (r *BookingRepository) saveTransitions(ctx context.Context, booking *domain.Booking) error {
+	const query = `
+		INSERT INTO booking_status_audit (booking_id, old_status, new_status, changed_by, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for _, transition := range booking.Transitions {
+		_, err := r.db.db.ExecContext(ctx, query,
+			booking.ID,
+			nullString(string(transition.From)),
+			string(transition.To),
+			transition.Actor,
+			nullString(transition.Reason),
+			transition.At,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving status audit: %w", err)
+		}
+	}
+
+	booking.Transitions = nil
+	return nil
+}
+
 func (r *BookingRepository) savePayment(ctx context.Context, payment *domain.Payment) error {
+	return r.insertPayment(ctx, payment, "")
+}
+
+// saveCharges inserts new charges of a booking and writes the status and refunds of existing ones
+func (r *BookingRepository) saveCharges(ctx context.Context, booking *domain.Booking) error {
+	for i := range booking.Charges {
+		if err := r.insertPayment(ctx, &booking.Charges[i], booking.Payment.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertPayment stores a payment, or a charge of the payment chargeOf
+func (r *BookingRepository) insertPayment(ctx context.Context, payment *domain.Payment, chargeOf string) error {
 	const query = `
 		INSERT INTO payments (
 			id, order_id, amount, currency, method, status,
-			provider_payment_id, confirmation_url, created_at, completed_at, failure_reason
+			provider_payment_id, confirmation_url, created_at, completed_at, failure_reason,
+			refunded_amount, two_stage, receipt, charge_of
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			provider_payment_id = EXCLUDED.provider_payment_id,
+			completed_at = EXCLUDED.completed_at,
+			failure_reason = EXCLUDED.failure_reason,
+			refunded_amount = EXCLUDED.refunded_amount
 	`
 
-	_, err := r.db.db.ExecContext(ctx, query,
+	receipt, err := encodeReceipt(payment.Receipt)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
 		payment.ID,
 		payment.OrderID,
 		payment.Amount,
-		payment.Currency,
+		string(payment.Amount.Currency),
 		string(payment.Method),
 		string(payment.Status),
 		payment.ProviderPaymentID,
@@ -601,6 +893,10 @@ func (r *BookingRepository) savePayment(ctx context.Context, payment *domain.Pay
 		payment.CreatedAt,
 		payment.CompletedAt,
 		payment.FailureReason,
+		payment.RefundedAmount,
+		payment.TwoStage,
+		receipt,
+		nullString(chargeOf),
 	)
 
 	if err != nil {
@@ -613,7 +909,8 @@ func (r *BookingRepository) savePayment(ctx context.Context, payment *domain.Pay
 func (r *BookingRepository) updatePayment(ctx context.Context, payment *domain.Payment) error {
 	const query = `
 		UPDATE payments
-		SET status = $2, provider_payment_id = $3, completed_at = $4, failure_reason = $5
+		SET status = $2, provider_payment_id = $3, completed_at = $4, failure_reason = $5,
+		    refunded_amount = $6
 		WHERE id = $1
 	`
 
@@ -623,6 +920,7 @@ func (r *BookingRepository) updatePayment(ctx context.Context, payment *domain.P
 		payment.ProviderPaymentID,
 		payment.CompletedAt,
 		payment.FailureReason,
+		payment.RefundedAmount,
 	)
 
 	if err != nil {
@@ -631,3 +929,88 @@ func (r *BookingRepository) updatePayment(ctx context.Context, payment *domain.P
 
 	return nil
 }
+
+func (r *BookingRepository) fetchPolicy(ctx context.Context, booking *domain.Booking) error {
+	const query = `
+		SELECT number, provider, currency, premium, coverage_limit, paid_out, status,
+		       valid_from, valid_until, issued_at
+		FROM insurance_policies
+		WHERE booking_id = $1
+	`
+
+	var policy domain.InsurancePolicy
+	var currency string
+
+	err := r.db.db.QueryRowContext(ctx, query, booking.ID).Scan(
+		&policy.Number,
+		&policy.Provider,
+		&currency,
+		&policy.Premium,
+		&policy.CoverageLimit,
+		&policy.PaidOut,
+		&policy.Status,
+		&policy.ValidFrom,
+		&policy.ValidUntil,
+		&policy.IssuedAt,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error querying insurance policy: %w", err)
+	}
+
+	if err == nil {
+		policy.Premium.Currency = domain.Currency(currency)
+		policy.CoverageLimit.Currency = domain.Currency(currency)
+		policy.PaidOut.Currency = domain.Currency(currency)
+		booking.Policy = &policy
+	}
+
+	return nil
+}
+
+// savePolicy stores a booking's insurance policy, or its status and payouts when stored already
+func (r *BookingRepository) savePolicy(ctx context.Context, bookingID string, policy *domain.InsurancePolicy) error {
+	const query = `
+		INSERT INTO insurance_policies (
+			booking_id, number, provider, currency, premium, coverage_limit, paid_out,
+			status, valid_from, valid_until, issued_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+		ON CONFLICT (booking_id) DO UPDATE
+		SET paid_out = EXCLUDED.paid_out, status = EXCLUDED.status
+	`
+
+	_, err := r.db.db.ExecContext(ctx, query,
+		bookingID,
+		policy.Number,
+		policy.Provider,
+		string(policy.CoverageLimit.Currency),
+		policy.Premium,
+		policy.CoverageLimit,
+		policy.PaidOut,
+		string(policy.Status),
+		policy.ValidFrom,
+		policy.ValidUntil,
+		policy.IssuedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error saving insurance policy: %w", err)
+	}
+
+	return nil
+}
+
+// encodeReceipt converts a payment's receipt to JSONB (NULL when the payment has none)
+func encodeReceipt(receipt *domain.Receipt) (interface{}, error) {
+	if receipt == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding payment receipt: %w", err)
+	}
+	return data, nil
+}
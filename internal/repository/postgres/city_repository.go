@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// CityRepository implements repository.CityRepository interface for PostgreSQL
+type CityRepository struct {
+	db *Database
+}
+
+// NewCityRepository creates a new city repository
+func NewCityRepository(db *Database) repository.CityRepository {
+	return &CityRepository{db: db}
+}
+
+// FindAll retrieves all cities with their aliases
+func (r *CityRepository) FindAll(ctx context.Context) ([]domain.City, error) {
+	const query = `
+		SELECT id, name, COALESCE(name_en, ''), COALESCE(iata_code, ''), COALESCE(rzd_code, ''),
+		       COALESCE(country_code, ''), COALESCE(latitude, 0), COALESCE(longitude, 0)
+		FROM cities
+		ORDER BY id
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cities: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []domain.City
+	index := make(map[string]int)
+	for rows.Next() {
+		var city domain.City
+		if err := rows.Scan(
+			&city.ID,
+			&city.Name,
+			&city.NameEn,
+			&city.IATACode,
+			&city.RZDCode,
+			&city.CountryCode,
+			&city.Latitude,
+			&city.Longitude,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning city: %w", err)
+		}
+		index[city.ID] = len(cities)
+		cities = append(cities, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	aliasRows, err := r.db.db.QueryContext(ctx, `SELECT city_id, alias FROM city_aliases ORDER BY city_id, alias`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying city aliases: %w", err)
+	}
+	defer aliasRows.Close()
+
+	for aliasRows.Next() {
+		var cityID, alias string
+		if err := aliasRows.Scan(&cityID, &alias); err != nil {
+			return nil, fmt.Errorf("error scanning city alias: %w", err)
+		}
+		if i, ok := index[cityID]; ok {
+			cities[i].Aliases = append(cities[i].Aliases, alias)
+		}
+	}
+
+	return cities, aliasRows.Err()
+}
+
+// Upsert inserts or updates a city and adds its aliases
+// Fields already set are only overwritten by non-empty values.
+func (r *CityRepository) Upsert(ctx context.Context, city *domain.City, source string) error {
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const cityQuery = `
+		INSERT INTO cities (id, name, name_en, iata_code, rzd_code, country_code, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			name_en = COALESCE(cities.name_en, EXCLUDED.name_en),
+			iata_code = COALESCE(cities.iata_code, EXCLUDED.iata_code),
+			rzd_code = COALESCE(cities.rzd_code, EXCLUDED.rzd_code),
+			country_code = COALESCE(cities.country_code, EXCLUDED.country_code),
+			latitude = COALESCE(cities.latitude, EXCLUDED.latitude),
+			longitude = COALESCE(cities.longitude, EXCLUDED.longitude),
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err = tx.ExecContext(ctx, cityQuery,
+		city.ID,
+		city.Name,
+		nullString(city.NameEn),
+		nullString(city.IATACode),
+		nullString(city.RZDCode),
+		nullString(city.CountryCode),
+		nullCoordinate(city.Latitude, city.Longitude, city.Latitude),
+		nullCoordinate(city.Latitude, city.Longitude, city.Longitude),
+	)
+	if err != nil {
+		return fmt.Errorf("error upserting city: %w", err)
+	}
+
+	const aliasQuery = `
+		INSERT INTO city_aliases (city_id, alias, source)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	for _, alias := range city.Aliases {
+		if _, err := tx.ExecContext(ctx, aliasQuery, city.ID, alias, source); err != nil {
+			return fmt.Errorf("error saving city alias: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullCoordinate stores unknown (0,0) coordinates as NULL
+func nullCoordinate(lat, lon, value float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: lat != 0 || lon != 0}
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// DisruptionRepository implements repository.DisruptionRepository interface for PostgreSQL
+type DisruptionRepository struct {
+	db *Database
+}
+
+// NewDisruptionRepository creates a new disruption repository
+func NewDisruptionRepository(db *Database) repository.DisruptionRepository {
+	return &DisruptionRepository{db: db}
+}
+
+// Save stores a disruption; the (booking_id, passenger_id, segment_id, type) key
+// rejects a disruption recorded twice
+func (r *DisruptionRepository) Save(ctx context.Context, disruption *domain.Disruption) error {
+	const query = `
+		INSERT INTO disruptions (
+			id, booking_id, passenger_id, segment_id, booked_segment_id, type, delay,
+			connection, replaces, from_city, from_city_id, to_city, to_city_id,
+			depart_after, alternatives, status, change_id, detected_at, notified_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+		)
+	`
+
+	var connection interface{}
+	if disruption.Connection != nil {
+		data, err := json.Marshal(disruption.Connection)
+		if err != nil {
+			return fmt.Errorf("error encoding disrupted connection: %w", err)
+		}
+		connection = data
+	}
+
+	alternatives, err := json.Marshal(disruption.Alternatives)
+	if err != nil {
+		return fmt.Errorf("error encoding alternatives: %w", err)
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
+		disruption.ID,
+		disruption.BookingID,
+		disruption.PassengerID,
+		disruption.SegmentID,
+		disruption.BookedSegmentID,
+		disruption.Type,
+		disruption.Delay.Nanoseconds(),
+		connection,
+		pq.Array(disruption.Replaces),
+		disruption.FromCity,
+		nullString(disruption.FromCityID),
+		disruption.ToCity,
+		nullString(disruption.ToCityID),
+		disruption.DepartAfter,
+		alternatives,
+		disruption.Status,
+		nullString(disruption.ChangeID),
+		disruption.DetectedAt,
+		disruption.NotifiedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving disruption: %w", err)
+	}
+
+	return nil
+}
+
+// FindByBooking retrieves the disruptions of a booking, oldest first
+func (r *DisruptionRepository) FindByBooking(ctx context.Context, bookingID string) ([]domain.Disruption, error) {
+	const query = `
+		SELECT id, booking_id, passenger_id, segment_id, booked_segment_id, type, delay,
+		       connection, replaces, from_city, from_city_id, to_city, to_city_id,
+		       depart_after, alternatives, status, change_id, detected_at, notified_at
+		FROM disruptions
+		WHERE booking_id = $1
+		ORDER BY detected_at
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying disruptions: %w", err)
+	}
+	defer rows.Close()
+
+	disruptions := make([]domain.Disruption, 0)
+	for rows.Next() {
+		var disruption domain.Disruption
+		var delayNs int64
+		var connection, alternatives []byte
+		var fromCityID, toCityID, changeID sql.NullString
+		var notifiedAt sql.NullTime
+
+		if err := rows.Scan(
+			&disruption.ID,
+			&disruption.BookingID,
+			&disruption.PassengerID,
+			&disruption.SegmentID,
+			&disruption.BookedSegmentID,
+			&disruption.Type,
+			&delayNs,
+			&connection,
+			pq.Array(&disruption.Replaces),
+			&disruption.FromCity,
+			&fromCityID,
+			&disruption.ToCity,
+			&toCityID,
+			&disruption.DepartAfter,
+			&alternatives,
+			&disruption.Status,
+			&changeID,
+			&disruption.DetectedAt,
+			&notifiedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning disruption: %w", err)
+		}
+
+		if len(connection) > 0 {
+			disruption.Connection = &domain.Connection{}
+			if err := json.Unmarshal(connection, disruption.Connection); err != nil {
+				return nil, fmt.Errorf("error decoding disrupted connection: %w", err)
+			}
+		}
+		if err := json.Unmarshal(alternatives, &disruption.Alternatives); err != nil {
+			return nil, fmt.Errorf("error decoding alternatives: %w", err)
+		}
+		disruption.Delay = time.Duration(delayNs)
+		disruption.FromCityID = fromCityID.String
+		disruption.ToCityID = toCityID.String
+		disruption.ChangeID = changeID.String
+		if notifiedAt.Valid {
+			disruption.NotifiedAt = &notifiedAt.Time
+		}
+
+		disruptions = append(disruptions, disruption)
+	}
+
+	return disruptions, rows.Err()
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// FareRuleRepository implements repository.FareRuleRepository interface for PostgreSQL
+type FareRuleRepository struct {
+	db *Database
+}
+
+// NewFareRuleRepository creates a new fare rule repository
+func NewFareRuleRepository(db *Database) repository.FareRuleRepository {
+	return &FareRuleRepository{db: db}
+}
+
+// fareTier is the JSONB form of a penalty tier (hours are easier to maintain by hand)
+type fareTier struct {
+	BeforeHours float64 `json:"before_hours"`
+	Penalty     float64 `json:"penalty"`
+	Fee         float64 `json:"fee"`
+}
+
+// FindAll retrieves every provider fare rule
+func (r *FareRuleRepository) FindAll(ctx context.Context) ([]domain.FareRule, error) {
+	const query = `
+		SELECT id, provider, tariff, refundable, tiers, changeable, change_fee, source, updated_at
+		FROM fare_rules
+		ORDER BY provider, tariff
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fare rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []domain.FareRule
+	for rows.Next() {
+		var rule domain.FareRule
+		var tiers []byte
+
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.Provider,
+			&rule.Tariff,
+			&rule.Refundable,
+			&tiers,
+			&rule.Changeable,
+			&rule.ChangeFee,
+			&rule.Source,
+			&rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning fare rule: %w", err)
+		}
+
+		if rule.Tiers, err = decodeFareTiers(tiers); err != nil {
+			return nil, fmt.Errorf("fare rule %s: %w", rule.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// Upsert inserts or replaces the rule of a provider tariff
+func (r *FareRuleRepository) Upsert(ctx context.Context, rule *domain.FareRule) error {
+	const query = `
+		INSERT INTO fare_rules (id, provider, tariff, refundable, tiers, changeable, change_fee, source, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (provider, tariff) DO UPDATE SET
+			refundable = EXCLUDED.refundable,
+			tiers = EXCLUDED.tiers,
+			changeable = EXCLUDED.changeable,
+			change_fee = EXCLUDED.change_fee,
+			source = EXCLUDED.source,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	tiers, err := encodeFareTiers(rule.Tiers)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.Provider,
+		rule.Tariff,
+		rule.Refundable,
+		tiers,
+		rule.Changeable,
+		rule.ChangeFee,
+		rule.Source,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving fare rule: %w", err)
+	}
+
+	return nil
+}
+
+func encodeFareTiers(tiers []domain.PenaltyTier) ([]byte, error) {
+	rows := make([]fareTier, len(tiers))
+	for i, tier := range tiers {
+		rows[i] = fareTier{BeforeHours: tier.Before.Hours(), Penalty: tier.Penalty, Fee: tier.Fee.Major()}
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding fare tiers: %w", err)
+	}
+	return data, nil
+}
+
+func decodeFareTiers(data []byte) ([]domain.PenaltyTier, error) {
+	var rows []fareTier
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("error decoding fare tiers: %w", err)
+	}
+
+	tiers := make([]domain.PenaltyTier, len(rows))
+	for i, row := range rows {
+		tiers[i] = domain.PenaltyTier{
+			Before:  time.Duration(row.BeforeHours * float64(time.Hour)),
+			Penalty: row.Penalty,
+			Fee:     domain.MoneyFromFloat(row.Fee, domain.SettlementCurrency),
+		}
+	}
+	return tiers, nil
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// InsuranceClaimRepository implements repository.InsuranceClaimRepository interface for PostgreSQL
+type InsuranceClaimRepository struct {
+	db *Database
+}
+
+// NewInsuranceClaimRepository creates a new insurance claim repository
+func NewInsuranceClaimRepository(db *Database) repository.InsuranceClaimRepository {
+	return &InsuranceClaimRepository{db: db}
+}
+
+// Save stores a claim; a unique index on approved claims rejects paying a ticket out twice,
+// even when two claims for it are made at once
+func (r *InsuranceClaimRepository) Save(ctx context.Context, claim *domain.InsuranceClaim) error {
+	const query = `
+		INSERT INTO insurance_claims (
+			id, booking_id, policy_number, type, passenger_id, booked_segment_id,
+			inbound_ticket_id, scheduled_arrival, actual_arrival, delay, connection,
+			status, payout, currency, rejection_reason, provider_claim_id, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+		ON CONFLICT (booking_id, booked_segment_id) WHERE status IN ('pending', 'paid') DO NOTHING
+	`
+
+	var connection interface{}
+	if claim.Connection != nil {
+		data, err := json.Marshal(claim.Connection)
+		if err != nil {
+			return fmt.Errorf("error encoding claimed connection: %w", err)
+		}
+		connection = data
+	}
+
+	result, err := r.db.db.ExecContext(ctx, query,
+		claim.ID,
+		claim.BookingID,
+		claim.PolicyNumber,
+		claim.Type,
+		claim.PassengerID,
+		claim.BookedSegmentID,
+		claim.InboundTicketID,
+		claim.ScheduledArrival,
+		claim.ActualArrival,
+		claim.Delay.Nanoseconds(),
+		connection,
+		claim.Status,
+		claim.Payout,
+		string(claim.Payout.Currency),
+		nullString(claim.RejectionReason),
+		nullString(claim.ProviderClaimID),
+		claim.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving insurance claim: %w", err)
+	}
+
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error saving insurance claim: %w", err)
+	}
+	if saved == 0 {
+		return domain.NewDomainError("CLAIM_NOT_ALLOWED", "Claim for this ticket was made already")
+	}
+
+	return nil
+}
+
+// Update stores the outcome of a claim
+func (r *InsuranceClaimRepository) Update(ctx context.Context, claim *domain.InsuranceClaim) error {
+	const query = `
+		UPDATE insurance_claims
+		SET status = $2, payout = $3, currency = $4, rejection_reason = $5, provider_claim_id = $6
+		WHERE id = $1
+	`
+
+	_, err := r.db.db.ExecContext(ctx, query,
+		claim.ID,
+		claim.Status,
+		claim.Payout,
+		string(claim.Payout.Currency),
+		nullString(claim.RejectionReason),
+		nullString(claim.ProviderClaimID),
+	)
+	if err != nil {
+		return fmt.Errorf("error updating insurance claim: %w", err)
+	}
+
+	return nil
+}
+
+// FindByBooking retrieves the insurance claims of a booking, oldest first
+func (r *InsuranceClaimRepository) FindByBooking(ctx context.Context, bookingID string) ([]domain.InsuranceClaim, error) {
+	const query = `
+		SELECT id, booking_id, policy_number, type, passenger_id, booked_segment_id,
+		       inbound_ticket_id, scheduled_arrival, actual_arrival, delay, connection,
+		       status, currency, payout, rejection_reason, provider_claim_id, created_at
+		FROM insurance_claims
+		WHERE booking_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying insurance claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims := make([]domain.InsuranceClaim, 0)
+	for rows.Next() {
+		var claim domain.InsuranceClaim
+		var delayNs int64
+		var connection []byte
+		var rejectionReason, providerClaimID sql.NullString
+
+		if err := rows.Scan(
+			&claim.ID,
+			&claim.BookingID,
+			&claim.PolicyNumber,
+			&claim.Type,
+			&claim.PassengerID,
+			&claim.BookedSegmentID,
+			&claim.InboundTicketID,
+			&claim.ScheduledArrival,
+			&claim.ActualArrival,
+			&delayNs,
+			&connection,
+			&claim.Status,
+			&claim.Payout.Currency, // Scanned before the payout it belongs to
+			&claim.Payout,
+			&rejectionReason,
+			&providerClaimID,
+			&claim.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning insurance claim: %w", err)
+		}
+
+		if len(connection) > 0 {
+			claim.Connection = &domain.Connection{}
+			if err := json.Unmarshal(connection, claim.Connection); err != nil {
+				return nil, fmt.Errorf("error decoding claimed connection: %w", err)
+			}
+		}
+		claim.Delay = time.Duration(delayNs)
+		claim.RejectionReason = rejectionReason.String
+		claim.ProviderClaimID = providerClaimID.String
+
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
@@ -126,32 +126,46 @@ func (r *RouteRepository) FindAll(ctx context.Context) ([]domain.Route, error) {
 	return routes, rows.Err()
 }
 
+// cityNamesQuery selects every lowercased spelling of the city whose ID is the given parameter
+const cityNamesQuery = `
+	SELECT LOWER(v.name) FROM cities c,
+	LATERAL (VALUES (c.name), (c.name_en), (c.iata_code)) AS v(name)
+	WHERE c.id = %[1]s AND v.name IS NOT NULL
+	UNION
+	SELECT LOWER(alias) FROM city_aliases WHERE city_id = %[1]s`
+
 // FindByCriteria searches routes by search criteria
+// Saved routes match any known spelling of the resolved cities. Routes saved only
+// because a live search result was booked have no segments of their own and are left out.
 func (r *RouteRepository) FindByCriteria(ctx context.Context, criteria *domain.RouteSearchCriteria) ([]domain.Route, error) {
 	query := `
 		SELECT id, from_city, to_city, departure_time, arrival_time,
 		       total_duration, total_price, reliability_score,
 		       insurance_premium, insurance_included, transport_types, saved_at
 		FROM routes
-		WHERE from_city = $1 AND to_city = $2
+		WHERE (from_city = $1 OR LOWER(from_city) IN (` + fmt.Sprintf(cityNamesQuery, "$4") + `))
+		AND (to_city = $2 OR LOWER(to_city) IN (` + fmt.Sprintf(cityNamesQuery, "$5") + `))
 		AND DATE(departure_time) = $3
+		AND EXISTS (SELECT 1 FROM segments s WHERE s.route_id = routes.id)
 	`
 
 	args := []interface{}{
 		criteria.FromCity,
 		criteria.ToCity,
 		criteria.DepartureDate,
+		criteria.FromCityID,
+		criteria.ToCityID,
 	}
 
 	// Add optional filters
 	if criteria.BudgetMax > 0 {
-		query += ` AND total_price <= $4`
 		args = append(args, criteria.BudgetMax)
+		query += fmt.Sprintf(` AND total_price <= $%d`, len(args))
 	}
 
 	if criteria.BudgetMin > 0 {
-		query += ` AND total_price >= $5`
 		args = append(args, criteria.BudgetMin)
+		query += fmt.Sprintf(` AND total_price >= $%d`, len(args))
 	}
 
 	query += ` ORDER BY reliability_score DESC, total_price ASC`
@@ -301,8 +315,9 @@ func (r *RouteRepository) fetchSegments(ctx context.Context, route *domain.Route
 		       s.start_stop_id, ss.name, ss.city, ss.latitude, ss.longitude,
 		       s.end_stop_id, es.name, es.city, es.latitude, es.longitude,
 		       s.departure_time, s.arrival_time,
-		       s.price, s.duration, s.seat_count,
-		       s.reliability_rate, s.distance
+		       s.currency, s.price, s.duration, s.seat_count,
+		       s.reliability_rate, s.distance,
+		       COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status
 		FROM segments s
 		JOIN stops ss ON s.start_stop_id = ss.id
 		JOIN stops es ON s.end_stop_id = es.id
@@ -335,11 +350,15 @@ func (r *RouteRepository) fetchSegments(ctx context.Context, route *domain.Route
 			&segment.EndStop.Longitude,
 			&segment.DepartureTime,
 			&segment.ArrivalTime,
+			&segment.Price.Currency, // Scanned before the price it belongs to
 			&segment.Price,
 			&segment.Duration,
 			&segment.SeatCount,
 			&segment.ReliabilityRate,
 			&segment.Distance,
+			&segment.Source,
+			&segment.Tariff,
+			&segment.Status,
 		); err != nil {
 			return fmt.Errorf("error scanning segment: %w", err)
 		}
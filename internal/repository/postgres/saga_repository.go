@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// SagaRepository implements repository.SagaRepository interface for PostgreSQL
+type SagaRepository struct {
+	db *Database
+}
+
+// NewSagaRepository creates a new saga repository
+func NewSagaRepository(db *Database) repository.SagaRepository {
+	return &SagaRepository{db: db}
+}
+
+// Save stores a new saga
+func (r *SagaRepository) Save(ctx context.Context, saga *domain.BookingSaga) error {
+	const query = `
+		INSERT INTO booking_sagas (id, booking_id, status, last_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.db.ExecContext(ctx, query,
+		saga.ID,
+		saga.BookingID,
+		saga.Status,
+		nullString(saga.LastError),
+		saga.CreatedAt,
+		saga.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving saga: %w", err)
+	}
+
+	return nil
+}
+
+// SaveStep inserts or updates a saga step and touches the saga's updated_at
+func (r *SagaRepository) SaveStep(ctx context.Context, step *domain.SagaStep) error {
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const stepQuery = `
+		INSERT INTO booking_saga_steps (
+			id, saga_id, sequence_order, step_type, segment_id, passenger_id,
+			status, reference, error, attempts, next_attempt_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			reference = EXCLUDED.reference,
+			error = EXCLUDED.error,
+			attempts = EXCLUDED.attempts,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = tx.ExecContext(ctx, stepQuery,
+		step.ID,
+		step.SagaID,
+		step.Sequence,
+		step.Type,
+		nullString(step.SegmentID),
+		nullString(step.PassengerID),
+		step.Status,
+		nullString(step.Reference),
+		nullString(step.Error),
+		step.Attempts,
+		step.NextAttemptAt,
+		step.CreatedAt,
+		step.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving saga step: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE booking_sagas SET updated_at = $2 WHERE id = $1`, step.SagaID, step.UpdatedAt); err != nil {
+		return fmt.Errorf("error touching saga: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus updates the saga status and last error
+func (r *SagaRepository) UpdateStatus(ctx context.Context, saga *domain.BookingSaga) error {
+	const query = `
+		UPDATE booking_sagas
+		SET status = $2, last_error = $3, updated_at = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.db.ExecContext(ctx, query, saga.ID, saga.Status, nullString(saga.LastError), saga.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error updating saga: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("saga not found: %s", saga.ID)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a saga with its steps
+func (r *SagaRepository) FindByID(ctx context.Context, id string) (*domain.BookingSaga, error) {
+	sagas, err := r.find(ctx, `WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sagas) == 0 {
+		return nil, fmt.Errorf("saga not found: %s", id)
+	}
+	return &sagas[0], nil
+}
+
+// FindByStatus retrieves sagas in any of the given statuses, oldest first
+func (r *SagaRepository) FindByStatus(ctx context.Context, statuses ...domain.SagaStatus) ([]domain.BookingSaga, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	return r.find(ctx, `WHERE status = ANY($1)`, pq.Array(values))
+}
+
+// find loads sagas matching the where clause together with their steps
+func (r *SagaRepository) find(ctx context.Context, where string, args ...interface{}) ([]domain.BookingSaga, error) {
+	query := `
+		SELECT id, booking_id, status, COALESCE(last_error, ''), created_at, updated_at
+		FROM booking_sagas
+		` + where + `
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var sagas []domain.BookingSaga
+	for rows.Next() {
+		var saga domain.BookingSaga
+		if err := rows.Scan(&saga.ID, &saga.BookingID, &saga.Status, &saga.LastError, &saga.CreatedAt, &saga.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning saga: %w", err)
+		}
+		sagas = append(sagas, saga)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range sagas {
+		if err := r.fetchSteps(ctx, &sagas[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return sagas, nil
+}
+
+// fetchSteps loads the steps of a saga in execution order
+func (r *SagaRepository) fetchSteps(ctx context.Context, saga *domain.BookingSaga) error {
+	const query = `
+		SELECT id, saga_id, sequence_order, step_type, COALESCE(segment_id, ''), COALESCE(passenger_id, ''),
+		       status, COALESCE(reference, ''), COALESCE(error, ''), attempts, next_attempt_at, created_at, updated_at
+		FROM booking_saga_steps
+		WHERE saga_id = $1
+		ORDER BY sequence_order
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, saga.ID)
+	if err != nil {
+		return fmt.Errorf("error querying saga steps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var step domain.SagaStep
+		var nextAttemptAt sql.NullTime
+		err := rows.Scan(
+			&step.ID,
+			&step.SagaID,
+			&step.Sequence,
+			&step.Type,
+			&step.SegmentID,
+			&step.PassengerID,
+			&step.Status,
+			&step.Reference,
+			&step.Error,
+			&step.Attempts,
+			&nextAttemptAt,
+			&step.CreatedAt,
+			&step.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning saga step: %w", err)
+		}
+		if nextAttemptAt.Valid {
+			step.NextAttemptAt = &nextAttemptAt.Time
+		}
+		saga.Steps = append(saga.Steps, step)
+	}
+
+	return rows.Err()
+}
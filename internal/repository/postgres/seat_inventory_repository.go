@@ -0,0 +1,321 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// SeatInventoryRepository implements repository.SeatInventoryRepository interface for PostgreSQL
+type SeatInventoryRepository struct {
+	db *Database
+}
+
+// NewSeatInventoryRepository creates a new seat inventory repository
+func NewSeatInventoryRepository(db *Database) repository.SeatInventoryRepository {
+	return &SeatInventoryRepository{db: db}
+}
+
+// Hold places all holds in one transaction
+// Segment rows are locked in ID order so concurrent bookings of overlapping routes cannot deadlock.
+func (r *SeatInventoryRepository) Hold(ctx context.Context, holds []domain.SeatHold) error {
+	if len(holds) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	requested := make(map[string]int)
+	for _, hold := range holds {
+		requested[hold.SegmentID] += hold.Seats
+	}
+
+	segmentIDs := make([]string, 0, len(requested))
+	for id := range requested {
+		segmentIDs = append(segmentIDs, id)
+	}
+	sort.Strings(segmentIDs)
+
+	for _, segmentID := range segmentIDs {
+		var seatCount int
+		err := tx.QueryRowContext(ctx, `SELECT seat_count FROM segments WHERE id = $1 FOR UPDATE`, segmentID).Scan(&seatCount)
+		if err == sql.ErrNoRows {
+			return domain.ErrSegmentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("error locking segment %s: %w", segmentID, err)
+		}
+
+		taken, err := unavailableSeats(ctx, tx, segmentID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if available := seatCount - taken; available < requested[segmentID] {
+			return domain.NewSeatsUnavailableError(segmentID, requested[segmentID], max(available, 0))
+		}
+	}
+
+	const query = `
+		INSERT INTO seat_holds (id, segment_id, booking_id, seats, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for _, hold := range holds {
+		_, err := tx.ExecContext(ctx, query,
+			hold.ID,
+			hold.SegmentID,
+			hold.BookingID,
+			hold.Seats,
+			hold.Status,
+			hold.ExpiresAt,
+			hold.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving seat hold: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Available returns the provider's free seats on a segment, minus active holds and
+// seats we sold that the provider does not reflect yet
+func (r *SeatInventoryRepository) Available(ctx context.Context, segmentID string) (int, error) {
+	var seatCount int
+	err := r.db.db.QueryRowContext(ctx, `SELECT seat_count FROM segments WHERE id = $1`, segmentID).Scan(&seatCount)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrSegmentNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error querying segment seats: %w", err)
+	}
+
+	taken, err := unavailableSeats(ctx, r.db.db, segmentID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return max(seatCount-taken, 0), nil
+}
+
+// ConfirmByBooking turns a booking's holds into sold seats
+// Active holds are confirmed as they are. Holds that expired before payment arrived may
+// have been sold to someone else since, so they are confirmed only if their segments
+// still have the seats; otherwise it fails with SEATS_UNAVAILABLE.
+// segments.seat_count is left alone: it is the provider's figure, overwritten by every
+// sync, and confirmed holds count against it until the provider reflects them.
+func (r *SeatInventoryRepository) ConfirmByBooking(ctx context.Context, bookingID string) error {
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	const confirmQuery = `
+		UPDATE seat_holds SET status = 'confirmed', confirmed_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE booking_id = $1 AND status = $2
+	`
+	if _, err := tx.ExecContext(ctx, confirmQuery, bookingID, domain.SeatHoldActive, now); err != nil {
+		return fmt.Errorf("error confirming seat holds: %w", err)
+	}
+
+	const expiredQuery = `
+		SELECT segment_id, seats FROM seat_holds
+		WHERE booking_id = $1 AND status = 'expired'
+	`
+	expired, err := collectSeats(tx.QueryContext(ctx, expiredQuery, bookingID))
+	if err != nil {
+		return fmt.Errorf("error querying expired seat holds: %w", err)
+	}
+	if len(expired) == 0 {
+		return tx.Commit()
+	}
+
+	segmentIDs := make([]string, 0, len(expired))
+	for id := range expired {
+		segmentIDs = append(segmentIDs, id)
+	}
+	sort.Strings(segmentIDs)
+
+	for _, segmentID := range segmentIDs {
+		var seatCount int
+		err := tx.QueryRowContext(ctx, `SELECT seat_count FROM segments WHERE id = $1 FOR UPDATE`, segmentID).Scan(&seatCount)
+		if err == sql.ErrNoRows {
+			return domain.ErrSegmentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("error locking segment %s: %w", segmentID, err)
+		}
+
+		taken, err := unavailableSeats(ctx, tx, segmentID, now)
+		if err != nil {
+			return err
+		}
+
+		if available := seatCount - taken; available < expired[segmentID] {
+			return domain.NewSeatsUnavailableError(segmentID, expired[segmentID], max(available, 0))
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, confirmQuery, bookingID, domain.SeatHoldExpired, now); err != nil {
+		return fmt.Errorf("error confirming expired seat holds: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReleaseByBooking releases a booking's holds, including the seats it had confirmed
+func (r *SeatInventoryRepository) ReleaseByBooking(ctx context.Context, bookingID string) error {
+	// Confirmed holds stop counting against the provider's seats; once the provider has
+	// reflected the sale, the seats come back with its next sync
+	const releaseQuery = `
+		UPDATE seat_holds SET status = 'released', updated_at = CURRENT_TIMESTAMP
+		WHERE booking_id = $1 AND status IN ('active', 'expired', 'confirmed')
+	`
+	if _, err := r.db.db.ExecContext(ctx, releaseQuery, bookingID); err != nil {
+		return fmt.Errorf("error releasing seat holds: %w", err)
+	}
+
+	return nil
+}
+
+// ReturnSeats gives back sold seats of a booking on one segment
+// The confirmed hold shrinks, and is released once it holds no seats.
+func (r *SeatInventoryRepository) ReturnSeats(ctx context.Context, bookingID, segmentID string, seats int) error {
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A booking change can leave several confirmed holds on a segment; shrink the largest
+	var holdID string
+	var held int
+	const holdQuery = `
+		SELECT id, seats FROM seat_holds
+		WHERE booking_id = $1 AND segment_id = $2 AND status = 'confirmed'
+		ORDER BY seats DESC
+		LIMIT 1
+		FOR UPDATE
+	`
+	err = tx.QueryRowContext(ctx, holdQuery, bookingID, segmentID).Scan(&holdID, &held)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error querying seat hold: %w", err)
+	}
+
+	returned := min(seats, held)
+	if returned == held {
+		const releaseQuery = `
+			UPDATE seat_holds SET status = 'released', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		_, err = tx.ExecContext(ctx, releaseQuery, holdID)
+	} else {
+		const shrinkQuery = `
+			UPDATE seat_holds SET seats = seats - $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		_, err = tx.ExecContext(ctx, shrinkQuery, holdID, returned)
+	}
+	if err != nil {
+		return fmt.Errorf("error returning seats: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReleaseHolds releases the given holds if they are not confirmed yet
+func (r *SeatInventoryRepository) ReleaseHolds(ctx context.Context, holdIDs []string) error {
+	if len(holdIDs) == 0 {
+		return nil
+	}
+
+	const query = `
+		UPDATE seat_holds SET status = 'released', updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1) AND status IN ('active', 'expired')
+	`
+
+	if _, err := r.db.db.ExecContext(ctx, query, pq.Array(holdIDs)); err != nil {
+		return fmt.Errorf("error releasing seat holds: %w", err)
+	}
+	return nil
+}
+
+// ReleaseExpired marks holds past their expiry as expired
+func (r *SeatInventoryRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	const query = `
+		UPDATE seat_holds SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'active' AND expires_at <= $1
+	`
+
+	result, err := r.db.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("error expiring seat holds: %w", err)
+	}
+
+	released, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	return int(released), nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// unavailableSeats sums the seats of a segment that the provider's seat_count still
+// offers but that are not free: active holds, and holds confirmed after the last sync
+func unavailableSeats(ctx context.Context, q queryer, segmentID string, now time.Time) (int, error) {
+	const query = `
+		SELECT COALESCE(SUM(h.seats), 0)
+		FROM seat_holds h
+		JOIN segments s ON s.id = h.segment_id
+		WHERE h.segment_id = $1
+		  AND ((h.status = 'active' AND h.expires_at > $2)
+		    OR (h.status = 'confirmed' AND h.confirmed_at > s.seats_synced_at))
+	`
+
+	var taken int
+	if err := q.QueryRowContext(ctx, query, segmentID, now).Scan(&taken); err != nil {
+		return 0, fmt.Errorf("error querying unavailable seats: %w", err)
+	}
+	return taken, nil
+}
+
+// collectSeats sums (segment_id, seats) rows per segment
+func collectSeats(rows *sql.Rows, err error) (map[string]int, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := make(map[string]int)
+	for rows.Next() {
+		var segmentID string
+		var n int
+		if err := rows.Scan(&segmentID, &n); err != nil {
+			return nil, err
+		}
+		seats[segmentID] += n
+	}
+	return seats, rows.Err()
+}
@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/internal/repository"
 )
@@ -26,12 +29,18 @@ func (r *SegmentRepository) Save(ctx context.Context, segment *domain.Segment) e
 		INSERT INTO segments (
 			id, route_id, transport_type, provider,
 			start_stop_id, end_stop_id, departure_time, arrival_time,
-			price, duration, seat_count, reliability_rate, distance, sequence_order
+			price, duration, seat_count, reliability_rate, distance, sequence_order, season,
+			source, tariff, status, currency
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
-	_, err := r.db.db.ExecContext(ctx, query,
+	season, err := encodeSeason(segment.Season)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
 		segment.ID,
 		nil, // route_id is NULL for standalone segments
 		segment.TransportType,
@@ -46,6 +55,11 @@ func (r *SegmentRepository) Save(ctx context.Context, segment *domain.Segment) e
 		segment.ReliabilityRate,
 		segment.Distance,
 		nil, // sequence_order is NULL for standalone segments
+		season,
+		nullString(segment.Source),
+		nullString(segment.Tariff),
+		segmentStatus(segment),
+		priceCurrency(segment.Price),
 	)
 
 	if err != nil {
@@ -71,14 +85,20 @@ func (r *SegmentRepository) BatchSave(ctx context.Context, segments []domain.Seg
 		INSERT INTO segments (
 			id, route_id, transport_type, provider,
 			start_stop_id, end_stop_id, departure_time, arrival_time,
-			price, duration, seat_count, reliability_rate, distance, sequence_order
+			price, duration, seat_count, reliability_rate, distance, sequence_order, season,
+			source, tariff, status, currency
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		ON CONFLICT (id) DO UPDATE SET
 			departure_time = EXCLUDED.departure_time,
 			arrival_time = EXCLUDED.arrival_time,
 			price = EXCLUDED.price,
-			seat_count = EXCLUDED.seat_count
+			currency = EXCLUDED.currency,
+			seat_count = EXCLUDED.seat_count,
+			seats_synced_at = CURRENT_TIMESTAMP,
+			season = EXCLUDED.season,
+			tariff = EXCLUDED.tariff,
+			status = EXCLUDED.status
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -88,7 +108,12 @@ func (r *SegmentRepository) BatchSave(ctx context.Context, segments []domain.Seg
 	defer stmt.Close()
 
 	for _, segment := range segments {
-		_, err := stmt.ExecContext(ctx,
+		season, err := encodeSeason(segment.Season)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx,
 			segment.ID,
 			nil, // route_id is NULL for standalone segments
 			segment.TransportType,
@@ -103,6 +128,11 @@ func (r *SegmentRepository) BatchSave(ctx context.Context, segments []domain.Seg
 			segment.ReliabilityRate,
 			segment.Distance,
 			nil, // sequence_order is NULL for standalone segments
+			season,
+			nullString(segment.Source),
+			nullString(segment.Tariff),
+			segmentStatus(&segment),
+			priceCurrency(segment.Price),
 		)
 		if err != nil {
 			return fmt.Errorf("error executing batch insert: %w", err)
@@ -121,10 +151,11 @@ func (r *SegmentRepository) FindByID(ctx context.Context, id string) (*domain.Se
 	const query = `
 		SELECT
 			s.id, s.transport_type, s.provider,
-			s.departure_time, s.arrival_time, s.price, s.duration,
-			s.seat_count, s.reliability_rate, s.distance,
-			start.id, start.name, start.city, start.latitude, start.longitude,
-			end_stop.id, end_stop.name, end_stop.city, end_stop.latitude, end_stop.longitude
+			s.departure_time, s.arrival_time, s.currency, s.price, s.duration,
+			s.seat_count, s.reliability_rate, s.distance, s.season,
+			COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status,
+			start.id, start.name, start.city, COALESCE(start.city_id, ''), start.latitude, start.longitude, start.season,
+			end_stop.id, end_stop.name, end_stop.city, COALESCE(end_stop.city_id, ''), end_stop.latitude, end_stop.longitude, end_stop.season
 		FROM segments s
 		JOIN stops start ON s.start_stop_id = start.id
 		JOIN stops end_stop ON s.end_stop_id = end_stop.id
@@ -133,6 +164,7 @@ func (r *SegmentRepository) FindByID(ctx context.Context, id string) (*domain.Se
 
 	var segment domain.Segment
 	var durationNs int64
+	var seasons [3][]byte
 
 	err := r.db.db.QueryRowContext(ctx, query, id).Scan(
 		&segment.ID,
@@ -140,31 +172,43 @@ func (r *SegmentRepository) FindByID(ctx context.Context, id string) (*domain.Se
 		&segment.Provider,
 		&segment.DepartureTime,
 		&segment.ArrivalTime,
+		&segment.Price.Currency, // Scanned before the price it belongs to
 		&segment.Price,
 		&durationNs,
 		&segment.SeatCount,
 		&segment.ReliabilityRate,
 		&segment.Distance,
+		&seasons[0],
+		&segment.Source,
+		&segment.Tariff,
+		&segment.Status,
 		&segment.StartStop.ID,
 		&segment.StartStop.Name,
 		&segment.StartStop.City,
+		&segment.StartStop.CityID,
 		&segment.StartStop.Latitude,
 		&segment.StartStop.Longitude,
+		&seasons[1],
 		&segment.EndStop.ID,
 		&segment.EndStop.Name,
 		&segment.EndStop.City,
+		&segment.EndStop.CityID,
 		&segment.EndStop.Latitude,
 		&segment.EndStop.Longitude,
+		&seasons[2],
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("segment not found: %s", id)
+			return nil, domain.ErrSegmentNotFound
 		}
 		return nil, fmt.Errorf("error querying segment: %w", err)
 	}
 
 	segment.Duration = time.Duration(durationNs)
+	if err := decodeSeasons(&segment, seasons); err != nil {
+		return nil, err
+	}
 
 	return &segment, nil
 }
@@ -174,15 +218,16 @@ func (r *SegmentRepository) FindByCriteria(ctx context.Context, fromCity, toCity
 	const query = `
 		SELECT
 			s.id, s.transport_type, s.provider,
-			s.departure_time, s.arrival_time, s.price, s.duration,
-			s.seat_count, s.reliability_rate, s.distance,
-			start.id, start.name, start.city, start.latitude, start.longitude,
-			end_stop.id, end_stop.name, end_stop.city, end_stop.latitude, end_stop.longitude
+			s.departure_time, s.arrival_time, s.currency, s.price, s.duration,
+			s.seat_count, s.reliability_rate, s.distance, s.season,
+			COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status,
+			start.id, start.name, start.city, COALESCE(start.city_id, ''), start.latitude, start.longitude, start.season,
+			end_stop.id, end_stop.name, end_stop.city, COALESCE(end_stop.city_id, ''), end_stop.latitude, end_stop.longitude, end_stop.season
 		FROM segments s
 		JOIN stops start ON s.start_stop_id = start.id
 		JOIN stops end_stop ON s.end_stop_id = end_stop.id
-		WHERE start.city = $1
-		  AND end_stop.city = $2
+		WHERE (start.city_id = $1 OR start.city = $1)
+		  AND (end_stop.city_id = $2 OR end_stop.city = $2)
 		  AND s.departure_time >= $3
 		  AND s.departure_time < $4
 		ORDER BY s.departure_time
@@ -194,41 +239,7 @@ func (r *SegmentRepository) FindByCriteria(ctx context.Context, fromCity, toCity
 	}
 	defer rows.Close()
 
-	var segments []domain.Segment
-	for rows.Next() {
-		var segment domain.Segment
-		var durationNs int64
-
-		if err := rows.Scan(
-			&segment.ID,
-			&segment.TransportType,
-			&segment.Provider,
-			&segment.DepartureTime,
-			&segment.ArrivalTime,
-			&segment.Price,
-			&durationNs,
-			&segment.SeatCount,
-			&segment.ReliabilityRate,
-			&segment.Distance,
-			&segment.StartStop.ID,
-			&segment.StartStop.Name,
-			&segment.StartStop.City,
-			&segment.StartStop.Latitude,
-			&segment.StartStop.Longitude,
-			&segment.EndStop.ID,
-			&segment.EndStop.Name,
-			&segment.EndStop.City,
-			&segment.EndStop.Latitude,
-			&segment.EndStop.Longitude,
-		); err != nil {
-			return nil, fmt.Errorf("error scanning segment: %w", err)
-		}
-
-		segment.Duration = time.Duration(durationNs)
-		segments = append(segments, segment)
-	}
-
-	return segments, rows.Err()
+	return scanSegments(rows)
 }
 
 // DeleteOldSegments removes segments older than specified date
@@ -259,10 +270,11 @@ func (r *SegmentRepository) FindAll(ctx context.Context) ([]domain.Segment, erro
 	const query = `
 		SELECT
 			s.id, s.transport_type, s.provider,
-			s.departure_time, s.arrival_time, s.price, s.duration,
-			s.seat_count, s.reliability_rate, s.distance,
-			start.id, start.name, start.city, start.latitude, start.longitude,
-			end_stop.id, end_stop.name, end_stop.city, end_stop.latitude, end_stop.longitude
+			s.departure_time, s.arrival_time, s.currency, s.price, s.duration,
+			s.seat_count, s.reliability_rate, s.distance, s.season,
+			COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status,
+			start.id, start.name, start.city, COALESCE(start.city_id, ''), start.latitude, start.longitude, start.season,
+			end_stop.id, end_stop.name, end_stop.city, COALESCE(end_stop.city_id, ''), end_stop.latitude, end_stop.longitude, end_stop.season
 		FROM segments s
 		JOIN stops start ON s.start_stop_id = start.id
 		JOIN stops end_stop ON s.end_stop_id = end_stop.id
@@ -276,10 +288,68 @@ func (r *SegmentRepository) FindAll(ctx context.Context) ([]domain.Segment, erro
 	}
 	defer rows.Close()
 
+	return scanSegments(rows)
+}
+
+// FindByIDs retrieves the segments with the given IDs; IDs not found are left out
+func (r *SegmentRepository) FindByIDs(ctx context.Context, ids []string) ([]domain.Segment, error) {
+	const query = `
+		SELECT
+			s.id, s.transport_type, s.provider,
+			s.departure_time, s.arrival_time, s.currency, s.price, s.duration,
+			s.seat_count, s.reliability_rate, s.distance, s.season,
+			COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status,
+			start.id, start.name, start.city, COALESCE(start.city_id, ''), start.latitude, start.longitude, start.season,
+			end_stop.id, end_stop.name, end_stop.city, COALESCE(end_stop.city_id, ''), end_stop.latitude, end_stop.longitude, end_stop.season
+		FROM segments s
+		JOIN stops start ON s.start_stop_id = start.id
+		JOIN stops end_stop ON s.end_stop_id = end_stop.id
+		WHERE s.id = ANY($1)
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error querying segments by IDs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSegments(rows)
+}
+
+// FindByDepartureWindow retrieves the segments departing in [departureStart, departureEnd)
+func (r *SegmentRepository) FindByDepartureWindow(ctx context.Context, departureStart, departureEnd time.Time) ([]domain.Segment, error) {
+	const query = `
+		SELECT
+			s.id, s.transport_type, s.provider,
+			s.departure_time, s.arrival_time, s.currency, s.price, s.duration,
+			s.seat_count, s.reliability_rate, s.distance, s.season,
+			COALESCE(s.source, ''), COALESCE(s.tariff, ''), s.status,
+			start.id, start.name, start.city, COALESCE(start.city_id, ''), start.latitude, start.longitude, start.season,
+			end_stop.id, end_stop.name, end_stop.city, COALESCE(end_stop.city_id, ''), end_stop.latitude, end_stop.longitude, end_stop.season
+		FROM segments s
+		JOIN stops start ON s.start_stop_id = start.id
+		JOIN stops end_stop ON s.end_stop_id = end_stop.id
+		WHERE s.departure_time >= $1
+		  AND s.departure_time < $2
+		ORDER BY s.departure_time
+	`
+
+	rows, err := r.db.db.QueryContext(ctx, query, departureStart, departureEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error querying segments by departure window: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSegments(rows)
+}
+
+// scanSegments reads segments joined with their start and end stops
+func scanSegments(rows *sql.Rows) ([]domain.Segment, error) {
 	var segments []domain.Segment
 	for rows.Next() {
 		var segment domain.Segment
 		var durationNs int64
+		var seasons [3][]byte
 
 		if err := rows.Scan(
 			&segment.ID,
@@ -287,28 +357,84 @@ func (r *SegmentRepository) FindAll(ctx context.Context) ([]domain.Segment, erro
 			&segment.Provider,
 			&segment.DepartureTime,
 			&segment.ArrivalTime,
+			&segment.Price.Currency, // Scanned before the price it belongs to
 			&segment.Price,
 			&durationNs,
 			&segment.SeatCount,
 			&segment.ReliabilityRate,
 			&segment.Distance,
+			&seasons[0],
+			&segment.Source,
+			&segment.Tariff,
+			&segment.Status,
 			&segment.StartStop.ID,
 			&segment.StartStop.Name,
 			&segment.StartStop.City,
+			&segment.StartStop.CityID,
 			&segment.StartStop.Latitude,
 			&segment.StartStop.Longitude,
+			&seasons[1],
 			&segment.EndStop.ID,
 			&segment.EndStop.Name,
 			&segment.EndStop.City,
+			&segment.EndStop.CityID,
 			&segment.EndStop.Latitude,
 			&segment.EndStop.Longitude,
+			&seasons[2],
 		); err != nil {
 			return nil, fmt.Errorf("error scanning segment: %w", err)
 		}
 
 		segment.Duration = time.Duration(durationNs)
+		if err := decodeSeasons(&segment, seasons); err != nil {
+			return nil, err
+		}
 		segments = append(segments, segment)
 	}
 
 	return segments, rows.Err()
 }
+
+// segmentStatus returns the status to store; synced segments without one are scheduled
+func segmentStatus(segment *domain.Segment) domain.SegmentStatus {
+	if segment.Status == "" {
+		return domain.SegmentScheduled
+	}
+	return segment.Status
+}
+
+// priceCurrency returns the currency of a price to store; prices without one are in the settlement currency
+func priceCurrency(price domain.Money) domain.Currency {
+	if price.Currency == "" {
+		return domain.SettlementCurrency
+	}
+	return price.Currency
+}
+
+// encodeSeason converts a season to JSONB (NULL when empty)
+func encodeSeason(season domain.Season) (interface{}, error) {
+	if len(season) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(season)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding season: %w", err)
+	}
+	return data, nil
+}
+
+// decodeSeasons fills the segment and stop seasons from JSONB columns
+func decodeSeasons(segment *domain.Segment, seasons [3][]byte) error {
+	targets := [3]*domain.Season{&segment.Season, &segment.StartStop.Season, &segment.EndStop.Season}
+
+	for i, data := range seasons {
+		if len(data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, targets[i]); err != nil {
+			return fmt.Errorf("error decoding season: %w", err)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/lenalink/backend/internal/domain"
 	"github.com/lenalink/backend/internal/repository"
@@ -22,19 +24,26 @@ func NewStopRepository(db *Database) repository.StopRepository {
 // Save stores a new stop
 func (r *StopRepository) Save(ctx context.Context, stop *domain.Stop) error {
 	const query = `
-		INSERT INTO stops (id, name, city, latitude, longitude, stop_type)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO stops (id, name, city, latitude, longitude, stop_type, season, city_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	stopType := r.inferStopType(stop.Name)
 
-	_, err := r.db.db.ExecContext(ctx, query,
+	season, err := encodeSeason(stop.Season)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
 		stop.ID,
 		stop.Name,
 		stop.City,
 		stop.Latitude,
 		stop.Longitude,
 		stopType,
+		season,
+		nullString(stop.CityID),
 	)
 
 	if err != nil {
@@ -47,24 +56,33 @@ func (r *StopRepository) Save(ctx context.Context, stop *domain.Stop) error {
 // Upsert inserts or updates a stop (by unique key name+city)
 func (r *StopRepository) Upsert(ctx context.Context, stop *domain.Stop) error {
 	const query = `
-		INSERT INTO stops (id, name, city, latitude, longitude, stop_type)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO stops (id, name, city, latitude, longitude, stop_type, season, city_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (name, city)
 		DO UPDATE SET
 			latitude = EXCLUDED.latitude,
 			longitude = EXCLUDED.longitude,
-			stop_type = EXCLUDED.stop_type
+			stop_type = EXCLUDED.stop_type,
+			season = COALESCE(EXCLUDED.season, stops.season),
+			city_id = COALESCE(EXCLUDED.city_id, stops.city_id)
 	`
 
 	stopType := r.inferStopType(stop.Name)
 
-	_, err := r.db.db.ExecContext(ctx, query,
+	season, err := encodeSeason(stop.Season)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, query,
 		stop.ID,
 		stop.Name,
 		stop.City,
 		stop.Latitude,
 		stop.Longitude,
 		stopType,
+		season,
+		nullString(stop.CityID),
 	)
 
 	if err != nil {
@@ -212,10 +230,94 @@ func (r *StopRepository) FindAll(ctx context.Context) ([]domain.Stop, error) {
 	return stops, rows.Err()
 }
 
+// Search finds stops whose name or city matches the query.
+// Full-text matches (with prefix matching on every word) are ranked by ts_rank;
+// if there are none, prefix and trigram similarity matches are used instead,
+// which handles typos and partially typed names.
+func (r *StopRepository) Search(ctx context.Context, query string, limit int) ([]domain.Stop, error) {
+	const fullTextQuery = `
+		SELECT id, name, city, latitude, longitude, COALESCE(stop_type, '')
+		FROM stops
+		WHERE search_vector @@ to_tsquery('russian', $1)
+		ORDER BY ts_rank(search_vector, to_tsquery('russian', $1)) DESC, city, name
+		LIMIT $2
+	`
+
+	const fuzzyQuery = `
+		SELECT id, name, city, latitude, longitude, COALESCE(stop_type, '')
+		FROM stops
+		WHERE name ILIKE $2 OR city ILIKE $2 OR name % $1 OR city % $1
+		ORDER BY GREATEST(similarity(name, $1), similarity(city, $1)) DESC, city, name
+		LIMIT $3
+	`
+
+	if tsQuery := prefixTSQuery(query); tsQuery != "" {
+		stops, err := r.searchStops(ctx, fullTextQuery, tsQuery, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(stops) > 0 {
+			return stops, nil
+		}
+	}
+
+	return r.searchStops(ctx, fuzzyQuery, query, likePrefix(query), limit)
+}
+
+// searchStops runs a stop search query and infers missing stop types
+func (r *StopRepository) searchStops(ctx context.Context, query string, args ...interface{}) ([]domain.Stop, error) {
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching stops: %w", err)
+	}
+	defer rows.Close()
+
+	var stops []domain.Stop
+	for rows.Next() {
+		var stop domain.Stop
+		if err := rows.Scan(
+			&stop.ID,
+			&stop.Name,
+			&stop.City,
+			&stop.Latitude,
+			&stop.Longitude,
+			&stop.Type,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning stop: %w", err)
+		}
+		if stop.Type == "" {
+			stop.Type = r.inferStopType(stop.Name)
+		}
+		stops = append(stops, stop)
+	}
+
+	return stops, rows.Err()
+}
+
+// prefixTSQuery converts user input to a tsquery matching every word as a prefix
+// ("якут аэро" -> "якут:* & аэро:*"); characters with special meaning are dropped
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(query, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// likePrefix builds an ILIKE prefix pattern with wildcards escaped
+func likePrefix(query string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query)
+	return escaped + "%"
+}
+
 // inferStopType infers stop type from name
 func (r *StopRepository) inferStopType(name string) string {
 	// Simple heuristic based on keywords in name
-	name = fmt.Sprintf("%s", name) // ensure lowercase for matching
+	name = strings.ToLower(name)
 
 	// Check for airport keywords
 	if containsAny(name, []string{"аэропорт", "airport", "авиа"}) {
@@ -239,28 +341,9 @@ func (r *StopRepository) inferStopType(name string) string {
 // containsAny checks if string contains any of the substrings
 func containsAny(s string, subs []string) bool {
 	for _, sub := range subs {
-		if len(s) >= len(sub) {
-			for i := 0; i <= len(s)-len(sub); i++ {
-				match := true
-				for j := 0; j < len(sub); j++ {
-					if toLower(s[i+j]) != toLower(sub[j]) {
-						match = false
-						break
-					}
-				}
-				if match {
-					return true
-				}
-			}
+		if strings.Contains(s, sub) {
+			return true
 		}
 	}
 	return false
 }
-
-// toLower converts byte to lowercase
-func toLower(b byte) byte {
-	if b >= 'A' && b <= 'Z' {
-		return b + 32
-	}
-	return b
-}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// WebhookEventRepository implements repository.WebhookEventRepository interface for PostgreSQL
+type WebhookEventRepository struct {
+	db *Database
+}
+
+// NewWebhookEventRepository creates a new webhook event repository
+func NewWebhookEventRepository(db *Database) repository.WebhookEventRepository {
+	return &WebhookEventRepository{db: db}
+}
+
+// Claim records an event; the primary key makes a concurrent duplicate lose the race
+func (r *WebhookEventRepository) Claim(ctx context.Context, event *domain.WebhookEvent) (bool, error) {
+	const query = `
+		INSERT INTO webhook_events (id, provider, event, object_id, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	result, err := r.db.db.ExecContext(ctx, query,
+		event.ID,
+		event.Provider,
+		event.Event,
+		event.ObjectID,
+		event.ReceivedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error claiming webhook event: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// Release deletes an event record
+func (r *WebhookEventRepository) Release(ctx context.Context, event *domain.WebhookEvent) error {
+	const query = `DELETE FROM webhook_events WHERE id = $1`
+
+	if _, err := r.db.db.ExecContext(ctx, query, event.ID); err != nil {
+		return fmt.Errorf("error releasing webhook event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package routing
+
+import "time"
+
+// Reference values used to bring price and time to a comparable scale
+const (
+	referencePrice   = 1000.0 // 1000 RUB
+	referenceMinutes = 60.0   // 1 hour
+)
+
+// WeightedCost blends price, time and transfers.
+// Price is measured in thousands of rubles and time in hours,
+// so weights like {"price": 0.6, "time": 0.4} are comparable.
+// The search itself is not weighted: it finds the Pareto-optimal journeys,
+// which are then ranked by their weighted cost.
+type WeightedCost struct {
+	Price     float64
+	Time      float64
+	Transfers float64
+}
+
+// Total returns the weighted cost of a whole journey
+func (c WeightedCost) Total(price float64, duration time.Duration, transfers int) float64 {
+	return c.Price*price/referencePrice +
+		c.Time*duration.Minutes()/referenceMinutes +
+		c.Transfers*float64(transfers)
+}
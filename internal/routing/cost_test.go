@@ -0,0 +1,28 @@
+package routing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeightedCostTotal(t *testing.T) {
+	// Fast and expensive flight against a slow and cheap bus and river journey
+	flight := func(c WeightedCost) float64 { return c.Total(20000, 2*time.Hour, 0) }
+	overland := func(c WeightedCost) float64 { return c.Total(5000, 16*time.Hour, 1) }
+
+	cases := map[string]struct {
+		cost            WeightedCost
+		prefersOverland bool
+	}{
+		"time":      {WeightedCost{Time: 1}, false},
+		"price":     {WeightedCost{Price: 1}, true},
+		"transfers": {WeightedCost{Price: 1, Transfers: 50}, false},
+		"weighted":  {WeightedCost{Price: 0.6, Time: 0.4}, true},
+	}
+
+	for name, tc := range cases {
+		if got := overland(tc.cost) < flight(tc.cost); got != tc.prefersOverland {
+			t.Fatalf("%s: expected overland preferred %v, got %v", name, tc.prefersOverland, got)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package routing
 
 import (
 	"container/heap"
+	"time"
+
 	"github.com/lenalink/backend/internal/graph"
 )
 
@@ -12,6 +14,11 @@ type Path struct {
 	TotalCost float64
 	TotalDistance float64
 	TotalPrice    float64
+
+	// Timeline (filled by time-dependent pathfinders)
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+	WaitingTime   time.Duration // Total time spent waiting at transfers
 }
 
 // DijkstraPathfinder implements Dijkstra's shortest path algorithm
@@ -19,13 +26,20 @@ type DijkstraPathfinder struct {
 	graph *graph.Graph
 }
 
-// NewDijkstraPathfinder creates a new Dijkstra pathfinder
+// NewDijkstraPathfinder creates a new Dijkstra pathfinder minimising travel time
 func NewDijkstraPathfinder(g *graph.Graph) *DijkstraPathfinder {
 	return &DijkstraPathfinder{graph: g}
 }
 
 // FindShortestPath finds the shortest path from start to end node
+// Returns a *PathError wrapping ErrUnknownNode or ErrNoPath if there is none
 func (d *DijkstraPathfinder) FindShortestPath(startNodeID, endNodeID string) (*Path, error) {
+	_, startExists := d.graph.GetNode(startNodeID)
+	_, endExists := d.graph.GetNode(endNodeID)
+	if !startExists || !endExists {
+		return nil, newPathError(d.graph, ErrUnknownNode, startNodeID, endNodeID, []string{startNodeID}, []string{endNodeID})
+	}
+
 	// Priority queue for unvisited nodes
 	pq := make(PriorityQueue, 0)
 	heap.Init(&pq)
@@ -80,11 +94,24 @@ func (d *DijkstraPathfinder) FindShortestPath(startNodeID, endNodeID string) (*P
 	}
 
 	// Reconstruct path
-	return d.reconstructPath(startNodeID, endNodeID, prev, prevEdge), nil
+	path, ok := d.reconstructPath(startNodeID, endNodeID, prev, prevEdge)
+	if !ok {
+		reached := make([]string, 0, len(prevEdge)+1)
+		reached = append(reached, startNodeID)
+		for nodeID := range prevEdge {
+			reached = append(reached, nodeID)
+		}
+
+		pathErr := newPathError(d.graph, ErrNoPath, startNodeID, endNodeID, []string{startNodeID}, []string{endNodeID})
+		return nil, pathErr.withNearestHub(d.graph, reached, []string{endNodeID})
+	}
+
+	return path, nil
 }
 
 // reconstructPath builds the path from prev map
-func (d *DijkstraPathfinder) reconstructPath(startID, endID string, prev map[string]string, prevEdge map[string]*graph.Edge) *Path {
+// Returns false if the end node was not reached
+func (d *DijkstraPathfinder) reconstructPath(startID, endID string, prev map[string]string, prevEdge map[string]*graph.Edge) (*Path, bool) {
 	path := &Path{
 		Nodes: make([]*graph.Node, 0),
 		Edges: make([]*graph.Edge, 0),
@@ -96,9 +123,9 @@ func (d *DijkstraPathfinder) reconstructPath(startID, endID string, prev map[str
 	for currentID != startID {
 		nodeIDs = append([]string{currentID}, nodeIDs...)
 		currentID = prev[currentID]
-		if currentID == "" {
+		if currentID == "" || len(nodeIDs) > len(d.graph.Nodes) {
 			// No path found
-			return path
+			return path, false
 		}
 	}
 	nodeIDs = append([]string{startID}, nodeIDs...)
@@ -119,7 +146,7 @@ func (d *DijkstraPathfinder) reconstructPath(startID, endID string, prev map[str
 		}
 	}
 
-	return path
+	return path, true
 }
 
 // Priority Queue implementation for Dijkstra
@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/graph"
+)
+
+func TestFindShortestPathErrors(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddNode(graph.NewNode("moscow", "Moscow", 55.75, 37.61, "city_center"))
+	g.AddNode(graph.NewNode("yakutsk", "Yakutsk", 62.03, 129.73, "city_center"))
+	g.AddNode(graph.NewNode("tiksi", "Tiksi", 71.64, 128.87, "city_center"))
+	g.AddEdge(graph.NewEdge("air", "moscow", "yakutsk", "air", "test", 4884, 6*time.Hour, 25000))
+
+	_, err := NewDijkstraPathfinder(g).FindShortestPath("moscow", "unknown")
+	if !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode got %v", err)
+	}
+
+	_, err = NewDijkstraPathfinder(g).FindShortestPath("moscow", "tiksi")
+	if !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath got %v", err)
+	}
+
+	var pathErr *PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("expected *PathError got %T", err)
+	}
+	if !pathErr.FromExists || !pathErr.ToExists {
+		t.Fatalf("expected both nodes to exist, got %+v", pathErr)
+	}
+	if pathErr.NearestHub != "yakutsk" {
+		t.Fatalf("expected nearest hub yakutsk got %q", pathErr.NearestHub)
+	}
+}
@@ -0,0 +1,90 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lenalink/backend/internal/graph"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+var (
+	// ErrNoPath is returned when the destination cannot be reached from the origin
+	ErrNoPath = errors.New("no path between origin and destination")
+	// ErrUnknownNode is returned when the origin or destination is not in the graph
+	ErrUnknownNode = errors.New("unknown node")
+)
+
+// PathError describes a failed search together with diagnostics
+// that help the client understand why no route was found
+type PathError struct {
+	Err        error  // ErrNoPath or ErrUnknownNode
+	From       string // Origin node or city
+	To         string // Destination node or city
+	FromExists bool   // Whether the origin is present in the graph
+	ToExists   bool   // Whether the destination is present in the graph
+
+	// Reachable node closest to the destination (empty if nothing is reachable)
+	NearestHub         string
+	NearestHubName     string
+	NearestHubDistance float64 // Distance from the hub to the destination in kilometers
+}
+
+func (e *PathError) Error() string {
+	if e.NearestHub != "" {
+		return fmt.Sprintf("%v: %s -> %s (nearest reachable hub %s, %.0f km away)", e.Err, e.From, e.To, e.NearestHubName, e.NearestHubDistance)
+	}
+	return fmt.Sprintf("%v: %s -> %s", e.Err, e.From, e.To)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// newPathError builds a PathError for a search between groups of nodes
+func newPathError(g *graph.Graph, err error, from, to string, startNodeIDs, endNodeIDs []string) *PathError {
+	return &PathError{
+		Err:        err,
+		From:       from,
+		To:         to,
+		FromExists: anyNodeExists(g, startNodeIDs),
+		ToExists:   anyNodeExists(g, endNodeIDs),
+	}
+}
+
+// withNearestHub fills in the reachable node closest to any of the destination nodes
+func (e *PathError) withNearestHub(g *graph.Graph, reached []string, endNodeIDs []string) *PathError {
+	best := -1.0
+	for _, id := range reached {
+		node, exists := g.GetNode(id)
+		if !exists {
+			continue
+		}
+
+		for _, endID := range endNodeIDs {
+			end, exists := g.GetNode(endID)
+			if !exists {
+				continue
+			}
+
+			distance := utils.CalculateDistance(node.Latitude, node.Longitude, end.Latitude, end.Longitude)
+			if best < 0 || distance < best {
+				best = distance
+				e.NearestHub = node.ID
+				e.NearestHubName = node.Name
+				e.NearestHubDistance = distance
+			}
+		}
+	}
+	return e
+}
+
+// anyNodeExists reports whether at least one of the nodes is in the graph
+func anyNodeExists(g *graph.Graph, nodeIDs []string) bool {
+	for _, id := range nodeIDs {
+		if _, exists := g.GetNode(id); exists {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+package routing
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lenalink/backend/internal/graph"
+)
+
+// maxLabelsPerNode bounds the Pareto bag kept at every node
+// so dense timetables don't blow up the search
+const maxLabelsPerNode = 32
+
+// ParetoPathfinder implements a multi-criteria label-setting search.
+// Instead of a single best path it returns every path that is not dominated
+// on departure, arrival, price, transfers and reliability, which gives
+// genuinely different alternatives (e.g. river vs air vs winter road).
+type ParetoPathfinder struct {
+	graph        *graph.Graph
+	transferRule TransferRule  // Minimum time between arrival and next departure
+	maxWaitTime  time.Duration // Maximum waiting time at a transfer (0 = unlimited)
+	maxLegs      int           // Maximum number of scheduled legs in a path (0 = unlimited)
+}
+
+// NewParetoPathfinder creates a new Pareto pathfinder
+func NewParetoPathfinder(g *graph.Graph, transferRule TransferRule, maxWaitTime time.Duration, maxLegs int) *ParetoPathfinder {
+	return &ParetoPathfinder{
+		graph:        g,
+		transferRule: transferRule,
+		maxWaitTime:  maxWaitTime,
+		maxLegs:      maxLegs,
+	}
+}
+
+// label is a partial journey ending at a node
+type label struct {
+	nodeID      string
+	departure   time.Time // Departure of the first edge (zero until the first edge is taken)
+	arrival     time.Time
+	price       float64
+	legs        int     // Scheduled legs taken (walk/taxi transfers don't count)
+	reliability float64 // Probability that every leg runs (0-1)
+	edge        *graph.Edge
+	lastLeg     *graph.Edge // Last scheduled (non-transfer) edge
+	parent      *label
+	index       int
+}
+
+// dominates reports whether l is at least as good as other on every criterion
+// Equal labels dominate each other so duplicates are dropped
+func (l *label) dominates(other *label) bool {
+	return !l.departure.Before(other.departure) &&
+		!l.arrival.After(other.arrival) &&
+		l.price <= other.price &&
+		l.legs <= other.legs &&
+		l.reliability >= other.reliability
+}
+
+// visits reports whether the journey already passed through a node
+func (l *label) visits(nodeID string) bool {
+	for current := l; current != nil; current = current.parent {
+		if current.nodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// FindParetoPaths finds all non-dominated paths from any of the start nodes to any
+// of the end nodes when leaving no earlier than departAfter.
+// Paths are ordered by arrival time, then by price.
+// Returns a *PathError wrapping ErrUnknownNode or ErrNoPath if there are none.
+func (p *ParetoPathfinder) FindParetoPaths(startNodeIDs, endNodeIDs []string, departAfter time.Time) ([]*Path, error) {
+	if !anyNodeExists(p.graph, startNodeIDs) || !anyNodeExists(p.graph, endNodeIDs) {
+		return nil, newPathError(p.graph, ErrUnknownNode, strings.Join(startNodeIDs, ","), strings.Join(endNodeIDs, ","), startNodeIDs, endNodeIDs)
+	}
+
+	targets := make(map[string]bool, len(endNodeIDs))
+	for _, id := range endNodeIDs {
+		targets[id] = true
+	}
+
+	pq := make(labelQueue, 0)
+	heap.Init(&pq)
+
+	for _, id := range startNodeIDs {
+		heap.Push(&pq, &label{nodeID: id, arrival: departAfter, reliability: 1})
+	}
+
+	// Settled non-dominated labels per node
+	bags := make(map[string][]*label)
+	results := make([]*label, 0)
+
+	for pq.Len() > 0 {
+		current := heap.Pop(&pq).(*label)
+
+		if !insertLabel(bags, current) {
+			continue
+		}
+
+		if targets[current.nodeID] && current.legs > 0 {
+			results = insertResult(results, current)
+			continue
+		}
+
+		if p.maxLegs > 0 && current.legs >= p.maxLegs {
+			continue
+		}
+
+		for _, edge := range p.graph.GetNeighbors(current.nodeID) {
+			if current.visits(edge.ToNodeID) {
+				continue
+			}
+
+			// One walk/taxi transfer at a time
+			if edge.Transfer && current.edge != nil && current.edge.Transfer {
+				continue
+			}
+
+			minTransfer := minTransferTime(p.graph, p.transferRule, current.nodeID, current.lastLeg, edge)
+			arriveNext, ok := traverseEdge(edge, current.arrival, current.legs > 0, minTransfer, p.maxWaitTime)
+			if !ok {
+				continue
+			}
+
+			legs := current.legs
+			if !edge.Transfer {
+				legs++
+			}
+
+			next := &label{
+				nodeID:      edge.ToNodeID,
+				departure:   current.departure,
+				arrival:     arriveNext,
+				price:       current.price + edge.Price,
+				legs:        legs,
+				reliability: current.reliability * edgeReliability(edge),
+				edge:        edge,
+				lastLeg:     current.lastLeg,
+				parent:      current,
+			}
+			if !edge.Transfer {
+				next.lastLeg = edge
+			}
+			if current.legs == 0 {
+				next.departure = current.arrival
+				if !edge.DepartureTime.IsZero() {
+					next.departure = edge.DepartureTime
+				}
+			}
+
+			if isDominated(bags[next.nodeID], next) {
+				continue
+			}
+			heap.Push(&pq, next)
+		}
+	}
+
+	if len(results) == 0 {
+		reached := make([]string, 0, len(bags))
+		for nodeID := range bags {
+			reached = append(reached, nodeID)
+		}
+
+		pathErr := newPathError(p.graph, ErrNoPath, strings.Join(startNodeIDs, ","), strings.Join(endNodeIDs, ","), startNodeIDs, endNodeIDs)
+		return nil, pathErr.withNearestHub(p.graph, reached, endNodeIDs)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if !results[i].arrival.Equal(results[j].arrival) {
+			return results[i].arrival.Before(results[j].arrival)
+		}
+		return results[i].price < results[j].price
+	})
+
+	paths := make([]*Path, 0, len(results))
+	for _, result := range results {
+		paths = append(paths, p.buildPath(result))
+	}
+
+	return paths, nil
+}
+
+// buildPath converts a label chain into a path with its timeline
+func (p *ParetoPathfinder) buildPath(l *label) *Path {
+	path := &Path{
+		Nodes:         make([]*graph.Node, 0),
+		Edges:         make([]*graph.Edge, 0),
+		DepartureTime: l.departure,
+		ArrivalTime:   l.arrival,
+	}
+
+	chain := make([]*label, 0)
+	for current := l; current != nil; current = current.parent {
+		chain = append([]*label{current}, chain...)
+	}
+
+	for i, current := range chain {
+		if node, exists := p.graph.GetNode(current.nodeID); exists {
+			path.Nodes = append(path.Nodes, node)
+		}
+		if current.edge == nil {
+			continue
+		}
+
+		path.Edges = append(path.Edges, current.edge)
+		path.TotalDistance += current.edge.Distance
+		path.TotalPrice += current.edge.Price
+
+		if i > 1 && !current.edge.DepartureTime.IsZero() {
+			path.WaitingTime += current.edge.DepartureTime.Sub(chain[i-1].arrival)
+		}
+	}
+
+	path.TotalCost = path.ArrivalTime.Sub(path.DepartureTime).Minutes()
+
+	return path
+}
+
+// edgeReliability converts the edge rating to a probability
+// Edges without a rating are treated as fully reliable
+func edgeReliability(edge *graph.Edge) float64 {
+	if edge.Reliability <= 0 {
+		return 1
+	}
+	return edge.Reliability / 100
+}
+
+// isDominated reports whether any label in the bag dominates l
+func isDominated(bag []*label, l *label) bool {
+	for _, existing := range bag {
+		if existing.dominates(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// insertLabel adds l to its node's bag, removing labels it dominates
+// Returns false if l is dominated or the bag is full
+func insertLabel(bags map[string][]*label, l *label) bool {
+	bag := bags[l.nodeID]
+	if isDominated(bag, l) || len(bag) >= maxLabelsPerNode {
+		return false
+	}
+
+	kept := bag[:0]
+	for _, existing := range bag {
+		if !l.dominates(existing) {
+			kept = append(kept, existing)
+		}
+	}
+	bags[l.nodeID] = append(kept, l)
+	return true
+}
+
+// insertResult keeps only non-dominated labels across all target nodes
+func insertResult(results []*label, l *label) []*label {
+	if isDominated(results, l) {
+		return results
+	}
+
+	kept := results[:0]
+	for _, existing := range results {
+		if !l.dominates(existing) {
+			kept = append(kept, existing)
+		}
+	}
+	return append(kept, l)
+}
+
+// Priority queue of labels ordered by arrival time
+
+type labelQueue []*label
+
+func (q labelQueue) Len() int { return len(q) }
+
+func (q labelQueue) Less(i, j int) bool {
+	return q[i].arrival.Before(q[j].arrival)
+}
+
+func (q labelQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *labelQueue) Push(x interface{}) {
+	l := x.(*label)
+	l.index = len(*q)
+	*q = append(*q, l)
+}
+
+func (q *labelQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	l := old[n-1]
+	old[n-1] = nil
+	l.index = -1
+	*q = old[0 : n-1]
+	return l
+}
@@ -0,0 +1,108 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/graph"
+)
+
+func scheduledEdge(id, from, to string, departure, arrival time.Time) *graph.Edge {
+	edge := graph.NewEdge(id, from, to, "bus", "test", 100, arrival.Sub(departure), 1000)
+	edge.DepartureTime = departure
+	edge.ArrivalTime = arrival
+	return edge
+}
+
+func TestFindParetoPathsRespectsMinTransferTime(t *testing.T) {
+	base := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	g := graph.NewGraph()
+	for _, id := range []string{"A", "B", "C"} {
+		g.AddNode(graph.NewNode(id, id, 0, 0, "city_center"))
+	}
+
+	// A -> B arrives at 10:00
+	g.AddEdge(scheduledEdge("ab", "A", "B", base, base.Add(2*time.Hour)))
+	// B -> C at 10:30 is too tight for a 60 minute transfer
+	g.AddEdge(scheduledEdge("bc-early", "B", "C", base.Add(150*time.Minute), base.Add(4*time.Hour)))
+	// B -> C at 11:30 can be caught
+	g.AddEdge(scheduledEdge("bc-late", "B", "C", base.Add(210*time.Minute), base.Add(5*time.Hour)))
+
+	paths, err := NewParetoPathfinder(g, FixedTransferTime(time.Hour), 0, 4).FindParetoPaths([]string{"A"}, []string{"C"}, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected only the connection that can be caught, got %d paths", len(paths))
+	}
+	path := paths[0]
+
+	if len(path.Edges) != 2 || path.Edges[1].ID != "bc-late" {
+		t.Fatalf("expected path via bc-late, got %+v", path.Edges)
+	}
+	if !path.ArrivalTime.Equal(base.Add(5 * time.Hour)) {
+		t.Fatalf("expected arrival at %s got %s", base.Add(5*time.Hour), path.ArrivalTime)
+	}
+	if path.WaitingTime != 90*time.Minute {
+		t.Fatalf("expected 90m waiting time got %s", path.WaitingTime)
+	}
+}
+
+func TestFindParetoPathsSkipsDepartedEdges(t *testing.T) {
+	base := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	g := graph.NewGraph()
+	g.AddNode(graph.NewNode("A", "A", 0, 0, "city_center"))
+	g.AddNode(graph.NewNode("B", "B", 0, 0, "city_center"))
+	g.AddEdge(scheduledEdge("ab", "A", "B", base.Add(-time.Hour), base.Add(time.Hour)))
+
+	_, err := NewParetoPathfinder(g, FixedTransferTime(time.Hour), 0, 4).FindParetoPaths([]string{"A"}, []string{"B"}, base)
+	if !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath got %v", err)
+	}
+}
+
+func TestFindParetoPathsReturnsNonDominatedAlternatives(t *testing.T) {
+	base := time.Date(2025, 6, 20, 8, 0, 0, 0, time.UTC)
+
+	g := graph.NewGraph()
+	for _, id := range []string{"yakutsk_airport", "yakutsk_port", "olyokminsk_airport", "olyokminsk_port"} {
+		g.AddNode(graph.NewNode(id, id, 0, 0, "city_center"))
+	}
+
+	// Fast and expensive flight
+	air := scheduledEdge("air", "yakutsk_airport", "olyokminsk_airport", base.Add(time.Hour), base.Add(3*time.Hour))
+	air.Price = 15000
+	g.AddEdge(air)
+
+	// Slow and cheap river boat
+	river := scheduledEdge("river", "yakutsk_port", "olyokminsk_port", base.Add(2*time.Hour), base.Add(26*time.Hour))
+	river.Price = 4000
+	g.AddEdge(river)
+
+	// Slower and pricier than the flight: dominated
+	charter := scheduledEdge("charter", "yakutsk_airport", "olyokminsk_airport", base.Add(time.Hour), base.Add(5*time.Hour))
+	charter.Price = 20000
+	g.AddEdge(charter)
+
+	paths, err := NewParetoPathfinder(g, FixedTransferTime(time.Hour), 0, 4).FindParetoPaths(
+		[]string{"yakutsk_airport", "yakutsk_port"},
+		[]string{"olyokminsk_airport", "olyokminsk_port"},
+		base,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 alternatives got %d", len(paths))
+	}
+	if paths[0].Edges[0].ID != "air" || paths[1].Edges[0].ID != "river" {
+		t.Fatalf("expected air then river, got %s then %s", paths[0].Edges[0].ID, paths[1].Edges[0].ID)
+	}
+	if paths[1].TotalPrice != 4000 {
+		t.Fatalf("expected river price 4000 got %.0f", paths[1].TotalPrice)
+	}
+}
@@ -0,0 +1,64 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/lenalink/backend/internal/graph"
+)
+
+// TransferRule decides how much time a traveller needs at a node between
+// arriving on the inbound edge and departing on the outbound one
+// (minimum connection time). Walk/taxi transfer edges are never passed as inbound:
+// the last scheduled edge before them is used instead.
+type TransferRule interface {
+	MinTransferTime(node *graph.Node, inbound, outbound *graph.Edge) time.Duration
+}
+
+// FixedTransferTime applies the same minimum transfer time everywhere
+type FixedTransferTime time.Duration
+
+// MinTransferTime returns the fixed transfer time
+func (f FixedTransferTime) MinTransferTime(node *graph.Node, inbound, outbound *graph.Edge) time.Duration {
+	return time.Duration(f)
+}
+
+// minTransferTime applies the rule for departing from nodeID on outbound
+func minTransferTime(g *graph.Graph, rule TransferRule, nodeID string, inbound, outbound *graph.Edge) time.Duration {
+	if rule == nil || inbound == nil || outbound.DepartureTime.IsZero() {
+		return 0
+	}
+
+	node, exists := g.GetNode(nodeID)
+	if !exists {
+		return 0
+	}
+	return rule.MinTransferTime(node, inbound, outbound)
+}
+
+// traverseEdge returns the arrival time at the edge's destination when standing at
+// its origin at arrivedAt, or false if the edge cannot be caught.
+// It applies the schedule rules of the time-dependent search.
+func traverseEdge(edge *graph.Edge, arrivedAt time.Time, transferring bool, minTransferTime, maxWaitTime time.Duration) (time.Time, bool) {
+	// Unscheduled transport leaves as soon as we are there
+	if edge.DepartureTime.IsZero() {
+		return arrivedAt.Add(edge.Duration), true
+	}
+
+	readyAt := arrivedAt
+	if transferring {
+		readyAt = arrivedAt.Add(minTransferTime)
+	}
+
+	if edge.DepartureTime.Before(readyAt) {
+		return time.Time{}, false
+	}
+
+	if transferring && maxWaitTime > 0 && edge.DepartureTime.Sub(arrivedAt) > maxWaitTime {
+		return time.Time{}, false
+	}
+
+	if edge.ArrivalTime.IsZero() {
+		return edge.DepartureTime.Add(edge.Duration), true
+	}
+	return edge.ArrivalTime, true
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// plannedChange is a ticket to be replaced and its priced replacement
+type plannedChange struct {
+	old         *domain.BookedSegment
+	segment     *domain.Segment
+	passenger   *domain.Passenger
+	replacement domain.BookedSegment
+	changeFee   domain.Money
+}
+
+// ChangeBooking moves tickets to other segments of the same legs, e.g. to a later bus
+// The new tickets are booked before the old ones are cancelled, so a failure leaves
+// the booking as it was. The fare difference plus the change fees of the old tickets'
+// fare rules is charged, or refunded when the new tickets are cheaper. A charge is taken
+// as a payment of its own, so the booking's payment method must allow charging it
+// without the customer.
+// Each change is recorded as a new version of the booking.
+func (bs *BookingService) ChangeBooking(ctx context.Context, bookingID string, requests []domain.ChangeRequest, actor, reason string) (*domain.Booking, *domain.BookingChange, error) {
+	return bs.changeBooking(ctx, bookingID, requests, actor, reason, false)
+}
+
+// RebookDisrupted moves tickets the carrier's cancellation or delay made unusable to other segments
+// Involuntary changes ignore the tariff's change conditions and cost the passenger nothing:
+// change fees are waived and a more expensive fare is not charged, a cheaper one is refunded.
+func (bs *BookingService) RebookDisrupted(ctx context.Context, bookingID string, requests []domain.ChangeRequest, reason string) (*domain.Booking, *domain.BookingChange, error) {
+	return bs.changeBooking(ctx, bookingID, requests, domain.ActorSystem, reason, true)
+}
+
+// changeBooking changes tickets voluntarily or, when involuntary, on the carrier's account
+func (bs *BookingService) changeBooking(ctx context.Context, bookingID string, requests []domain.ChangeRequest, actor, reason string, involuntary bool) (*domain.Booking, *domain.BookingChange, error) {
+	if len(requests) == 0 {
+		return nil, nil, domain.NewDomainError("INVALID_BOOKING", "At least one ticket change is required")
+	}
+
+	booking, err := bs.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !booking.IsChangeable() {
+		return nil, nil, domain.NewDomainError("CHANGE_NOT_ALLOWED", fmt.Sprintf("A %s booking cannot be changed", booking.Status))
+	}
+	if booking.Payment == nil || booking.Payment.Status != domain.PaymentCompleted {
+		return nil, nil, domain.NewDomainError("CHANGE_NOT_ALLOWED", "Only paid bookings can be changed")
+	}
+
+	rules, err := bs.fareRules.Rules(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 1. Validate and price every change before touching providers
+	now := time.Now()
+	plan, err := bs.planChange(ctx, booking, requests, rules, now, involuntary)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change := &domain.BookingChange{
+		ID:        utils.GenerateID(),
+		BookingID: booking.ID,
+		Version:   max(booking.Version, 1) + 1,
+		Tickets:   make([]domain.TicketChange, len(plan)),
+		Actor:     actor,
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	for i, planned := range plan {
+		difference := planned.replacement.TotalPrice.Sub(planned.old.TotalPrice)
+		change.Tickets[i] = domain.TicketChange{
+			OldBookedSegmentID: planned.old.ID,
+			NewBookedSegmentID: planned.replacement.ID,
+			FareDifference:     difference,
+			ChangeFee:          planned.changeFee,
+		}
+		change.FareDifference = change.FareDifference.Add(difference)
+		change.ChangeFee = change.ChangeFee.Add(planned.changeFee)
+	}
+	change.Amount = change.FareDifference.Add(change.ChangeFee)
+	if involuntary && change.Amount.IsPositive() {
+		change.Amount = domain.Money{Currency: change.Amount.Currency}
+	}
+	if change.Amount.IsPositive() && !bs.paymentSvc.CanCharge(booking.Payment.Method) {
+		return nil, nil, domain.NewDomainError(domain.ErrChargeNotSupported.Code, fmt.Sprintf("The change costs %s more, which cannot be charged to a %s payment without the customer: cancel the tickets and book the new ones instead", change.Amount, booking.Payment.Method))
+	}
+
+	// 2. Hold seats on the new segments
+	seats := make(map[string]int)
+	for _, planned := range plan {
+		seats[planned.segment.ID] += bs.seatsFor(booking, &planned.replacement)
+	}
+	holds, err := bs.seats.HoldSegments(ctx, booking.ID, seats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3. Book the new tickets with providers
+	bookingRefs := make([]string, 0, len(plan))
+	for i := range plan {
+		planned := &plan[i]
+
+		ticketNumber, bookingRef, err := bs.providerBooking.BookSegment(ctx, planned.segment, planned.passenger)
+		if err != nil {
+			bs.undoChange(ctx, bookingRefs, holds)
+			return nil, nil, domain.NewDomainError("BOOKING_FAILED", fmt.Sprintf("Could not book %s -> %s: %v", planned.segment.StartStop.City, planned.segment.EndStop.City, err))
+		}
+		bookingRefs = append(bookingRefs, bookingRef)
+
+		planned.replacement.TicketNumber = ticketNumber
+		planned.replacement.ProviderBookingRef = bookingRef
+	}
+
+	// 4. Charge or refund the difference; until the money has moved the change can be undone
+	switch {
+	case change.Amount.IsPositive():
+		receipt := bs.receipts.ChangeReceipt(booking, &plan[0].replacement, change.Amount)
+		charge, err := bs.paymentSvc.Charge(ctx, booking, change.Amount, receipt)
+		if err != nil {
+			bs.undoChange(ctx, bookingRefs, holds)
+			return nil, nil, domain.NewDomainError("PAYMENT_FAILED", fmt.Sprintf("The fare difference could not be charged: %v", err))
+		}
+		change.PaymentID = charge.ID
+	case change.Amount.IsNegative():
+		change.Amount = domain.MinMoney(change.Amount.Neg(), booking.RefundableAmount()).Neg()
+		receipt := bs.receipts.ChangeRefundReceipt(booking, plan[0].old, &plan[0].replacement, change.Amount.Neg())
+		if err := bs.paymentSvc.RefundBooking(ctx, booking, change.Amount.Neg(), receipt); err != nil {
+			bs.undoChange(ctx, bookingRefs, holds)
+			return nil, nil, fmt.Errorf("refund failed: %w", err)
+		}
+	}
+
+	// 5. Cancel the old tickets with providers and sell the new seats
+	oldRefs := make([]string, len(plan))
+	for i, planned := range plan {
+		oldRefs[i] = planned.old.ProviderBookingRef
+	}
+	bs.rollbackBookings(ctx, oldRefs)
+
+	if err := bs.seats.Confirm(ctx, booking.ID); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: failed to confirm seats for booking %s: %v\n", booking.ID, err)
+	}
+	for _, planned := range plan {
+		if err := bs.seats.ReturnSeats(ctx, booking.ID, planned.old.SegmentID, bs.seatsFor(booking, planned.old)); err != nil {
+			fmt.Printf("Warning: failed to return seat on segment %s: %v\n", planned.old.SegmentID, err)
+		}
+	}
+
+	// 6. Record the new version of the booking
+	for _, planned := range plan {
+		booking.ReplaceSegment(planned.old.ID, planned.replacement, now)
+	}
+	booking.Version = change.Version
+	booking.UpdatedAt = now
+
+	if err := bs.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, nil, fmt.Errorf("failed to update booking: %w", err)
+	}
+	if err := bs.changes.Save(ctx, change); err != nil {
+		return nil, nil, fmt.Errorf("failed to record booking change: %w", err)
+	}
+
+	return booking, change, nil
+}
+
+// GetBookingChanges returns the change history of a booking, oldest first
+func (bs *BookingService) GetBookingChanges(ctx context.Context, bookingID string) ([]domain.BookingChange, error) {
+	if _, err := bs.bookingRepo.FindByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return bs.changes.FindByBooking(ctx, bookingID)
+}
+
+// planChange validates the requested changes and prices the replacement tickets
+// Involuntary changes skip the tariff's change conditions and fees.
+func (bs *BookingService) planChange(ctx context.Context, booking *domain.Booking, requests []domain.ChangeRequest, rules domain.FareRules, now time.Time, involuntary bool) ([]plannedChange, error) {
+	plan := make([]plannedChange, 0, len(requests))
+	seen := make(map[string]bool, len(requests))
+
+	for _, request := range requests {
+		if seen[request.BookedSegmentID] {
+			return nil, domain.NewDomainError("INVALID_BOOKING", fmt.Sprintf("Ticket %s is changed more than once", request.BookedSegmentID))
+		}
+		seen[request.BookedSegmentID] = true
+
+		old, ok := booking.FindSegment(request.BookedSegmentID)
+		if !ok {
+			return nil, domain.ErrSegmentNotFound
+		}
+		if old.BookingStatus == domain.BookingCancelled {
+			return nil, domain.NewDomainError("CHANGE_NOT_ALLOWED", "Ticket is cancelled")
+		}
+		if !old.DepartureTime.After(now) {
+			return nil, domain.NewDomainError("CHANGE_NOT_ALLOWED", "Ticket cannot be changed after departure")
+		}
+
+		rule := rules.For(old.Source, old.Tariff, old.TransportType)
+		changeFee := rule.ChangeFee
+		if involuntary {
+			changeFee = domain.Money{Currency: changeFee.Currency}
+		} else if !rule.Changeable {
+			return nil, domain.NewDomainError("CHANGE_NOT_ALLOWED", "The ticket's tariff does not allow changes")
+		}
+
+		segment, err := bs.segmentRepo.FindByID(ctx, request.SegmentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := convertSegmentPrice(bs.config.ExchangeRates, segment); err != nil {
+			return nil, domain.NewDomainError("INVALID_SEGMENT", fmt.Sprintf("The new segment's price cannot be converted: %v", err))
+		}
+		switch {
+		case segment.ID == old.SegmentID:
+			return nil, domain.NewDomainError("INVALID_SEGMENT", "The ticket is already booked on this segment")
+		case !old.SameLeg(segment):
+			return nil, domain.NewDomainError("INVALID_SEGMENT", fmt.Sprintf("Segment %s does not run %s -> %s", segment.ID, old.From.City, old.To.City))
+		case !segment.DepartureTime.After(now):
+			return nil, domain.NewDomainError("INVALID_SEGMENT", "The new segment has already departed")
+		case segment.IsCancelled():
+			return nil, domain.NewDomainError("INVALID_SEGMENT", "The new segment was cancelled by the carrier")
+		case !segment.IsAvailableOn(segment.DepartureTime):
+			return nil, domain.NewDomainError("INVALID_SEGMENT", "The new segment does not run in this season")
+		}
+
+		passenger := booking.FindPassenger(old.PassengerID)
+		if passenger == nil {
+			passenger = &booking.Passenger
+		}
+
+		// Price the new ticket like the old one: passenger fare, then commission
+		basePrice := bs.fares.Fare(segment.TransportType, passenger.Type, segment.Price)
+		commission := bs.commissionSvc.CalculateCommission(segment.TransportType, basePrice)
+
+		plan = append(plan, plannedChange{
+			old:       old,
+			segment:   segment,
+			passenger: passenger,
+			changeFee: changeFee,
+			replacement: domain.BookedSegment{
+				ID:            utils.GenerateID(),
+				SegmentID:     segment.ID,
+				PassengerID:   old.PassengerID,
+				Provider:      segment.Provider,
+				TransportType: segment.TransportType,
+				From:          segment.StartStop,
+				To:            segment.EndStop,
+				DepartureTime: segment.DepartureTime,
+				ArrivalTime:   segment.ArrivalTime,
+				Price:         basePrice,
+				Commission:    commission,
+				TotalPrice:    basePrice.Add(commission),
+				BookingStatus: domain.BookingConfirmed,
+				Source:        segment.Source,
+				Tariff:        segment.Tariff,
+			},
+		})
+	}
+
+	if err := checkChangedItinerary(booking, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkChangedItinerary makes sure every passenger can still make their connections:
+// each remaining ticket must depart after the previous one arrives
+func checkChangedItinerary(booking *domain.Booking, plan []plannedChange) error {
+	replacements := make(map[string]*domain.BookedSegment, len(plan))
+	for i := range plan {
+		replacements[plan[i].old.ID] = &plan[i].replacement
+	}
+
+	for _, passenger := range booking.Passengers {
+		var previous *domain.BookedSegment
+		for _, ticket := range booking.SegmentsFor(passenger.ID) {
+			if ticket.BookingStatus == domain.BookingCancelled {
+				continue
+			}
+			current := &ticket
+			if replacement, ok := replacements[ticket.ID]; ok {
+				current = replacement
+			}
+
+			if previous != nil && current.DepartureTime.Before(previous.ArrivalTime) {
+				return domain.NewDomainError("INVALID_CONNECTION", fmt.Sprintf("%s would miss the connection in %s", passenger.FirstName, previous.To.City))
+			}
+			previous = current
+		}
+	}
+
+	return nil
+}
+
+// undoChange cancels the new tickets booked so far and releases their seat holds
+// It runs even when ctx is cancelled: the compensations must still happen.
+func (bs *BookingService) undoChange(ctx context.Context, bookingRefs []string, holds []domain.SeatHold) {
+	ctx = context.WithoutCancel(ctx)
+
+	bs.rollbackBookings(ctx, bookingRefs)
+	if err := bs.seats.ReleaseHolds(ctx, holds); err != nil {
+		// Holds expire on their own
+		fmt.Printf("Warning: failed to release seat holds: %v\n", err)
+	}
+}
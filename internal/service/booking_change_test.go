@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/internal/repository/memory"
+)
+
+// fakeRedirectGateway takes payments the customer confirms on its page, like every real gateway
+type fakeRedirectGateway struct {
+	PaymentGateway
+}
+
+func (g *fakeRedirectGateway) RedirectsCustomer() {}
+
+type fakeChangeSegmentRepo struct {
+	repository.SegmentRepository
+	segments map[string]*domain.Segment
+}
+
+func (r *fakeChangeSegmentRepo) FindByID(ctx context.Context, id string) (*domain.Segment, error) {
+	if segment, ok := r.segments[id]; ok {
+		return segment, nil
+	}
+	return nil, domain.ErrSegmentNotFound
+}
+
+type fakeFareRuleRepo struct {
+	repository.FareRuleRepository
+}
+
+func (r *fakeFareRuleRepo) FindAll(ctx context.Context) ([]domain.FareRule, error) {
+	return nil, nil
+}
+
+func TestChangeBookingRejectsSurchargeOnRedirectGateway(t *testing.T) {
+	ctx := context.Background()
+	departure := time.Now().Add(48 * time.Hour)
+	yakutsk := domain.Stop{ID: "yks", City: "Якутск"}
+	mirny := domain.Stop{ID: "mjz", City: "Мирный"}
+
+	bookings := memory.NewBookingRepository()
+	booking := &domain.Booking{
+		ID:         "b1",
+		Status:     domain.BookingConfirmed,
+		Passengers: []domain.Passenger{{ID: "p1", FirstName: "Иван"}},
+		Segments: []domain.BookedSegment{{
+			ID:            "t1",
+			SegmentID:     "bus-morning",
+			PassengerID:   "p1",
+			TransportType: domain.TransportBus,
+			From:          yakutsk,
+			To:            mirny,
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(10 * time.Hour),
+			TotalPrice:    domain.Rubles(3000),
+			BookingStatus: domain.BookingConfirmed,
+		}},
+		Payment: &domain.Payment{ID: "pay-b1", Amount: domain.Rubles(3000), Method: domain.PaymentYooKassa, Status: domain.PaymentCompleted},
+	}
+	if err := bookings.Save(ctx, booking); err != nil {
+		t.Fatal(err)
+	}
+
+	segments := &fakeChangeSegmentRepo{segments: map[string]*domain.Segment{
+		"bus-evening": {
+			ID:            "bus-evening",
+			TransportType: domain.TransportBus,
+			StartStop:     yakutsk,
+			EndStop:       mirny,
+			DepartureTime: departure.Add(8 * time.Hour),
+			ArrivalTime:   departure.Add(18 * time.Hour),
+			Price:         domain.Rubles(4500),
+		},
+	}}
+	commission := NewCommissionService(DefaultCommissionConfig())
+	provider := &fakeProviderBooking{failing: make(map[string]bool)}
+	payments := NewPaymentService(NewGatewayRegistry(&fakeRedirectGateway{}))
+	svc := NewBookingService(nil, segments, bookings, nil, commission, nil, payments, provider,
+		nil, nil, NewFareRuleService(&fakeFareRuleRepo{}, commission), DefaultBookingConfig())
+
+	// The later bus costs more, and the payment cannot be charged again without the customer
+	_, _, err := svc.ChangeBooking(ctx, "b1", []domain.ChangeRequest{{BookedSegmentID: "t1", SegmentID: "bus-evening"}}, domain.ActorCustomer, "")
+	var domainErr domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrChargeNotSupported.Code {
+		t.Fatalf("expected the change to be rejected up front, got %v", err)
+	}
+
+	unchanged, _ := bookings.FindByID(ctx, "b1")
+	if unchanged.Segments[0].SegmentID != "bus-morning" || len(unchanged.Charges) != 0 {
+		t.Fatal("expected the booking to be left as it was")
+	}
+}
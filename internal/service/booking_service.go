@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,39 +17,98 @@ type ProviderBookingService interface {
 	CancelBooking(ctx context.Context, bookingRef string) error
 }
 
+// MaxPassengers is the maximum number of passengers in one booking
+const MaxPassengers = 10
+
+// PaymentFlow defines whether tickets are issued before or after the customer pays
+type PaymentFlow string
+
+const (
+	PaymentFlowTicketFirst PaymentFlow = "ticket_first" // Issue tickets, then take the payment
+	PaymentFlowPayFirst    PaymentFlow = "pay_first"    // Authorize the payment, issue tickets, then capture it
+)
+
+// BookingConfig holds parameters for booking creation
+type BookingConfig struct {
+	PaymentFlow   PaymentFlow
+	ExchangeRates *domain.ExchangeRates // Converts provider prices into the settlement currency
+	Receipts      ReceiptConfig
+}
+
+// DefaultBookingConfig returns default booking configuration
+func DefaultBookingConfig() BookingConfig {
+	return BookingConfig{
+		PaymentFlow:   PaymentFlowTicketFirst,
+		ExchangeRates: domain.DefaultExchangeRates(),
+		Receipts:      DefaultReceiptConfig(),
+	}
+}
+
 // BookingService handles multi-segment booking with ACID guarantees
 type BookingService struct {
-	routeRepo       repository.RouteRepository
+	routes          *RouteService
+	segmentRepo     repository.SegmentRepository
 	bookingRepo     repository.BookingRepository
+	changes         repository.BookingChangeRepository
 	commissionSvc   *CommissionService
 	insuranceSvc    *InsuranceService
 	paymentSvc      *PaymentService
 	providerBooking ProviderBookingService
+	seats           *SeatInventoryService
+	sagas           *SagaService
+	fareRules       *FareRuleService
+	fares           domain.PassengerFares
+	receipts        *ReceiptService
+	config          BookingConfig
 }
 
 // NewBookingService creates a new booking service
 func NewBookingService(
-	routeRepo repository.RouteRepository,
+	routes *RouteService,
+	segmentRepo repository.SegmentRepository,
 	bookingRepo repository.BookingRepository,
+	changes repository.BookingChangeRepository,
 	commissionSvc *CommissionService,
 	insuranceSvc *InsuranceService,
 	paymentSvc *PaymentService,
 	providerBooking ProviderBookingService,
+	seats *SeatInventoryService,
+	sagas *SagaService,
+	fareRules *FareRuleService,
+	config BookingConfig,
 ) *BookingService {
 	return &BookingService{
-		routeRepo:       routeRepo,
+		routes:          routes,
+		segmentRepo:     segmentRepo,
 		bookingRepo:     bookingRepo,
+		changes:         changes,
 		commissionSvc:   commissionSvc,
 		insuranceSvc:    insuranceSvc,
 		paymentSvc:      paymentSvc,
 		providerBooking: providerBooking,
+		seats:           seats,
+		sagas:           sagas,
+		fareRules:       fareRules,
+		fares:           domain.DefaultPassengerFares(),
+		receipts:        NewReceiptService(config.Receipts),
+		config:          config,
 	}
 }
 
 // CreateBooking creates a multi-segment booking with ACID transaction
-func (bs *BookingService) CreateBooking(ctx context.Context, routeID string, passenger domain.Passenger, includeInsurance bool, paymentMethod domain.PaymentMethod) (*domain.Booking, error) {
-	// 1. Fetch route
-	route, err := bs.routeRepo.FindByID(ctx, routeID)
+// The first passenger is the lead passenger who receives the tickets and pays.
+// Every passenger gets a ticket for every leg, priced by their fare category.
+// In the pay-first flow no ticket is issued until the payment is authorized.
+func (bs *BookingService) CreateBooking(ctx context.Context, routeID string, passengers []domain.Passenger, includeInsurance bool, paymentMethod domain.PaymentMethod) (*domain.Booking, error) {
+	if len(passengers) == 0 {
+		return nil, domain.NewDomainError("INVALID_BOOKING", "At least one passenger is required")
+	}
+	if len(passengers) > MaxPassengers {
+		return nil, domain.NewDomainError("INVALID_BOOKING", fmt.Sprintf("A booking cannot have more than %d passengers", MaxPassengers))
+	}
+
+	// 1. Fetch route (saved, or composed by a live search)
+	route, err := bs.routes.BookableRoute(ctx, routeID)
 	if err != nil {
 		return nil, fmt.Errorf("route not found: %w", err)
 	}
@@ -57,111 +117,263 @@ func (bs *BookingService) CreateBooking(ctx context.Context, routeID string, pas
 		return nil, fmt.Errorf("route has no segments")
 	}
 
-	// 2. Create booking
+	// Saved routes keep the provider's prices; tickets are priced in the settlement currency
+	for i := range route.Segments {
+		if err := convertSegmentPrice(bs.config.ExchangeRates, &route.Segments[i]); err != nil {
+			return nil, domain.NewDomainError("INVALID_ROUTE", fmt.Sprintf("The route's price cannot be converted: %v", err))
+		}
+	}
+
+	// 2. Classify passengers by age on the departure date
+	passengers = append([]domain.Passenger(nil), passengers...)
+	for i := range passengers {
+		if passengers[i].ID == "" {
+			passengers[i].ID = utils.GenerateID()
+		}
+		passengers[i].Type = domain.PassengerTypeOn(passengers[i].DateOfBirth, route.DepartureTime)
+	}
+	if err := validatePassengerMix(passengers); err != nil {
+		return nil, err
+	}
+
+	// 3. Create booking
 	booking := &domain.Booking{
 		ID:               utils.GenerateID(),
 		RouteID:          routeID,
-		Passenger:        passenger,
-		Segments:         make([]domain.BookedSegment, 0, len(route.Segments)),
+		Passenger:        passengers[0],
+		Passengers:       passengers,
+		Segments:         make([]domain.BookedSegment, 0, len(route.Segments)*len(passengers)),
 		IncludeInsurance: includeInsurance,
 		Status:           domain.BookingPending,
+		Version:          1,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
-	// 3. Calculate insurance if requested
+	// 4. Calculate insurance if requested (each passenger is insured)
 	if includeInsurance {
-		booking.InsurancePremium = bs.insuranceSvc.CalculatePremium(route)
+		booking.InsurancePremium = bs.insuranceSvc.CalculatePremium(route).Mul(float64(len(passengers)))
 	}
 
-	// 4. Book all segments (with rollback on failure)
-	bookedSegments := make([]domain.BookedSegment, 0, len(route.Segments))
-	bookingRefs := make([]string, 0, len(route.Segments))
+	// 5. Hold seats on every segment (fails fast if any segment is short)
+	if _, err := bs.seats.HoldRoute(ctx, booking.ID, route, domain.SeatsRequired(passengers)); err != nil {
+		return nil, err
+	}
 
-	for i := range route.Segments {
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			// Context cancelled, rollback all bookings
-			bs.rollbackBookings(ctx, bookingRefs)
-			booking.MarkAsFailed("booking cancelled: " + ctx.Err().Error())
-			bs.bookingRepo.Save(context.Background(), booking)
-			return nil, fmt.Errorf("booking cancelled: %w", ctx.Err())
-		default:
-			// Continue with booking
-		}
+	if bs.config.PaymentFlow == PaymentFlowPayFirst {
+		return bs.createPayFirst(ctx, booking, route, paymentMethod)
+	}
 
+	// 6. Record the booking saga so a crash midway can be compensated after restart
+	saga, err := bs.sagas.Start(ctx, booking.ID)
+	if err != nil {
+		bs.releaseSeats(ctx, booking.ID)
+		return nil, err
+	}
+
+	// 7. Book all segments for every passenger (compensated on failure)
+	for i := range route.Segments {
 		segment := &route.Segments[i]
 
-		// Calculate commission
-		basePrice := segment.Price
-		commission := bs.commissionSvc.CalculateCommission(segment.TransportType, basePrice)
-		totalPrice := basePrice + commission
+		for p := range passengers {
+			passenger := &passengers[p]
+
+			// Check for context cancellation
+			if err := ctx.Err(); err != nil {
+				bs.abort(ctx, saga, booking, "booking cancelled: "+err.Error())
+				return nil, fmt.Errorf("booking cancelled: %w", err)
+			}
+
+			// Book with provider
+			step, err := bs.sagas.BeginStep(ctx, saga, domain.SagaStepBookSegment, segment.ID, passenger.ID)
+			if err != nil {
+				bs.abort(ctx, saga, booking, err.Error())
+				return nil, err
+			}
+
+			ticketNumber, bookingRef, err := bs.providerBooking.BookSegment(ctx, segment, passenger)
+			if err != nil {
+				bs.sagas.FailStep(ctx, saga, step, err)
+				bs.abort(ctx, saga, booking, fmt.Sprintf("failed to book segment %d for passenger %d: %v", i+1, p+1, err))
+				return nil, fmt.Errorf("booking failed at segment %d (%s -> %s) for passenger %d: %w", i+1, segment.StartStop.City, segment.EndStop.City, p+1, err)
+			}
+
+			if err := bs.sagas.CompleteStep(ctx, saga, step, bookingRef); err != nil {
+				bs.abort(ctx, saga, booking, err.Error())
+				return nil, err
+			}
+
+			// Create booked segment
+			bookedSegment := bs.priceTicket(segment, passenger)
+			bookedSegment.TicketNumber = ticketNumber
+			bookedSegment.ProviderBookingRef = bookingRef
+			bookedSegment.BookingStatus = domain.BookingConfirmed
+
+			booking.AddSegment(bookedSegment)
+		}
+	}
 
-		// Book with provider
-		ticketNumber, bookingRef, err := bs.providerBooking.BookSegment(ctx, segment, &passenger)
-		if err != nil {
-			// ROLLBACK: Cancel all previously booked segments
-			bs.rollbackBookings(ctx, bookingRefs)
-			booking.MarkAsFailed(fmt.Sprintf("failed to book segment %d: %v", i+1, err))
-			bs.bookingRepo.Save(ctx, booking)
-			return nil, fmt.Errorf("booking failed at segment %d (%s -> %s): %w", i+1, segment.StartStop.City, segment.EndStop.City, err)
-		}
-
-		// Create booked segment
-		bookedSegment := domain.BookedSegment{
-			ID:                 utils.GenerateID(),
-			SegmentID:          segment.ID,
-			Provider:           segment.Provider,
-			TransportType:      segment.TransportType,
-			From:               segment.StartStop,
-			To:                 segment.EndStop,
-			DepartureTime:      segment.DepartureTime,
-			ArrivalTime:        segment.ArrivalTime,
-			TicketNumber:       ticketNumber,
-			Price:              basePrice,
-			Commission:         commission,
-			TotalPrice:         totalPrice,
-			BookingStatus:      domain.BookingConfirmed,
-			ProviderBookingRef: bookingRef,
-		}
-
-		bookedSegments = append(bookedSegments, bookedSegment)
-		bookingRefs = append(bookingRefs, bookingRef)
-		booking.AddSegment(bookedSegment)
-	}
-
-	// 5. Create payment
+	// 8. Create payment
 	grandTotal := booking.GrandTotal
 	payment := bs.paymentSvc.CreatePayment(booking.ID, grandTotal, paymentMethod)
+	payment.Receipt = bs.receipts.BookingReceipt(booking)
 	booking.Payment = payment
 
-	// 6. Process payment
+	// 9. Process payment
+	step, err := bs.sagas.BeginStep(ctx, saga, domain.SagaStepProcessPayment, "", "")
+	if err != nil {
+		bs.abort(ctx, saga, booking, err.Error())
+		return nil, err
+	}
+
 	if err := bs.paymentSvc.ProcessPayment(ctx, payment); err != nil {
-		// ROLLBACK: Cancel all booked segments
-		bs.rollbackBookings(ctx, bookingRefs)
-		booking.MarkAsFailed(fmt.Sprintf("payment failed: %v", err))
-		bs.bookingRepo.Save(ctx, booking)
+		bs.sagas.FailStep(ctx, saga, step, err)
+		bs.abort(ctx, saga, booking, fmt.Sprintf("payment failed: %v", err))
 		return nil, fmt.Errorf("payment processing failed: %w", err)
 	}
 
-	// 7. Check if payment requires redirect (YooKassa async flow)
+	if err := bs.sagas.CompleteStep(ctx, saga, step, payment.ID); err != nil {
+		bs.abort(ctx, saga, booking, err.Error())
+		return nil, err
+	}
+
+	// 10. Check if payment requires redirect (YooKassa async flow)
 	if payment.ConfirmationURL != "" {
-		// Payment pending - user needs to complete payment via redirect
-		booking.Status = domain.BookingPendingPayment
+		// Payment pending - user needs to complete payment via redirect; seats stay held
+		if err := booking.TransitionTo(domain.BookingPendingPayment, domain.ActorSystem, "awaiting payment confirmation"); err != nil {
+			return nil, err
+		}
 	} else {
 		// Payment completed immediately (mock gateway or instant confirmation)
-		booking.MarkAsConfirmed()
+		if err := booking.MarkAsConfirmed(domain.ActorSystem); err != nil {
+			return nil, err
+		}
+		if err := bs.seats.Confirm(ctx, booking.ID); err != nil {
+			return nil, fmt.Errorf("failed to confirm seats: %w", err)
+		}
+		bs.issuePolicy(ctx, booking)
 	}
 
-	// 8. Save booking
+	// 11. Save booking
 	if err := bs.bookingRepo.Save(ctx, booking); err != nil {
 		return nil, fmt.Errorf("failed to save booking: %w", err)
 	}
 
+	// 12. Close the saga; if this fails, recovery finds the saved booking and completes it
+	if err := bs.sagas.Complete(ctx, saga); err != nil {
+		return nil, err
+	}
+
 	return booking, nil
 }
 
+// createPayFirst prices the tickets and authorizes the payment without issuing anything
+// The tickets are issued once the gateway holds the funds: right away when the payment
+// needs no redirect, otherwise when the webhook or reconciliation reports the authorization.
+func (bs *BookingService) createPayFirst(ctx context.Context, booking *domain.Booking, route *domain.Route, paymentMethod domain.PaymentMethod) (*domain.Booking, error) {
+	for i := range route.Segments {
+		for p := range booking.Passengers {
+			ticket := bs.priceTicket(&route.Segments[i], &booking.Passengers[p])
+			ticket.BookingStatus = domain.BookingPending
+			booking.AddSegment(ticket)
+		}
+	}
+
+	payment := bs.paymentSvc.CreatePayment(booking.ID, booking.GrandTotal, paymentMethod)
+	payment.Receipt = bs.receipts.BookingReceipt(booking)
+	booking.Payment = payment
+
+	if err := bs.paymentSvc.Authorize(ctx, payment); err != nil {
+		bs.releaseSeats(ctx, booking.ID)
+		booking.MarkAsFailed(domain.ActorSystem, fmt.Sprintf("payment failed: %v", err))
+		bs.bookingRepo.Save(context.WithoutCancel(ctx), booking)
+		return nil, fmt.Errorf("payment processing failed: %w", err)
+	}
+
+	// Seats stay held while the customer authorizes the payment
+	if err := booking.TransitionTo(domain.BookingPendingPayment, domain.ActorSystem, "awaiting payment authorization"); err != nil {
+		return nil, err
+	}
+	if err := bs.bookingRepo.Save(ctx, booking); err != nil {
+		bs.releaseSeats(ctx, booking.ID)
+		return nil, fmt.Errorf("failed to save booking: %w", err)
+	}
+
+	if payment.Status == domain.PaymentAuthorized {
+		// Authorized without a redirect (mock gateway or saved card)
+		if err := bs.issueTickets(ctx, booking, domain.ActorSystem, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return booking, nil
+}
+
+// priceTicket prices a passenger's ticket on a segment: child/infant fare, then commission
+// The ticket is not issued yet.
+func (bs *BookingService) priceTicket(segment *domain.Segment, passenger *domain.Passenger) domain.BookedSegment {
+	basePrice := bs.fares.Fare(segment.TransportType, passenger.Type, segment.Price)
+	commission := bs.commissionSvc.CalculateCommission(segment.TransportType, basePrice)
+
+	return domain.BookedSegment{
+		ID:            utils.GenerateID(),
+		SegmentID:     segment.ID,
+		PassengerID:   passenger.ID,
+		Provider:      segment.Provider,
+		TransportType: segment.TransportType,
+		From:          segment.StartStop,
+		To:            segment.EndStop,
+		DepartureTime: segment.DepartureTime,
+		ArrivalTime:   segment.ArrivalTime,
+		Price:         basePrice,
+		Commission:    commission,
+		TotalPrice:    basePrice.Add(commission),
+		Source:        segment.Source,
+		Tariff:        segment.Tariff,
+	}
+}
+
+// abort compensates the saga and records the booking as failed
+// It runs even when ctx is cancelled: the compensations must still happen.
+func (bs *BookingService) abort(ctx context.Context, saga *domain.BookingSaga, booking *domain.Booking, reason string) {
+	ctx = context.WithoutCancel(ctx)
+
+	if err := bs.sagas.Compensate(ctx, saga, reason); err != nil {
+		// The saga stays compensating and is retried in the background
+		fmt.Printf("Warning: failed to compensate saga %s: %v\n", saga.ID, err)
+	}
+
+	booking.MarkAsFailed(domain.ActorSystem, reason)
+	bs.bookingRepo.Save(ctx, booking)
+}
+
+// validatePassengerMix checks that the lead passenger is an adult
+// and every infant has an adult lap to sit on
+func validatePassengerMix(passengers []domain.Passenger) error {
+	if passengers[0].Type != domain.PassengerAdult {
+		return domain.NewDomainError("INVALID_BOOKING", "The lead passenger must be an adult")
+	}
+	if passengers[0].Email == "" || passengers[0].Phone == "" {
+		return domain.NewDomainError("INVALID_BOOKING", "The lead passenger must have an email and a phone")
+	}
+
+	adults, infants := 0, 0
+	for _, passenger := range passengers {
+		switch passenger.Type {
+		case domain.PassengerAdult:
+			adults++
+		case domain.PassengerInfant:
+			infants++
+		}
+	}
+
+	if infants > adults {
+		return domain.NewDomainError("INVALID_BOOKING", "Each infant must travel with an adult")
+	}
+
+	return nil
+}
+
 // rollbackBookings cancels all provider bookings (ACID rollback)
 func (bs *BookingService) rollbackBookings(ctx context.Context, bookingRefs []string) {
 	for _, ref := range bookingRefs {
@@ -173,6 +385,291 @@ func (bs *BookingService) rollbackBookings(ctx context.Context, bookingRefs []st
 	}
 }
 
+// releaseSeats gives held seats back (best effort, the hold expires anyway)
+func (bs *BookingService) releaseSeats(ctx context.Context, bookingID string) {
+	if err := bs.seats.Release(ctx, bookingID); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: failed to release seats for booking %s: %v\n", bookingID, err)
+	}
+}
+
+// ConfirmPayment confirms a booking whose redirect payment succeeded
+// (reported by the payment webhook or found by reconciliation) and sells its held seats.
+// A paid-first booking gets its tickets issued first. When the holds expired and their
+// seats were sold meanwhile, the tickets are cancelled, the payment is refunded and the
+// booking fails.
+func (bs *BookingService) ConfirmPayment(ctx context.Context, booking *domain.Booking, actor, providerPaymentID string) error {
+	if booking.AwaitingTickets() {
+		// Captured outside the pay-first flow: issue the tickets, there is nothing left to capture
+		setProviderPaymentID(booking.Payment, providerPaymentID)
+		return bs.issueTickets(ctx, booking, actor, true)
+	}
+
+	// Check before selling the seats
+	if !domain.CanTransition(booking.Status, domain.BookingConfirmed) {
+		return &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingConfirmed}
+	}
+
+	if booking.Payment != nil {
+		booking.Payment.Status = domain.PaymentCompleted
+		now := time.Now()
+		booking.Payment.CompletedAt = &now
+		setProviderPaymentID(booking.Payment, providerPaymentID)
+	}
+
+	// Take the held seats off sale
+	if err := bs.seats.Confirm(ctx, booking.ID); err != nil {
+		var domainErr domain.DomainError
+		if !errors.As(err, &domainErr) || domainErr.Code != "SEATS_UNAVAILABLE" {
+			return fmt.Errorf("failed to confirm seats: %w", err)
+		}
+		bs.rollbackBookings(ctx, cancelTickets(booking))
+		return bs.failBooking(ctx, booking, actor, "Seats were sold while the payment was pending: "+domainErr.Message)
+	}
+
+	if err := booking.MarkAsConfirmed(actor); err != nil {
+		return err
+	}
+	bs.issuePolicy(ctx, booking)
+
+	return bs.UpdateBooking(ctx, booking)
+}
+
+// ReturnLatePayment gives back a payment that went through after its booking failed,
+// e.g. one the customer completed on the gateway's page after the booking expired
+// A captured payment is refunded, an authorized one is cancelled. A payment given
+// back already is left alone.
+func (bs *BookingService) ReturnLatePayment(ctx context.Context, booking *domain.Booking, providerPaymentID string, status domain.PaymentStatus) error {
+	payment := booking.Payment
+	if payment == nil || (payment.Status != domain.PaymentPending && payment.Status != domain.PaymentFailed) {
+		return nil
+	}
+	setProviderPaymentID(payment, providerPaymentID)
+
+	switch status {
+	case domain.PaymentAuthorized:
+		payment.Status = domain.PaymentAuthorized
+		if err := bs.paymentSvc.CancelAuthorization(ctx, payment); err != nil {
+			return fmt.Errorf("failed to cancel late payment of booking %s: %w", booking.ID, err)
+		}
+	case domain.PaymentCompleted:
+		now := time.Now()
+		payment.Status = domain.PaymentCompleted
+		payment.CompletedAt = &now
+		if err := bs.paymentSvc.RefundPayment(ctx, payment); err != nil {
+			return fmt.Errorf("failed to refund late payment of booking %s: %w", booking.ID, err)
+		}
+	default:
+		return nil
+	}
+
+	return bs.UpdateBooking(ctx, booking)
+}
+
+// issuePolicy issues the insurance policy of a confirmed booking (best effort,
+// a claim issues it when still missing)
+func (bs *BookingService) issuePolicy(ctx context.Context, booking *domain.Booking) {
+	if err := bs.insuranceSvc.IssuePolicy(ctx, booking); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: booking %s confirmed without its insurance policy: %v\n", booking.ID, err)
+	}
+}
+
+// AuthorizePayment issues the tickets of a paid-first booking whose payment is authorized
+// (reported by the payment webhook or found by reconciliation) and captures the payment.
+// If any ticket cannot be issued, the issued ones are cancelled and the authorization
+// with them, so the customer is never charged for a journey they did not get.
+func (bs *BookingService) AuthorizePayment(ctx context.Context, booking *domain.Booking, actor, providerPaymentID string) error {
+	if booking.Payment == nil || !booking.Payment.TwoStage {
+		return domain.NewDomainError("PAYMENT_FAILED", "Booking was not paid for in two stages")
+	}
+
+	setProviderPaymentID(booking.Payment, providerPaymentID)
+	return bs.issueTickets(ctx, booking, actor, false)
+}
+
+// issueTickets books every pending ticket with its provider, captures the payment
+// unless it was captured already, and confirms the booking
+func (bs *BookingService) issueTickets(ctx context.Context, booking *domain.Booking, actor string, captured bool) error {
+	// Check before booking anything with providers
+	if !domain.CanTransition(booking.Status, domain.BookingConfirmed) {
+		return &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingConfirmed}
+	}
+
+	if captured {
+		booking.Payment.Status = domain.PaymentCompleted
+	} else {
+		booking.Payment.Status = domain.PaymentAuthorized
+	}
+
+	saga, err := bs.sagas.Start(ctx, booking.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range booking.Segments {
+		ticket := &booking.Segments[i]
+		if ticket.BookingStatus != domain.BookingPending {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, "ticket issuing cancelled: "+err.Error())
+			return fmt.Errorf("ticket issuing cancelled: %w", err)
+		}
+
+		segment, err := bs.segmentRepo.FindByID(ctx, ticket.SegmentID)
+		if err == nil && segment.IsCancelled() {
+			err = fmt.Errorf("segment %s was cancelled by the carrier", ticket.SegmentID)
+		}
+		if err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, fmt.Sprintf("failed to issue ticket %s: %v", ticket.ID, err))
+			return fmt.Errorf("ticket issuing failed (%s -> %s): %w", ticket.From.City, ticket.To.City, err)
+		}
+
+		passenger := booking.FindPassenger(ticket.PassengerID)
+		if passenger == nil {
+			passenger = &booking.Passenger
+		}
+
+		step, err := bs.sagas.BeginStep(ctx, saga, domain.SagaStepBookSegment, segment.ID, passenger.ID)
+		if err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, err.Error())
+			return err
+		}
+
+		ticketNumber, bookingRef, err := bs.providerBooking.BookSegment(ctx, segment, passenger)
+		if err != nil {
+			bs.sagas.FailStep(ctx, saga, step, err)
+			bs.abortTickets(ctx, saga, booking, actor, fmt.Sprintf("failed to issue ticket %s: %v", ticket.ID, err))
+			return fmt.Errorf("ticket issuing failed (%s -> %s): %w", ticket.From.City, ticket.To.City, err)
+		}
+
+		if err := bs.sagas.CompleteStep(ctx, saga, step, bookingRef); err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, err.Error())
+			return err
+		}
+
+		ticket.TicketNumber = ticketNumber
+		ticket.ProviderBookingRef = bookingRef
+		ticket.BookingStatus = domain.BookingConfirmed
+	}
+
+	// Take the money only once every ticket is issued
+	if !captured {
+		step, err := bs.sagas.BeginStep(ctx, saga, domain.SagaStepCapturePayment, "", "")
+		if err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, err.Error())
+			return err
+		}
+
+		if err := bs.paymentSvc.Capture(ctx, booking.Payment); err != nil {
+			bs.sagas.FailStep(ctx, saga, step, err)
+			bs.abortTickets(ctx, saga, booking, actor, fmt.Sprintf("payment capture failed: %v", err))
+			return fmt.Errorf("payment capture failed: %w", err)
+		}
+
+		if err := bs.sagas.CompleteStep(ctx, saga, step, booking.Payment.ID); err != nil {
+			bs.abortTickets(ctx, saga, booking, actor, err.Error())
+			return err
+		}
+	}
+
+	if err := bs.ConfirmPayment(ctx, booking, actor, ""); err != nil {
+		return err
+	}
+
+	// Close the saga; if this fails, recovery finds the confirmed booking and completes it
+	return bs.sagas.Complete(ctx, saga)
+}
+
+// abortTickets undoes the tickets issued for a paid-first booking and fails it
+// It runs even when ctx is cancelled: the compensations must still happen.
+func (bs *BookingService) abortTickets(ctx context.Context, saga *domain.BookingSaga, booking *domain.Booking, actor, reason string) {
+	ctx = context.WithoutCancel(ctx)
+
+	// The saga cancels the issued tickets with their providers
+	if err := bs.sagas.Compensate(ctx, saga, reason); err != nil {
+		// The saga stays compensating and is retried in the background
+		fmt.Printf("Warning: failed to compensate saga %s: %v\n", saga.ID, err)
+	}
+
+	cancelTickets(booking)
+	if err := bs.failBooking(ctx, booking, actor, reason); err != nil {
+		fmt.Printf("Warning: failed to record booking %s as failed: %v\n", booking.ID, err)
+	}
+}
+
+// FailPayment fails a booking whose redirect payment was cancelled or never completed
+// The tickets already issued by providers are cancelled and the held seats given back.
+func (bs *BookingService) FailPayment(ctx context.Context, booking *domain.Booking, actor, reason string) error {
+	// Check before cancelling anything with providers
+	if !domain.CanTransition(booking.Status, domain.BookingFailed) {
+		return &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingFailed}
+	}
+
+	bs.rollbackBookings(ctx, cancelTickets(booking))
+	return bs.failBooking(ctx, booking, actor, reason)
+}
+
+// failBooking gives back the held seats and the customer's money and saves the booking as failed
+// An authorized payment is cancelled; a captured payment is refunded.
+func (bs *BookingService) failBooking(ctx context.Context, booking *domain.Booking, actor, reason string) error {
+	bs.releaseSeats(ctx, booking.ID)
+
+	if err := booking.MarkAsFailed(actor, reason); err != nil {
+		return err
+	}
+
+	if payment := booking.Payment; payment != nil {
+		switch {
+		case payment.Status == domain.PaymentAuthorized:
+			if err := bs.paymentSvc.CancelAuthorization(ctx, payment); err != nil {
+				// The gateway releases authorizations that are never captured
+				fmt.Printf("Warning: failed to cancel payment authorization of booking %s: %v\n", booking.ID, err)
+			}
+		case payment.Status == domain.PaymentCompleted:
+			if err := bs.paymentSvc.RefundPayment(ctx, payment); err != nil {
+				// The payment stays completed so the refund can be made manually
+				fmt.Printf("Warning: failed to refund payment of booking %s: %v\n", booking.ID, err)
+			}
+		}
+
+		if payment.Status != domain.PaymentCompleted && payment.Status != domain.PaymentRefunded {
+			payment.Status = domain.PaymentFailed
+			payment.FailureReason = reason
+		}
+	}
+
+	return bs.UpdateBooking(ctx, booking)
+}
+
+// cancelTickets marks the booking's active tickets as cancelled
+// Returns the provider references of the tickets that were issued.
+func cancelTickets(booking *domain.Booking) []string {
+	now := time.Now()
+	bookingRefs := make([]string, 0, len(booking.Segments))
+	for i := range booking.Segments {
+		segment := &booking.Segments[i]
+		if segment.BookingStatus == domain.BookingCancelled {
+			continue
+		}
+		if segment.ProviderBookingRef != "" {
+			bookingRefs = append(bookingRefs, segment.ProviderBookingRef)
+		}
+		segment.BookingStatus = domain.BookingCancelled
+		segment.CancelledAt = &now
+	}
+	return bookingRefs
+}
+
+// setProviderPaymentID records the gateway's payment ID once it is known
+func setProviderPaymentID(payment *domain.Payment, providerPaymentID string) {
+	if payment != nil && providerPaymentID != "" {
+		payment.ProviderPaymentID = providerPaymentID
+	}
+}
+
 // GetBooking retrieves a booking by ID
 func (bs *BookingService) GetBooking(ctx context.Context, bookingID string) (*domain.Booking, error) {
 	return bs.bookingRepo.FindByID(ctx, bookingID)
@@ -185,35 +682,224 @@ func (bs *BookingService) UpdateBooking(ctx context.Context, booking *domain.Boo
 }
 
 // CancelBooking cancels a booking and processes refund
-func (bs *BookingService) CancelBooking(ctx context.Context, bookingID string, reason string) error {
+func (bs *BookingService) CancelBooking(ctx context.Context, bookingID, actor, reason string) error {
 	booking, err := bs.bookingRepo.FindByID(ctx, bookingID)
 	if err != nil {
 		return fmt.Errorf("booking not found: %w", err)
 	}
 
-	if booking.Status != domain.BookingConfirmed {
-		return fmt.Errorf("cannot cancel booking with status: %s", booking.Status)
+	// Check before cancelling anything with providers
+	if !domain.CanTransition(booking.Status, domain.BookingCancelled) {
+		return &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingCancelled}
+	}
+
+	rules, err := bs.fareRules.Rules(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Cancel all segment bookings
+	// Cancel the segment bookings that are still active, priced by their fare rules
+	now := time.Now()
+	refundTotal := domain.Money{Currency: domain.SettlementCurrency}
+	refunds := make([]domain.SegmentRefund, 0, len(booking.Segments))
+	departed := false
 	bookingRefs := make([]string, 0, len(booking.Segments))
-	for _, segment := range booking.Segments {
+	for i := range booking.Segments {
+		segment := &booking.Segments[i]
+		departed = departed || !segment.DepartureTime.After(now)
+		if segment.BookingStatus == domain.BookingCancelled {
+			continue
+		}
+
+		refund := bs.fareRules.Refund(rules, segment, now)
+		refundTotal = refundTotal.Add(refund.Amount)
+		refunds = append(refunds, refund)
+
 		bookingRefs = append(bookingRefs, segment.ProviderBookingRef)
+		segment.BookingStatus = domain.BookingCancelled
+		segment.RefundAmount = refund.Amount
+		segment.CancelledAt = &now
 	}
 	bs.rollbackBookings(ctx, bookingRefs)
+	bs.releaseSeats(ctx, booking.ID)
 
-	// Process refund
-	if booking.Payment != nil && booking.Payment.Status == domain.PaymentCompleted {
-		if err := bs.paymentSvc.RefundPayment(ctx, booking.Payment); err != nil {
+	// Insurance is refunded while the journey has not started
+	refundInsurance := booking.IncludeInsurance && !departed
+	if refundInsurance {
+		refundTotal = refundTotal.Add(booking.InsurancePremium)
+		if err := bs.insuranceSvc.CancelPolicy(ctx, booking); err != nil {
+			// In production, this should be logged and monitored
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	// Refund the active tickets (earlier ticket cancellations were refunded already)
+	if booking.RefundableAmount().IsPositive() {
+		amount := domain.MinMoney(refundTotal, booking.RefundableAmount())
+		receipt := bs.receipts.RefundReceipt(booking, refunds, refundInsurance)
+		if err := bs.paymentSvc.RefundBooking(ctx, booking, amount, receipt); err != nil {
 			return fmt.Errorf("refund failed: %w", err)
 		}
 	}
 
 	// Mark as cancelled
-	booking.MarkAsCancelled(reason)
+	if err := booking.MarkAsCancelled(actor, reason); err != nil {
+		return err
+	}
 	return bs.bookingRepo.Update(ctx, booking)
 }
 
+// CancelSegment cancels a single ticket of a confirmed booking and refunds it
+// The refund is the fare minus the penalty of the ticket's fare rule, plus our
+// commission when cancelled early enough; one the gateway fails stays pending on the
+// ticket and is retried by RetryRefunds.
+// The booking becomes partially_cancelled, or cancelled once no tickets are left.
+func (bs *BookingService) CancelSegment(ctx context.Context, bookingID, bookedSegmentID, actor, reason string) (*domain.Booking, *domain.SegmentRefund, error) {
+	booking, err := bs.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check before cancelling anything with the provider
+	if !domain.CanTransition(booking.Status, domain.BookingPartiallyCancelled) {
+		return nil, nil, &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingPartiallyCancelled}
+	}
+
+	segment, ok := booking.FindSegment(bookedSegmentID)
+	if !ok {
+		return nil, nil, domain.ErrSegmentNotFound
+	}
+	if segment.BookingStatus == domain.BookingCancelled {
+		return nil, nil, domain.NewDomainError("SEGMENT_NOT_CANCELLABLE", "Ticket is already cancelled")
+	}
+	now := time.Now()
+	if !segment.DepartureTime.After(now) {
+		return nil, nil, domain.NewDomainError("SEGMENT_NOT_CANCELLABLE", "Ticket cannot be cancelled after departure")
+	}
+
+	rules, err := bs.fareRules.Rules(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Cancel with the provider first: no refund for a ticket that is still valid
+	if err := bs.providerBooking.CancelBooking(ctx, segment.ProviderBookingRef); err != nil {
+		return nil, nil, fmt.Errorf("provider cancellation failed: %w", err)
+	}
+
+	refund := bs.fareRules.Refund(rules, segment, now)
+	if booking.RefundableAmount().IsPositive() {
+		refund.Amount = domain.MinMoney(refund.Amount, booking.RefundableAmount())
+	} else {
+		refund.Amount = domain.Money{Currency: refund.Amount.Currency}
+	}
+
+	segment.BookingStatus = domain.BookingCancelled
+	segment.RefundAmount = refund.Amount
+	segment.RefundPending = refund.Amount.IsPositive()
+	segment.CancelledAt = &now
+
+	if err := bs.seats.ReturnSeats(ctx, booking.ID, segment.SegmentID, bs.seatsFor(booking, segment)); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: failed to return seat on segment %s: %v\n", segment.SegmentID, err)
+	}
+
+	status := domain.BookingPartiallyCancelled
+	if len(booking.ActiveSegments()) == 0 {
+		status = domain.BookingCancelled
+	}
+	if err := booking.TransitionTo(status, actor, reason); err != nil {
+		return nil, nil, err
+	}
+
+	// The ticket is gone at the carrier: record that before refunding, so a refund
+	// that fails stays pending for RetryRefunds instead of being lost
+	if err := bs.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, nil, fmt.Errorf("failed to update booking: %w", err)
+	}
+
+	if segment.RefundPending {
+		if err := bs.refundTicket(ctx, booking, segment, refund); err != nil {
+			// In production, this should be logged and monitored
+			fmt.Printf("Warning: refund of ticket %s failed, retrying later: %v\n", segment.ID, err)
+			return booking, &refund, nil
+		}
+		if err := bs.bookingRepo.Update(ctx, booking); err != nil {
+			return nil, nil, fmt.Errorf("failed to update booking: %w", err)
+		}
+	}
+
+	return booking, &refund, nil
+}
+
+// RetryRefunds makes the refunds of cancelled tickets that failed at the gateway
+// Returns how many tickets were refunded; the rest stay pending for the next run.
+func (bs *BookingService) RetryRefunds(ctx context.Context) (int, error) {
+	bookings, err := bs.bookingRepo.FindWithPendingRefunds(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rules, err := bs.fareRules.Rules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	for i := range bookings {
+		booking := &bookings[i]
+		changed := false
+		for j := range booking.Segments {
+			segment := &booking.Segments[j]
+			if !segment.RefundPending || segment.CancelledAt == nil {
+				continue
+			}
+
+			// Priced as at the cancellation, for the receipt's split of fare and commission
+			refund := bs.fareRules.Refund(rules, segment, *segment.CancelledAt)
+			refund.Amount = segment.RefundAmount
+			if err := bs.refundTicket(ctx, booking, segment, refund); err != nil {
+				// In production, this should be logged and monitored
+				fmt.Printf("Warning: refund of ticket %s failed again: %v\n", segment.ID, err)
+				continue
+			}
+			refunded++
+			changed = true
+		}
+
+		if changed {
+			if err := bs.bookingRepo.Update(ctx, booking); err != nil {
+				return refunded, fmt.Errorf("failed to update booking: %w", err)
+			}
+		}
+	}
+
+	return refunded, nil
+}
+
+// refundTicket pays back the refund of a cancelled ticket over the booking's payments
+func (bs *BookingService) refundTicket(ctx context.Context, booking *domain.Booking, segment *domain.BookedSegment, refund domain.SegmentRefund) error {
+	if amount := domain.MinMoney(segment.RefundAmount, booking.RefundableAmount()); amount.IsPositive() {
+		receipt := bs.receipts.RefundReceipt(booking, []domain.SegmentRefund{refund}, false)
+		if err := bs.paymentSvc.RefundBooking(ctx, booking, amount, receipt); err != nil {
+			return fmt.Errorf("refund failed: %w", err)
+		}
+	}
+
+	segment.RefundPending = false
+	return nil
+}
+
+// seatsFor returns how many seats a ticket occupies (infants and walks take none)
+func (bs *BookingService) seatsFor(booking *domain.Booking, segment *domain.BookedSegment) int {
+	if !segment.TransportType.HasSeats() {
+		return 0
+	}
+	if passenger := booking.FindPassenger(segment.PassengerID); passenger != nil {
+		return domain.SeatsRequired([]domain.Passenger{*passenger})
+	}
+	return 1
+}
+
 // ListBookings returns all bookings (for admin)
 func (bs *BookingService) ListBookings(ctx context.Context) ([]domain.Booking, error) {
 	return bs.bookingRepo.FindAll(ctx)
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository/memory"
+)
+
+// fakeRefundGateway fails refunds while down and records the ones made
+type fakeRefundGateway struct {
+	PaymentGateway
+	down     bool
+	refunded []domain.Money
+}
+
+func (g *fakeRefundGateway) RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error {
+	if g.down {
+		return errors.New("gateway unavailable")
+	}
+	g.refunded = append(g.refunded, amount)
+	return nil
+}
+
+func TestCancelSegmentKeepsFailedRefundPending(t *testing.T) {
+	ctx := context.Background()
+	departure := time.Now().Add(72 * time.Hour)
+
+	bookings := memory.NewBookingRepository()
+	ticket := func(id string, departure time.Time) domain.BookedSegment {
+		return domain.BookedSegment{
+			ID:                 id,
+			PassengerID:        "p1",
+			TransportType:      domain.TransportBus,
+			DepartureTime:      departure,
+			ArrivalTime:        departure.Add(10 * time.Hour),
+			Price:              domain.Rubles(3000),
+			TotalPrice:         domain.Rubles(3000),
+			ProviderBookingRef: "ref-" + id,
+			BookingStatus:      domain.BookingConfirmed,
+		}
+	}
+	booking := &domain.Booking{
+		ID:         "b1",
+		Status:     domain.BookingConfirmed,
+		Passengers: []domain.Passenger{{ID: "p1", FirstName: "Иван"}},
+		Segments:   []domain.BookedSegment{ticket("t1", departure), ticket("t2", departure.Add(12*time.Hour))},
+		Payment:    &domain.Payment{ID: "pay-b1", Amount: domain.Rubles(6000), Method: domain.PaymentYooKassa, Status: domain.PaymentCompleted},
+	}
+	if err := bookings.Save(ctx, booking); err != nil {
+		t.Fatal(err)
+	}
+
+	gateway := &fakeRefundGateway{down: true}
+	commission := NewCommissionService(DefaultCommissionConfig())
+	provider := &fakeProviderBooking{failing: make(map[string]bool)}
+	svc := NewBookingService(nil, nil, bookings, nil, commission, nil, NewPaymentService(NewGatewayRegistry(gateway)), provider,
+		NewSeatInventoryService(&fakeSeatRepo{}, DefaultSeatHoldConfig()), nil, NewFareRuleService(&fakeFareRuleRepo{}, commission), DefaultBookingConfig())
+
+	// The ticket is cancelled at the carrier, but the gateway fails the refund
+	_, refund, err := svc.CancelSegment(ctx, "b1", "t1", domain.ActorCustomer, "plans changed")
+	if err != nil {
+		t.Fatalf("expected the cancellation to stand, got %v", err)
+	}
+	if len(provider.cancelled) != 1 || !refund.Amount.IsPositive() {
+		t.Fatalf("expected the ticket cancelled with a refund, got %v and %s", provider.cancelled, refund.Amount)
+	}
+
+	saved, _ := bookings.FindByID(ctx, "b1")
+	cancelled, _ := saved.FindSegment("t1")
+	if saved.Status != domain.BookingPartiallyCancelled || cancelled.BookingStatus != domain.BookingCancelled || !cancelled.RefundPending {
+		t.Fatalf("expected the cancelled ticket saved with its refund pending, got %s / %s", saved.Status, cancelled.BookingStatus)
+	}
+
+	// Still down: the refund stays pending
+	if refunded, err := svc.RetryRefunds(ctx); err != nil || refunded != 0 {
+		t.Fatalf("expected nothing refunded while the gateway is down, got %d (%v)", refunded, err)
+	}
+
+	gateway.down = false
+	if refunded, err := svc.RetryRefunds(ctx); err != nil || refunded != 1 {
+		t.Fatalf("expected the pending refund to be made, got %d (%v)", refunded, err)
+	}
+	if len(gateway.refunded) != 1 || gateway.refunded[0] != refund.Amount {
+		t.Fatalf("expected %s refunded once, got %v", refund.Amount, gateway.refunded)
+	}
+
+	saved, _ = bookings.FindByID(ctx, "b1")
+	cancelled, _ = saved.FindSegment("t1")
+	if cancelled.RefundPending || saved.Payment.RefundedAmount != refund.Amount {
+		t.Fatalf("expected the refund recorded, got %s refunded", saved.Payment.RefundedAmount)
+	}
+	if refunded, _ := svc.RetryRefunds(ctx); refunded != 0 {
+		t.Fatalf("expected the ticket to be refunded once, refunded again %d", refunded)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// cityDirectoryTTL is how long the in-memory city directory is used before reloading
+const cityDirectoryTTL = 10 * time.Minute
+
+// CityResolver maps city names, transliterations and IATA/RZD codes to canonical cities
+type CityResolver struct {
+	cityRepo repository.CityRepository
+
+	mu        sync.RWMutex
+	directory *domain.CityDirectory
+	loadedAt  time.Time
+}
+
+// NewCityResolver creates a new city resolver
+func NewCityResolver(cityRepo repository.CityRepository) *CityResolver {
+	return &CityResolver{cityRepo: cityRepo}
+}
+
+// Resolve finds the canonical city for a name, alias or code
+func (r *CityResolver) Resolve(ctx context.Context, input string) (*domain.City, bool) {
+	directory := r.load(ctx)
+	if directory == nil {
+		return nil, false
+	}
+	return directory.Resolve(input)
+}
+
+// ResolveCriteria replaces the cities in the criteria with their canonical names and IDs
+// Unknown cities are left as typed so they can still match stops by name.
+func (r *CityResolver) ResolveCriteria(ctx context.Context, criteria *domain.RouteSearchCriteria) error {
+	if from, ok := r.Resolve(ctx, criteria.FromCity); ok {
+		criteria.FromCity = from.Name
+		criteria.FromCityID = from.ID
+	}
+	if to, ok := r.Resolve(ctx, criteria.ToCity); ok {
+		criteria.ToCity = to.Name
+		criteria.ToCityID = to.ID
+	}
+
+	if criteria.FromCityID != "" && criteria.FromCityID == criteria.ToCityID {
+		return fmt.Errorf("from_city and to_city cannot be the same")
+	}
+
+	return nil
+}
+
+// load returns the cached directory, reloading it from the repository when stale
+// A failed reload keeps serving the previous directory; without one,
+// cities are matched by name as typed.
+func (r *CityResolver) load(ctx context.Context) *domain.CityDirectory {
+	r.mu.RLock()
+	directory, loadedAt := r.directory, r.loadedAt
+	r.mu.RUnlock()
+
+	if directory != nil && time.Since(loadedAt) < cityDirectoryTTL {
+		return directory
+	}
+
+	cities, err := r.cityRepo.FindAll(ctx)
+	if err != nil {
+		return directory
+	}
+
+	directory = domain.NewCityDirectory(cities)
+
+	r.mu.Lock()
+	r.directory = directory
+	r.loadedAt = time.Now()
+	r.mu.Unlock()
+
+	return directory
+}
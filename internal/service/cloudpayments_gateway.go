@@ -0,0 +1,316 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// errCloudPaymentsNotFound is returned when CloudPayments has no transaction for an invoice yet
+var errCloudPaymentsNotFound = errors.New("cloudpayments transaction not found")
+
+// CloudPaymentsGateway implements PaymentGateway for CloudPayments
+// The customer pays on a CloudPayments order page. Until then CloudPayments knows the
+// payment by our invoice ID (the payment ID); once paid, by its transaction ID.
+type CloudPaymentsGateway struct {
+	baseURL   string
+	publicID  string
+	apiSecret string
+	client    *http.Client
+}
+
+// NewCloudPaymentsGateway creates a new CloudPayments payment gateway
+func NewCloudPaymentsGateway(baseURL, publicID, apiSecret string) *CloudPaymentsGateway {
+	return &CloudPaymentsGateway{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		publicID:  publicID,
+		apiSecret: apiSecret,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// cloudPaymentsResponse is the envelope of every CloudPayments API response
+type cloudPaymentsResponse struct {
+	Success bool            `json:"Success"`
+	Message string          `json:"Message"`
+	Model   json.RawMessage `json:"Model"`
+}
+
+// cloudPaymentsTransaction is a payment or refund transaction
+type cloudPaymentsTransaction struct {
+	TransactionID        int64           `json:"TransactionId"`
+	PaymentTransactionID int64           `json:"PaymentTransactionId"` // Refunds: the refunded payment
+	Amount               float64         `json:"Amount"`
+	Currency             string          `json:"Currency"`
+	InvoiceID            string          `json:"InvoiceId"`
+	Status               string          `json:"Status"`
+	JSONData             json.RawMessage `json:"JsonData"`
+}
+
+// amount returns the transaction's amount; CloudPayments reports it in major units
+func (t *cloudPaymentsTransaction) amount() (domain.Money, error) {
+	currency, err := domain.ParseCurrency(t.Currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	return domain.MoneyFromFloat(t.Amount, currency), nil
+}
+
+// ProcessPayment creates an order (payment page) in CloudPayments
+func (g *CloudPaymentsGateway) ProcessPayment(ctx context.Context, payment *domain.Payment) error {
+	request := map[string]interface{}{
+		"Amount":              payment.Amount.Major(),
+		"Currency":            payment.Amount.Currency,
+		"Description":         fmt.Sprintf("LenaLink: Бронирование %s", payment.OrderID),
+		"InvoiceId":           payment.ID,
+		"RequireConfirmation": payment.TwoStage, // Two-stage payments are confirmed after the tickets are issued
+		"JsonData": map[string]string{
+			"order_id":   payment.OrderID,
+			"payment_id": payment.ID,
+		},
+	}
+
+	var order struct {
+		ID  string `json:"Id"`
+		URL string `json:"Url"`
+	}
+	if err := g.call(ctx, "/orders/create", request, &order); err != nil {
+		return fmt.Errorf("cloudpayments create order failed: %w", err)
+	}
+
+	// Payment is pending until the customer pays on the order page
+	payment.ConfirmationURL = order.URL
+	payment.Status = domain.PaymentPending
+
+	return nil
+}
+
+// RedirectsCustomer marks the gateway as redirect-only: CloudPayments orders are paid on the order page
+func (g *CloudPaymentsGateway) RedirectsCustomer() {}
+
+// RefundPayment refunds a paid transaction in CloudPayments
+// Receipts are not sent: CloudPayments issues them through CloudKassir, configured separately.
+func (g *CloudPaymentsGateway) RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error {
+	transactionID, err := g.transactionID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("cloudpayments refund failed: %w", err)
+	}
+
+	request := map[string]interface{}{"TransactionId": transactionID, "Amount": amount.Major()}
+	if err := g.call(ctx, "/payments/refund", request, nil); err != nil {
+		return fmt.Errorf("cloudpayments refund failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetPaymentStatus retrieves payment status from CloudPayments
+func (g *CloudPaymentsGateway) GetPaymentStatus(ctx context.Context, paymentID string) (domain.PaymentStatus, error) {
+	transaction, err := g.transaction(ctx, paymentID)
+	if errors.Is(err, errCloudPaymentsNotFound) {
+		// The customer has not paid on the order page yet
+		return domain.PaymentPending, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cloudpayments get payment failed: %w", err)
+	}
+
+	return cloudPaymentsStatus(transaction.Status), nil
+}
+
+// CapturePayment confirms an authorized (two-stage) transaction in CloudPayments
+func (g *CloudPaymentsGateway) CapturePayment(ctx context.Context, paymentID string, amount domain.Money) error {
+	transactionID, err := g.transactionID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("cloudpayments confirm payment failed: %w", err)
+	}
+
+	request := map[string]interface{}{"TransactionId": transactionID, "Amount": amount.Major()}
+	if err := g.call(ctx, "/payments/confirm", request, nil); err != nil {
+		return fmt.Errorf("cloudpayments confirm payment failed: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPayment voids an authorized (two-stage) transaction in CloudPayments
+func (g *CloudPaymentsGateway) CancelPayment(ctx context.Context, paymentID string) error {
+	transactionID, err := g.transactionID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("cloudpayments void payment failed: %w", err)
+	}
+
+	request := map[string]interface{}{"TransactionId": transactionID}
+	if err := g.call(ctx, "/payments/void", request, nil); err != nil {
+		return fmt.Errorf("cloudpayments void payment failed: %w", err)
+	}
+
+	return nil
+}
+
+// FindPayment retrieves a payment transaction from CloudPayments
+func (g *CloudPaymentsGateway) FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error) {
+	transaction, err := g.transaction(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("cloudpayments get payment failed: %w", err)
+	}
+
+	amount, err := transaction.amount()
+	if err != nil {
+		return nil, fmt.Errorf("cloudpayments transaction %d: %w", transaction.TransactionID, err)
+	}
+
+	return &GatewayPayment{
+		ID:      strconv.FormatInt(transaction.TransactionID, 10),
+		OrderID: cloudPaymentsOrderID(transaction.JSONData),
+		Status:  cloudPaymentsStatus(transaction.Status),
+		Amount:  amount,
+	}, nil
+}
+
+// FindRefund retrieves a refund transaction from CloudPayments
+func (g *CloudPaymentsGateway) FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error) {
+	transaction, err := g.transaction(ctx, refundID)
+	if err != nil {
+		return nil, fmt.Errorf("cloudpayments get refund failed: %w", err)
+	}
+
+	amount, err := transaction.amount()
+	if err != nil {
+		return nil, fmt.Errorf("cloudpayments transaction %d: %w", transaction.TransactionID, err)
+	}
+
+	return &GatewayRefund{
+		ID:        strconv.FormatInt(transaction.TransactionID, 10),
+		PaymentID: strconv.FormatInt(transaction.PaymentTransactionID, 10),
+		Succeeded: transaction.Status == "Completed",
+		Amount:    amount,
+	}, nil
+}
+
+// VerifyNotification checks the Content-HMAC header: the body signed with the API secret
+func (g *CloudPaymentsGateway) VerifyNotification(body []byte, signature string) error {
+	mac := hmac.New(sha256.New, []byte(g.apiSecret))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("invalid CloudPayments notification signature")
+	}
+	return nil
+}
+
+// transaction fetches a transaction by its ID, or the latest transaction of an invoice
+func (g *CloudPaymentsGateway) transaction(ctx context.Context, id string) (*cloudPaymentsTransaction, error) {
+	path, request := "/v2/payments/find", map[string]interface{}{"InvoiceId": id}
+	if transactionID, err := strconv.ParseInt(id, 10, 64); err == nil {
+		path, request = "/payments/get", map[string]interface{}{"TransactionId": transactionID}
+	}
+
+	var transaction cloudPaymentsTransaction
+	if err := g.call(ctx, path, request, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// transactionID resolves a payment ID (transaction or invoice) to the transaction ID
+func (g *CloudPaymentsGateway) transactionID(ctx context.Context, id string) (int64, error) {
+	if transactionID, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return transactionID, nil
+	}
+
+	transaction, err := g.transaction(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return transaction.TransactionID, nil
+}
+
+// call posts a request to the CloudPayments API and decodes the response model
+// Declined transactions come back unsuccessful but with their model, which is decoded.
+func (g *CloudPaymentsGateway) call(ctx context.Context, path string, request interface{}, model interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.SetBasicAuth(g.publicID, g.apiSecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var envelope cloudPaymentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	hasModel := len(envelope.Model) > 0 && string(envelope.Model) != "null"
+	if !envelope.Success && !hasModel {
+		if envelope.Message == "Not found" {
+			return errCloudPaymentsNotFound
+		}
+		return fmt.Errorf("request rejected: %s", envelope.Message)
+	}
+
+	if model != nil && hasModel {
+		if err := json.Unmarshal(envelope.Model, model); err != nil {
+			return fmt.Errorf("decode model: %w", err)
+		}
+	}
+	return nil
+}
+
+// cloudPaymentsOrderID reads the order ID from a transaction's JsonData
+// CloudPayments returns it either as an object or as a JSON-encoded string.
+func cloudPaymentsOrderID(data json.RawMessage) string {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		data = json.RawMessage(encoded)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return ""
+	}
+	orderID, _ := metadata["order_id"].(string)
+	return orderID
+}
+
+// cloudPaymentsStatus maps a CloudPayments transaction status to the domain status
+func cloudPaymentsStatus(status string) domain.PaymentStatus {
+	switch status {
+	case "Authorized":
+		return domain.PaymentAuthorized
+	case "Completed":
+		return domain.PaymentCompleted
+	case "Cancelled", "Declined":
+		return domain.PaymentFailed
+	default:
+		// AwaitingAuthentication (3-D Secure)
+		return domain.PaymentPending
+	}
+}
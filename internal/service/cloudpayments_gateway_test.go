@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// fakeCloudPayments serves the CloudPayments API methods the gateway uses
+func fakeCloudPayments(t *testing.T, requests map[string]map[string]interface{}) *httptest.Server {
+	transaction := map[string]interface{}{
+		"TransactionId": 504,
+		"Amount":        1500.50,
+		"Currency":      "RUB",
+		"InvoiceId":     "pay-1",
+		"Status":        "Authorized",
+		"JsonData":      `{"order_id":"booking-1","payment_id":"pay-1"}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "pk_test" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var request map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("%s: invalid request body: %v", r.URL.Path, err)
+		}
+		requests[r.URL.Path] = request
+
+		response := map[string]interface{}{"Success": true}
+		switch r.URL.Path {
+		case "/orders/create":
+			response["Model"] = map[string]interface{}{"Id": "order-1", "Url": "https://orders.cloudpayments.ru/d/order-1"}
+		case "/v2/payments/find":
+			if request["InvoiceId"] != "pay-1" {
+				response = map[string]interface{}{"Success": false, "Message": "Not found"}
+				break
+			}
+			response["Model"] = transaction
+		case "/payments/get":
+			response["Model"] = transaction
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestCloudPaymentsGateway(t *testing.T) {
+	ctx := context.Background()
+	requests := make(map[string]map[string]interface{})
+	server := fakeCloudPayments(t, requests)
+	defer server.Close()
+
+	gateway := NewCloudPaymentsGateway(server.URL, "pk_test", "secret")
+
+	payment := &domain.Payment{ID: "pay-1", OrderID: "booking-1", Amount: domain.Rubles(1500.50), TwoStage: true}
+	if err := gateway.ProcessPayment(ctx, payment); err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+	if payment.ConfirmationURL != "https://orders.cloudpayments.ru/d/order-1" || payment.Status != domain.PaymentPending {
+		t.Fatalf("unexpected payment after creating the order: %+v", payment)
+	}
+	if order := requests["/orders/create"]; order["InvoiceId"] != "pay-1" || order["RequireConfirmation"] != true {
+		t.Fatalf("two-stage order not requested for the payment: %v", order)
+	}
+
+	// Before the first transaction the payment is known by its invoice
+	status, err := gateway.GetPaymentStatus(ctx, "pay-1")
+	if err != nil || status != domain.PaymentAuthorized {
+		t.Fatalf("expected the invoice's transaction to be authorized, got %s (%v)", status, err)
+	}
+	if status, err := gateway.GetPaymentStatus(ctx, "pay-unpaid"); err != nil || status != domain.PaymentPending {
+		t.Fatalf("expected an unpaid invoice to be pending, got %s (%v)", status, err)
+	}
+
+	remote, err := gateway.FindPayment(ctx, "504")
+	if err != nil {
+		t.Fatalf("FindPayment: %v", err)
+	}
+	if remote.ID != "504" || remote.OrderID != "booking-1" || remote.Amount != domain.Rubles(1500.50) {
+		t.Fatalf("unexpected payment: %+v", remote)
+	}
+
+	// Capturing by invoice resolves the transaction first
+	if err := gateway.CapturePayment(ctx, "pay-1", domain.Rubles(1500.50)); err != nil {
+		t.Fatalf("CapturePayment: %v", err)
+	}
+	if confirm := requests["/payments/confirm"]; confirm["TransactionId"] != float64(504) || confirm["Amount"] != 1500.50 {
+		t.Fatalf("unexpected confirm request: %v", confirm)
+	}
+	if err := gateway.RefundPayment(ctx, "504", domain.Rubles(500), nil); err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	if refund := requests["/payments/refund"]; refund["TransactionId"] != float64(504) || refund["Amount"] != float64(500) {
+		t.Fatalf("unexpected refund request: %v", refund)
+	}
+
+	if err := NewCloudPaymentsGateway(server.URL, "pk_test", "wrong").CancelPayment(ctx, "504"); err == nil {
+		t.Fatal("expected a request with wrong credentials to fail")
+	}
+}
+
+func TestCloudPaymentsNotificationSignature(t *testing.T) {
+	gateway := NewCloudPaymentsGateway("http://localhost", "pk_test", "secret")
+	body := []byte("TransactionId=504&Amount=1500.50&Status=Completed")
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if err := gateway.VerifyNotification(body, signature); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+	if err := gateway.VerifyNotification([]byte("TransactionId=504&Amount=1.00&Status=Completed"), signature); err == nil {
+		t.Fatal("expected a tampered body to be rejected")
+	}
+	if err := gateway.VerifyNotification(body, ""); err == nil {
+		t.Fatal("expected an unsigned notification to be rejected")
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/lenalink/backend/internal/domain"
 )
 
@@ -13,6 +15,7 @@ type CommissionConfig struct {
 	TaxiCommissionRate    float64
 	WalkCommissionRate    float64
 	DefaultCommissionRate float64
+	RefundBefore          time.Duration // Markup is refunded for tickets cancelled at least this long before departure
 }
 
 // DefaultCommissionConfig returns default commission rates
@@ -25,6 +28,7 @@ func DefaultCommissionConfig() CommissionConfig {
 		TaxiCommissionRate:    0.15, // 15% for taxi
 		WalkCommissionRate:    0.00, // 0% for walking (free)
 		DefaultCommissionRate: 0.07, // 7% default
+		RefundBefore:          24 * time.Hour,
 	}
 }
 
@@ -39,36 +43,45 @@ func NewCommissionService(config CommissionConfig) *CommissionService {
 }
 
 // CalculateCommission calculates commission for a segment
-func (cs *CommissionService) CalculateCommission(transportType domain.TransportType, basePrice float64) float64 {
+func (cs *CommissionService) CalculateCommission(transportType domain.TransportType, basePrice domain.Money) domain.Money {
 	rate := cs.getCommissionRate(transportType)
-	return basePrice * rate
+	return basePrice.Mul(rate)
 }
 
 // CalculateTotalPrice calculates total price including commission
-func (cs *CommissionService) CalculateTotalPrice(transportType domain.TransportType, basePrice float64) float64 {
+func (cs *CommissionService) CalculateTotalPrice(transportType domain.TransportType, basePrice domain.Money) domain.Money {
 	commission := cs.CalculateCommission(transportType, basePrice)
-	return basePrice + commission
+	return basePrice.Add(commission)
 }
 
 // CalculateCommissionForSegment calculates commission for a route segment
-func (cs *CommissionService) CalculateCommissionForSegment(segment *domain.Segment) (basePrice, commission, totalPrice float64) {
+func (cs *CommissionService) CalculateCommissionForSegment(segment *domain.Segment) (basePrice, commission, totalPrice domain.Money) {
 	basePrice = segment.Price
 	commission = cs.CalculateCommission(segment.TransportType, basePrice)
-	totalPrice = basePrice + commission
+	totalPrice = basePrice.Add(commission)
 	return basePrice, commission, totalPrice
 }
 
 // CalculateRouteCommission calculates total commission for entire route
-func (cs *CommissionService) CalculateRouteCommission(route *domain.Route) (basePrice, totalCommission, grandTotal float64) {
+func (cs *CommissionService) CalculateRouteCommission(route *domain.Route) (basePrice, totalCommission, grandTotal domain.Money) {
 	for i := range route.Segments {
 		segmentBase, segmentCommission, _ := cs.CalculateCommissionForSegment(&route.Segments[i])
-		basePrice += segmentBase
-		totalCommission += segmentCommission
+		basePrice = basePrice.Add(segmentBase)
+		totalCommission = totalCommission.Add(segmentCommission)
 	}
-	grandTotal = basePrice + totalCommission
+	grandTotal = basePrice.Add(totalCommission)
 	return basePrice, totalCommission, grandTotal
 }
 
+// RefundableCommission returns how much of a ticket's commission is refunded
+// when it is cancelled the given time before departure: all of it early enough, otherwise none
+func (cs *CommissionService) RefundableCommission(commission domain.Money, before time.Duration) domain.Money {
+	if before >= cs.config.RefundBefore {
+		return commission
+	}
+	return domain.Money{Currency: commission.Currency}
+}
+
 // getCommissionRate returns commission rate for transport type
 func (cs *CommissionService) getCommissionRate(transportType domain.TransportType) float64 {
 	switch transportType {
@@ -76,9 +89,9 @@ func (cs *CommissionService) getCommissionRate(transportType domain.TransportTyp
 		return cs.config.AirCommissionRate
 	case domain.TransportRail:
 		return cs.config.RailCommissionRate
-	case domain.TransportBus:
+	case domain.TransportBus, domain.TransportIceRoad:
 		return cs.config.BusCommissionRate
-	case domain.TransportRiver:
+	case domain.TransportRiver, domain.TransportFerry:
 		return cs.config.RiverCommissionRate
 	case domain.TransportTaxi:
 		return cs.config.TaxiCommissionRate
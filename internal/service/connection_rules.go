@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/graph"
+)
+
+// connectionRule applies the minimum connection time table during routing
+type connectionRule struct {
+	table *domain.MinConnectionTable
+}
+
+// MinTransferTime implements routing.TransferRule
+func (r connectionRule) MinTransferTime(node *graph.Node, inbound, outbound *graph.Edge) time.Duration {
+	return r.table.MinConnectionTime(
+		node.ID,
+		node.City,
+		domain.TransportType(inbound.TransportType),
+		domain.TransportType(outbound.TransportType),
+	)
+}
+
+// minConnectionFile is the JSON layout of a minimum connection time table
+type minConnectionFile struct {
+	DefaultMinutes int                        `json:"default_minutes"`
+	Rules          []domain.MinConnectionRule `json:"rules"`
+}
+
+// LoadMinConnectionTable reads minimum connection time rules from a JSON file:
+//
+//	{"default_minutes": 60, "rules": [{"inbound": "air", "outbound": "river", "min_minutes": 120}]}
+//
+// If default_minutes is omitted, defaultTime is used.
+func LoadMinConnectionTable(path string, defaultTime time.Duration) (*domain.MinConnectionTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading connection rules: %w", err)
+	}
+
+	var file minConnectionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing connection rules: %w", err)
+	}
+
+	table := &domain.MinConnectionTable{
+		Default: defaultTime,
+		Rules:   file.Rules,
+	}
+	if file.DefaultMinutes > 0 {
+		table.Default = time.Duration(file.DefaultMinutes) * time.Minute
+	}
+
+	for i, rule := range table.Rules {
+		if rule.MinMinutes < 0 {
+			return nil, fmt.Errorf("connection rule %d: min_minutes cannot be negative", i)
+		}
+	}
+
+	return table, nil
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/internal/routing"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// Notifier delivers messages to passengers (email, SMS, push)
+type Notifier interface {
+	// NotifyDisruption tells a passenger that their journey was disrupted and what was done about it
+	NotifyDisruption(ctx context.Context, passenger *domain.Passenger, disruption *domain.Disruption) error
+}
+
+// DisruptionConfig holds parameters for disruption handling
+type DisruptionConfig struct {
+	CheckInterval   time.Duration              // How often booked journeys are checked against synced segments
+	AutoRebook      bool                       // Move passengers to an alternative running the same legs without asking
+	Alternatives    int                        // Maximum number of alternatives offered per disruption
+	ConnectionRules *domain.MinConnectionTable // Minimum connection times a delay is checked against
+}
+
+// DefaultDisruptionConfig returns default disruption handling configuration
+func DefaultDisruptionConfig() DisruptionConfig {
+	return DisruptionConfig{
+		CheckInterval:   10 * time.Minute,
+		AutoRebook:      true,
+		Alternatives:    3,
+		ConnectionRules: domain.DefaultMinConnectionTable(),
+	}
+}
+
+// DisruptionService watches booked journeys for cancelled and delayed segments
+// A broken journey is re-routed from the disrupted point to the passenger's destination.
+// When an alternative runs the same legs as the lost tickets the passenger is rebooked
+// onto it free of charge, otherwise the alternatives are offered. Either way the
+// passenger is notified.
+type DisruptionService struct {
+	bookingRepo repository.BookingRepository
+	segmentRepo repository.SegmentRepository
+	disruptions repository.DisruptionRepository
+	routes      *RouteService
+	bookings    *BookingService
+	notifier    Notifier
+	config      DisruptionConfig
+}
+
+// NewDisruptionService creates a new disruption service
+func NewDisruptionService(
+	bookingRepo repository.BookingRepository,
+	segmentRepo repository.SegmentRepository,
+	disruptions repository.DisruptionRepository,
+	routes *RouteService,
+	bookings *BookingService,
+	notifier Notifier,
+	config DisruptionConfig,
+) *DisruptionService {
+	return &DisruptionService{
+		bookingRepo: bookingRepo,
+		segmentRepo: segmentRepo,
+		disruptions: disruptions,
+		routes:      routes,
+		bookings:    bookings,
+		notifier:    notifier,
+		config:      config,
+	}
+}
+
+// CheckInterval returns how often booked journeys should be checked
+func (s *DisruptionService) CheckInterval() time.Duration {
+	return s.config.CheckInterval
+}
+
+// CheckBookings checks confirmed and partially cancelled bookings for disruptions
+// Journeys that have arrived are skipped, and the segments of the rest are loaded in
+// one query. Returns the number of new disruptions handled. A booking that cannot be
+// checked is skipped and checked again next time.
+func (s *DisruptionService) CheckBookings(ctx context.Context) (int, error) {
+	now := time.Now()
+	travelling := make([]domain.Booking, 0)
+	for _, status := range []domain.BookingStatus{domain.BookingConfirmed, domain.BookingPartiallyCancelled} {
+		bookings, err := s.bookingRepo.FindByStatus(ctx, status)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load %s bookings: %w", status, err)
+		}
+		for _, booking := range bookings {
+			if booking.LastArrival().After(now) {
+				travelling = append(travelling, booking)
+			}
+		}
+	}
+	if len(travelling) == 0 {
+		return 0, nil
+	}
+
+	current, err := s.currentSegments(ctx, travelling...)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for i := range travelling {
+		count, err := s.checkBooking(ctx, &travelling[i], current)
+		if err != nil {
+			// In production, this should be logged and monitored
+			fmt.Printf("Warning: failed to check booking %s for disruptions: %v\n", travelling[i].ID, err)
+		}
+		handled += count
+	}
+
+	return handled, nil
+}
+
+// CheckBooking compares a booking's tickets with the latest synced segments
+// and handles disruptions that have not been recorded yet
+func (s *DisruptionService) CheckBooking(ctx context.Context, booking *domain.Booking) (int, error) {
+	current, err := s.currentSegments(ctx, *booking)
+	if err != nil {
+		return 0, err
+	}
+	return s.checkBooking(ctx, booking, current)
+}
+
+// currentSegments loads the latest synced segments of the bookings' active tickets
+// A segment that is no longer synced maps to nil: the carrier dropped it.
+func (s *DisruptionService) currentSegments(ctx context.Context, bookings ...domain.Booking) (map[string]*domain.Segment, error) {
+	current := make(map[string]*domain.Segment)
+	ids := make([]string, 0)
+	for _, booking := range bookings {
+		for _, ticket := range booking.ActiveSegments() {
+			if _, seen := current[ticket.SegmentID]; !seen {
+				current[ticket.SegmentID] = nil
+				ids = append(ids, ticket.SegmentID)
+			}
+		}
+	}
+
+	segments, err := s.segmentRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load booked segments: %w", err)
+	}
+	for i := range segments {
+		current[segments[i].ID] = &segments[i]
+	}
+
+	return current, nil
+}
+
+// checkBooking handles the disruptions of a booking found against current
+func (s *DisruptionService) checkBooking(ctx context.Context, booking *domain.Booking, current map[string]*domain.Segment) (int, error) {
+	detected := domain.DetectDisruptions(booking, current, s.config.ConnectionRules, time.Now())
+	if len(detected) == 0 {
+		return 0, nil
+	}
+
+	known, err := s.disruptions.FindByBooking(ctx, booking.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for i := range detected {
+		if isKnownDisruption(known, &detected[i]) {
+			continue
+		}
+		if err := s.handle(ctx, booking, &detected[i]); err != nil {
+			return handled, err
+		}
+		handled++
+	}
+
+	return handled, nil
+}
+
+// GetDisruptions returns the disruptions of a booking, oldest first
+func (s *DisruptionService) GetDisruptions(ctx context.Context, bookingID string) ([]domain.Disruption, error) {
+	if _, err := s.bookingRepo.FindByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return s.disruptions.FindByBooking(ctx, bookingID)
+}
+
+// handle re-routes a disrupted journey, rebooks the passenger when possible,
+// notifies them and records the disruption
+func (s *DisruptionService) handle(ctx context.Context, booking *domain.Booking, disruption *domain.Disruption) error {
+	disruption.ID = utils.GenerateID()
+	disruption.DetectedAt = time.Now()
+
+	alternatives, err := s.reroute(ctx, booking, disruption)
+	if err != nil {
+		return fmt.Errorf("re-routing from %s failed: %w", disruption.FromCity, err)
+	}
+	disruption.Alternatives = alternatives
+	if len(alternatives) > 0 {
+		disruption.Status = domain.DisruptionProposed
+	}
+
+	if s.config.AutoRebook {
+		if requests := rebookingRequests(booking, disruption); requests != nil {
+			_, change, err := s.bookings.RebookDisrupted(ctx, booking.ID, requests, disruptionReason(disruption))
+			if err != nil {
+				// The alternatives are still offered to the passenger
+				fmt.Printf("Warning: failed to rebook booking %s after disruption: %v\n", booking.ID, err)
+			} else {
+				disruption.Status = domain.DisruptionRebooked
+				disruption.ChangeID = change.ID
+			}
+		}
+	}
+
+	passenger := booking.FindPassenger(disruption.PassengerID)
+	if passenger == nil {
+		passenger = &booking.Passenger
+	}
+	if err := s.notifier.NotifyDisruption(ctx, passenger, disruption); err != nil {
+		fmt.Printf("Warning: failed to notify passenger of booking %s: %v\n", booking.ID, err)
+	} else {
+		now := time.Now()
+		disruption.NotifiedAt = &now
+	}
+
+	if err := s.disruptions.Save(ctx, disruption); err != nil {
+		return fmt.Errorf("failed to record disruption: %w", err)
+	}
+	return nil
+}
+
+// reroute searches journeys from the disrupted point to the passenger's destination
+// The party travels on together, so alternatives must seat everyone in the booking.
+// Returns no alternatives when nothing reaches the destination.
+func (s *DisruptionService) reroute(ctx context.Context, booking *domain.Booking, disruption *domain.Disruption) ([]domain.Route, error) {
+	if disruption.FromCity == disruption.ToCity {
+		return nil, nil
+	}
+
+	criteria := &domain.RouteSearchCriteria{
+		FromCity:       disruption.FromCity,
+		ToCity:         disruption.ToCity,
+		FromCityID:     disruption.FromCityID,
+		ToCityID:       disruption.ToCityID,
+		DepartureDate:  disruption.DepartAfter,
+		PassengerCount: max(domain.SeatsRequired(booking.Passengers), 1),
+		Mode:           domain.SearchModeLive,
+		Alternatives:   s.config.Alternatives,
+	}
+
+	result, err := s.routes.SearchRoutes(ctx, criteria)
+	if errors.Is(err, routing.ErrNoPath) || errors.Is(err, routing.ErrUnknownNode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Alternatives, nil
+}
+
+// rebookingRequests pairs the lost tickets with the segments of the first alternative
+// running the same legs, or returns nil if no alternative does
+func rebookingRequests(booking *domain.Booking, disruption *domain.Disruption) []domain.ChangeRequest {
+	for _, route := range disruption.Alternatives {
+		if len(route.Segments) != len(disruption.Replaces) {
+			continue
+		}
+
+		requests := make([]domain.ChangeRequest, 0, len(route.Segments))
+		for i, ticketID := range disruption.Replaces {
+			ticket, ok := booking.FindSegment(ticketID)
+			if !ok || !ticket.SameLeg(&route.Segments[i]) {
+				break
+			}
+			requests = append(requests, domain.ChangeRequest{BookedSegmentID: ticketID, SegmentID: route.Segments[i].ID})
+		}
+		if len(requests) == len(disruption.Replaces) {
+			return requests
+		}
+	}
+	return nil
+}
+
+// isKnownDisruption checks if the disruption was handled by an earlier check
+func isKnownDisruption(known []domain.Disruption, disruption *domain.Disruption) bool {
+	for i := range known {
+		if known[i].Matches(disruption) {
+			return true
+		}
+	}
+	return false
+}
+
+// disruptionReason describes the disruption for the booking change history
+func disruptionReason(disruption *domain.Disruption) string {
+	if disruption.Type == domain.DisruptionDelayed {
+		return fmt.Sprintf("Segment %s delayed by %s, connection in %s missed", disruption.SegmentID, disruption.Delay, disruption.FromCity)
+	}
+	return fmt.Sprintf("Segment %s cancelled by the carrier", disruption.SegmentID)
+}
+
+// --- Mock Notifier for MVP/Hackathon ---
+
+// MockNotifier prints notifications instead of sending them
+type MockNotifier struct{}
+
+// NewMockNotifier creates a mock notifier
+func NewMockNotifier() *MockNotifier {
+	return &MockNotifier{}
+}
+
+// NotifyDisruption prints the message the passenger would receive
+func (mn *MockNotifier) NotifyDisruption(ctx context.Context, passenger *domain.Passenger, disruption *domain.Disruption) error {
+	message := disruptionReason(disruption)
+	switch disruption.Status {
+	case domain.DisruptionRebooked:
+		message += ". You have been rebooked free of charge."
+	case domain.DisruptionProposed:
+		message += fmt.Sprintf(". %d alternative journeys to %s are available.", len(disruption.Alternatives), disruption.ToCity)
+	default:
+		message += fmt.Sprintf(". No alternative journey to %s was found, please contact support.", disruption.ToCity)
+	}
+
+	fmt.Printf("Notification to %s <%s>: %s\n", passenger.FirstName, passenger.Email, message)
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/internal/repository/memory"
+)
+
+// fakeDisruptionSegmentRepo serves synced segments and records which were asked for
+type fakeDisruptionSegmentRepo struct {
+	repository.SegmentRepository
+	segments  map[string]domain.Segment
+	requested []string
+}
+
+func (r *fakeDisruptionSegmentRepo) FindByIDs(ctx context.Context, ids []string) ([]domain.Segment, error) {
+	r.requested = append(r.requested, ids...)
+	segments := make([]domain.Segment, 0, len(ids))
+	for _, id := range ids {
+		if segment, ok := r.segments[id]; ok {
+			segments = append(segments, segment)
+		}
+	}
+	return segments, nil
+}
+
+type fakeDisruptionRepo struct {
+	saved []domain.Disruption
+}
+
+func (r *fakeDisruptionRepo) Save(ctx context.Context, disruption *domain.Disruption) error {
+	r.saved = append(r.saved, *disruption)
+	return nil
+}
+
+func (r *fakeDisruptionRepo) FindByBooking(ctx context.Context, bookingID string) ([]domain.Disruption, error) {
+	disruptions := make([]domain.Disruption, 0)
+	for _, disruption := range r.saved {
+		if disruption.BookingID == bookingID {
+			disruptions = append(disruptions, disruption)
+		}
+	}
+	return disruptions, nil
+}
+
+type fakeNotifier struct {
+	notified []string
+}
+
+func (n *fakeNotifier) NotifyDisruption(ctx context.Context, passenger *domain.Passenger, disruption *domain.Disruption) error {
+	n.notified = append(n.notified, disruption.BookingID)
+	return nil
+}
+
+func TestCheckBookingsThroughRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	yakutsk := domain.Stop{ID: "yks", City: "Якутск"}
+	mirny := domain.Stop{ID: "mjz", City: "Мирный"}
+	lensk := domain.Stop{ID: "ulk", City: "Ленск"}
+
+	ticket := func(id, segmentID string, from, to domain.Stop, departure time.Time) domain.BookedSegment {
+		return domain.BookedSegment{
+			ID:            id,
+			SegmentID:     segmentID,
+			PassengerID:   "p1",
+			TransportType: domain.TransportBus,
+			From:          from,
+			To:            to,
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(2 * time.Hour),
+			BookingStatus: domain.BookingConfirmed,
+		}
+	}
+	bookings := memory.NewBookingRepository()
+	save := func(booking *domain.Booking) {
+		booking.Passengers = []domain.Passenger{{ID: "p1", FirstName: "Иван"}}
+		if err := bookings.Save(ctx, booking); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Travelling tomorrow; the carrier dropped the first leg
+	save(&domain.Booking{ID: "travelling", Status: domain.BookingConfirmed, Segments: []domain.BookedSegment{
+		ticket("t1", "air", yakutsk, mirny, now.Add(24*time.Hour)),
+		ticket("t2", "bus", mirny, lensk, now.Add(28*time.Hour)),
+	}})
+	// Arrived yesterday: nothing left to check
+	save(&domain.Booking{ID: "arrived", Status: domain.BookingConfirmed, Segments: []domain.BookedSegment{
+		ticket("t3", "old", yakutsk, mirny, now.Add(-48*time.Hour)),
+	}})
+
+	segments := &fakeDisruptionSegmentRepo{segments: map[string]domain.Segment{
+		"bus": {ID: "bus", StartStop: mirny, EndStop: lensk, DepartureTime: now.Add(28 * time.Hour), ArrivalTime: now.Add(30 * time.Hour)},
+	}}
+	disruptions := &fakeDisruptionRepo{}
+	notifier := &fakeNotifier{}
+	routes := NewRouteService(nil, nil, nil, nil, nil, DefaultRouteSearchConfig())
+	svc := NewDisruptionService(bookings, segments, disruptions, routes, nil, notifier, DefaultDisruptionConfig())
+
+	handled, err := svc.CheckBookings(ctx)
+	if err != nil || handled != 1 {
+		t.Fatalf("expected one disruption handled, got %d (%v)", handled, err)
+	}
+	if len(disruptions.saved) != 1 || disruptions.saved[0].BookingID != "travelling" || disruptions.saved[0].Type != domain.DisruptionCancelled {
+		t.Fatalf("expected the dropped flight to be recorded, got %+v", disruptions.saved)
+	}
+	if len(notifier.notified) != 1 {
+		t.Fatalf("expected the passenger to be notified, got %v", notifier.notified)
+	}
+	for _, id := range segments.requested {
+		if id == "old" {
+			t.Fatal("expected the arrived journey to be skipped")
+		}
+	}
+
+	// The next check finds nothing new
+	if handled, err := svc.CheckBookings(ctx); err != nil || handled != 0 {
+		t.Fatalf("expected the disruption to be handled once, got %d (%v)", handled, err)
+	}
+}
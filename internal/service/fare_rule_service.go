@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// FareRuleService looks up refund and change conditions and prices cancellations
+type FareRuleService struct {
+	repo          repository.FareRuleRepository
+	commissionSvc *CommissionService
+	defaults      domain.FareRules
+}
+
+// NewFareRuleService creates a new fare rule service
+func NewFareRuleService(repo repository.FareRuleRepository, commissionSvc *CommissionService) *FareRuleService {
+	return &FareRuleService{
+		repo:          repo,
+		commissionSvc: commissionSvc,
+		defaults:      domain.DefaultFareRules(),
+	}
+}
+
+// Rules returns the provider rules from the database with the transport type defaults
+func (s *FareRuleService) Rules(ctx context.Context) (domain.FareRules, error) {
+	rules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return domain.FareRules{}, fmt.Errorf("failed to load fare rules: %w", err)
+	}
+
+	return domain.FareRules{Rules: rules, Defaults: s.defaults.Defaults}, nil
+}
+
+// RouteRules returns the rule of every segment of a route, in segment order
+func (s *FareRuleService) RouteRules(ctx context.Context, route *domain.Route) ([]domain.FareRule, error) {
+	rules, err := s.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentRules := make([]domain.FareRule, len(route.Segments))
+	for i := range route.Segments {
+		segmentRules[i] = rules.ForSegment(&route.Segments[i])
+	}
+	return segmentRules, nil
+}
+
+// Refund prices the cancellation of a ticket at the given time:
+// the fare minus the carrier penalty, plus the commission if cancelled early enough
+func (s *FareRuleService) Refund(rules domain.FareRules, segment *domain.BookedSegment, at time.Time) domain.SegmentRefund {
+	refund := rules.RefundFor(segment, at)
+	refund.RefundCommission(s.commissionSvc.RefundableCommission(segment.Commission, segment.DepartureTime.Sub(at)))
+	return refund
+}
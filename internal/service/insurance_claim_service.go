@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// InsuranceClaimConfig holds parameters for insurance claims
+type InsuranceClaimConfig struct {
+	ConnectionRules *domain.MinConnectionTable // Minimum connection times a late arrival is checked against
+	Receipts        ReceiptConfig              // VAT rates of the payout's refund receipt
+}
+
+// DefaultInsuranceClaimConfig returns default insurance claim configuration
+func DefaultInsuranceClaimConfig() InsuranceClaimConfig {
+	return InsuranceClaimConfig{
+		ConnectionRules: domain.DefaultMinConnectionTable(),
+		Receipts:        DefaultReceiptConfig(),
+	}
+}
+
+// InsuranceClaimService handles claims under the insurance policies of bookings
+// A missed connection is checked against the inbound segment as last synced: the claim
+// is approved when the segment arrived late and left less than the minimum connection
+// time. The missed ticket is then paid out through a refund of the booking's payment,
+// up to what the policy still covers, and cancelled. Rejected claims are recorded with the reason.
+// An approved claim is recorded as pending before the insurer is called, so that a ticket
+// is paid out once and a payout that fails half way stays on record.
+type InsuranceClaimService struct {
+	bookingRepo repository.BookingRepository
+	segmentRepo repository.SegmentRepository
+	claims      repository.InsuranceClaimRepository
+	insurance   *InsuranceService
+	bookings    *BookingService
+	paymentSvc  *PaymentService
+	receipts    *ReceiptService
+	config      InsuranceClaimConfig
+}
+
+// NewInsuranceClaimService creates a new insurance claim service
+func NewInsuranceClaimService(
+	bookingRepo repository.BookingRepository,
+	segmentRepo repository.SegmentRepository,
+	claims repository.InsuranceClaimRepository,
+	insurance *InsuranceService,
+	bookings *BookingService,
+	paymentSvc *PaymentService,
+	config InsuranceClaimConfig,
+) *InsuranceClaimService {
+	return &InsuranceClaimService{
+		bookingRepo: bookingRepo,
+		segmentRepo: segmentRepo,
+		claims:      claims,
+		insurance:   insurance,
+		bookings:    bookings,
+		paymentSvc:  paymentSvc,
+		receipts:    NewReceiptService(config.Receipts),
+		config:      config,
+	}
+}
+
+// ClaimMissedConnection files a claim for a ticket missed because the passenger's
+// previous leg arrived late
+func (s *InsuranceClaimService) ClaimMissedConnection(ctx context.Context, bookingID, bookedSegmentID string) (*domain.InsuranceClaim, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !booking.IncludeInsurance {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Booking is not insured")
+	}
+	if booking.Payment == nil || booking.Payment.Status != domain.PaymentCompleted {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Booking has no completed payment to pay the claim out through")
+	}
+	// The missed ticket is cancelled once paid out
+	if !domain.CanTransition(booking.Status, domain.BookingPartiallyCancelled) {
+		return nil, &domain.TransitionError{BookingID: booking.ID, From: booking.Status, To: domain.BookingPartiallyCancelled}
+	}
+
+	missed, ok := booking.FindSegment(bookedSegmentID)
+	if !ok {
+		return nil, domain.ErrSegmentNotFound
+	}
+	if missed.BookingStatus == domain.BookingCancelled {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Ticket was cancelled")
+	}
+	inbound, ok := booking.InboundTicket(missed)
+	if !ok {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Ticket is the first leg of the journey, there is no connection to miss")
+	}
+
+	// A policy that failed to be issued on confirmation is issued now
+	issued := booking.Policy == nil
+	if err := s.insurance.IssuePolicy(ctx, booking); err != nil {
+		return nil, err
+	}
+	policy := booking.Policy
+	if !policy.Covers(missed.DepartureTime) {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", fmt.Sprintf("Insurance policy %s does not cover the ticket", policy.Number))
+	}
+
+	claims, err := s.claims.FindByBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		if claim.BookedSegmentID == missed.ID && claim.Status != domain.ClaimRejected {
+			return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Claim for this ticket was made already")
+		}
+	}
+
+	arrived, err := s.segmentRepo.FindByID(ctx, inbound.SegmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load arrival of segment %s: %w", inbound.SegmentID, err)
+	}
+	if arrived.IsCancelled() {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Inbound segment was cancelled by the carrier, the journey is handled as a disruption")
+	}
+	now := time.Now()
+	if arrived.ArrivalTime.After(now) {
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", "Inbound segment has not arrived yet")
+	}
+
+	connection, delay, missedConnection := domain.CheckMissedConnection(inbound, arrived, missed, s.config.ConnectionRules)
+	claim := &domain.InsuranceClaim{
+		ID:               utils.GenerateID(),
+		BookingID:        booking.ID,
+		PolicyNumber:     policy.Number,
+		Type:             domain.ClaimMissedConnection,
+		PassengerID:      missed.PassengerID,
+		BookedSegmentID:  missed.ID,
+		InboundTicketID:  inbound.ID,
+		ScheduledArrival: inbound.ArrivalTime,
+		ActualArrival:    arrived.ArrivalTime,
+		Delay:            delay,
+		Connection:       &connection,
+		Payout:           domain.Money{Currency: domain.SettlementCurrency},
+		CreatedAt:        now,
+	}
+
+	payout := domain.MinMoney(domain.MinMoney(missed.TotalPrice, policy.Remaining()), booking.RefundableAmount())
+	switch {
+	case delay <= 0:
+		claim.Status = domain.ClaimRejected
+		claim.RejectionReason = "Inbound segment arrived on time"
+	case !missedConnection:
+		claim.Status = domain.ClaimRejected
+		claim.RejectionReason = fmt.Sprintf("Arrived %s late, leaving %s to connect (minimum %s)",
+			delay, connection.Gap, connection.MinConnectionTime)
+	case !payout.IsPositive():
+		claim.Status = domain.ClaimRejected
+		claim.RejectionReason = fmt.Sprintf("Insurance policy %s has no coverage left", policy.Number)
+	}
+	if issued {
+		if err := s.bookings.UpdateBooking(ctx, booking); err != nil {
+			return nil, fmt.Errorf("failed to save insurance policy: %w", err)
+		}
+	}
+	if claim.Status == domain.ClaimRejected {
+		if err := s.claims.Save(ctx, claim); err != nil {
+			return nil, err
+		}
+		return claim, nil
+	}
+
+	// Approved: recorded first, so that the ticket cannot be claimed again while it is paid out
+	claim.Status = domain.ClaimPending
+	claim.Payout = payout
+	if err := s.claims.Save(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	// The insurer settles the claim...
+	if err := s.insurance.SubmitClaim(ctx, policy, claim); err != nil {
+		claim.Status = domain.ClaimRejected
+		claim.Payout = domain.Money{Currency: domain.SettlementCurrency}
+		claim.RejectionReason = err.Error()
+		s.updateClaim(ctx, claim)
+		return nil, domain.NewDomainError("CLAIM_NOT_ALLOWED", err.Error())
+	}
+	s.updateClaim(ctx, claim)
+
+	// ...and the customer gets the missed ticket back; a failed payout stays pending
+	if err := s.paymentSvc.RefundBooking(ctx, booking, payout, s.payoutReceipt(booking, missed, payout)); err != nil {
+		return nil, domain.NewDomainError("PAYMENT_FAILED", fmt.Sprintf("Payout of claim %s failed: %v", claim.ID, err))
+	}
+	policy.PaidOut = policy.PaidOut.Add(payout)
+
+	// The missed ticket is settled: later cancellations must not refund it again
+	missed.BookingStatus = domain.BookingCancelled
+	missed.RefundAmount = payout
+	missed.CancelledAt = &now
+
+	status := domain.BookingPartiallyCancelled
+	if len(booking.ActiveSegments()) == 0 {
+		status = domain.BookingCancelled
+	}
+	if err := booking.TransitionTo(status, domain.ActorSystem, fmt.Sprintf("Missed connection paid out by claim %s", claim.ID)); err != nil {
+		return nil, err
+	}
+
+	if err := s.bookings.UpdateBooking(ctx, booking); err != nil {
+		return nil, fmt.Errorf("failed to save booking after claim payout: %w", err)
+	}
+
+	claim.Status = domain.ClaimPaid
+	if err := s.claims.Update(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+// updateClaim records the progress of a pending claim (best effort, the claim stays pending)
+func (s *InsuranceClaimService) updateClaim(ctx context.Context, claim *domain.InsuranceClaim) {
+	if err := s.claims.Update(ctx, claim); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: failed to update insurance claim %s: %v\n", claim.ID, err)
+	}
+}
+
+// payoutReceipt builds the refund receipt of a claim payout: the missed ticket's fare,
+// then its commission as far as the payout reaches
+func (s *InsuranceClaimService) payoutReceipt(booking *domain.Booking, missed *domain.BookedSegment, payout domain.Money) *domain.Receipt {
+	fare := domain.MinMoney(missed.Price, payout)
+	refund := domain.SegmentRefund{
+		BookedSegmentID:    missed.ID,
+		Fare:               missed.Price,
+		CommissionRefunded: payout.Sub(fare),
+		Amount:             payout,
+	}
+	return s.receipts.RefundReceipt(booking, []domain.SegmentRefund{refund}, false)
+}
+
+// GetClaims returns the insurance claims made for a booking
+func (s *InsuranceClaimService) GetClaims(ctx context.Context, bookingID string) ([]domain.InsuranceClaim, error) {
+	if _, err := s.bookingRepo.FindByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return s.claims.FindByBooking(ctx, bookingID)
+}
@@ -1,45 +1,107 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/pkg/utils"
 )
 
+// InsuranceProvider issues travel insurance policies and settles claims under them
+type InsuranceProvider interface {
+	// IssuePolicy issues a policy for a confirmed booking with insurance
+	IssuePolicy(ctx context.Context, booking *domain.Booking) (*domain.InsurancePolicy, error)
+	// SubmitClaim reports an approved claim to the insurer, returning the insurer's claim reference
+	SubmitClaim(ctx context.Context, policy *domain.InsurancePolicy, claim *domain.InsuranceClaim) (string, error)
+	// CancelPolicy cancels a policy whose premium was refunded
+	CancelPolicy(ctx context.Context, policy *domain.InsurancePolicy) error
+}
+
 // InsuranceConfig holds insurance calculation parameters
 type InsuranceConfig struct {
-	BasePremiumRate         float64 // Base premium as percentage (e.g., 0.05 = 5%)
-	TightConnectionSurcharge float64 // Surcharge for connections < 2 hours
-	NightFlightSurcharge    float64 // Surcharge for night flights (22:00-06:00)
-	RiverTransportSurcharge float64 // Surcharge for river transport (weather risks)
-	MultiSegmentSurcharge   float64 // Surcharge for routes with 3+ segments
+	BasePremiumRate          float64 // Base premium as percentage (e.g., 0.05 = 5%)
+	TightConnectionSurcharge float64 // Surcharge for connections close to the minimum connection time
+	NightFlightSurcharge     float64 // Surcharge for night flights (22:00-06:00)
+	RiverTransportSurcharge  float64 // Surcharge for river transport (weather risks)
+	MultiSegmentSurcharge    float64 // Surcharge for routes with 3+ segments
+
+	// A connection is tight if its gap is less than the minimum connection time plus this buffer
+	TightConnectionBuffer time.Duration
+	ConnectionRules       *domain.MinConnectionTable
 }
 
 // DefaultInsuranceConfig returns default insurance configuration
 func DefaultInsuranceConfig() InsuranceConfig {
 	return InsuranceConfig{
-		BasePremiumRate:         0.05,  // 5% base premium
-		TightConnectionSurcharge: 0.01,  // +1% per tight connection
-		NightFlightSurcharge:    0.005, // +0.5% for night flights
-		RiverTransportSurcharge: 0.02,  // +2% for river transport
-		MultiSegmentSurcharge:   0.01,  // +1% for 3+ segments
+		BasePremiumRate:          0.05,             // 5% base premium
+		TightConnectionSurcharge: 0.01,             // +1% per tight connection
+		NightFlightSurcharge:     0.005,            // +0.5% for night flights
+		RiverTransportSurcharge:  0.02,             // +2% for river transport
+		MultiSegmentSurcharge:    0.01,             // +1% for 3+ segments
+		TightConnectionBuffer:    60 * time.Minute, // 2 hours with the default 1-hour MCT
+		ConnectionRules:          domain.DefaultMinConnectionTable(),
 	}
 }
 
-// InsuranceService calculates insurance premiums for bookings
+// InsuranceService calculates insurance premiums for bookings and issues their policies
 type InsuranceService struct {
-	config InsuranceConfig
+	config   InsuranceConfig
+	provider InsuranceProvider
 }
 
 // NewInsuranceService creates a new insurance service
-func NewInsuranceService(config InsuranceConfig) *InsuranceService {
-	return &InsuranceService{config: config}
+func NewInsuranceService(config InsuranceConfig, provider InsuranceProvider) *InsuranceService {
+	return &InsuranceService{config: config, provider: provider}
+}
+
+// IssuePolicy issues the policy of a confirmed booking that includes insurance
+// Bookings without insurance, or with a policy already, are left as they are.
+func (is *InsuranceService) IssuePolicy(ctx context.Context, booking *domain.Booking) error {
+	if !booking.IncludeInsurance || booking.Policy != nil {
+		return nil
+	}
+
+	policy, err := is.provider.IssuePolicy(ctx, booking)
+	if err != nil {
+		return fmt.Errorf("failed to issue insurance policy: %w", err)
+	}
+
+	booking.Policy = policy
+	return nil
+}
+
+// CancelPolicy cancels the policy of a booking whose premium is refunded
+func (is *InsuranceService) CancelPolicy(ctx context.Context, booking *domain.Booking) error {
+	if booking.Policy == nil || booking.Policy.Status == domain.PolicyCancelled {
+		return nil
+	}
+
+	if err := is.provider.CancelPolicy(ctx, booking.Policy); err != nil {
+		return fmt.Errorf("failed to cancel insurance policy %s: %w", booking.Policy.Number, err)
+	}
+
+	booking.Policy.Status = domain.PolicyCancelled
+	return nil
+}
+
+// SubmitClaim reports an approved claim to the insurer
+func (is *InsuranceService) SubmitClaim(ctx context.Context, policy *domain.InsurancePolicy, claim *domain.InsuranceClaim) error {
+	reference, err := is.provider.SubmitClaim(ctx, policy, claim)
+	if err != nil {
+		return fmt.Errorf("insurer rejected claim under policy %s: %w", policy.Number, err)
+	}
+
+	claim.ProviderClaimID = reference
+	return nil
 }
 
 // CalculatePremium calculates insurance premium for a route
-func (is *InsuranceService) CalculatePremium(route *domain.Route) float64 {
+func (is *InsuranceService) CalculatePremium(route *domain.Route) domain.Money {
 	if route == nil || len(route.Segments) == 0 {
-		return 0
+		return domain.Money{}
 	}
 
 	totalPrice := route.TotalPrice
@@ -48,7 +110,7 @@ func (is *InsuranceService) CalculatePremium(route *domain.Route) float64 {
 	// Add surcharges based on risk factors
 	premiumRate += is.calculateRiskSurcharges(route)
 
-	premium := totalPrice * premiumRate
+	premium := totalPrice.Mul(premiumRate)
 	return premium
 }
 
@@ -80,15 +142,28 @@ func (is *InsuranceService) calculateRiskSurcharges(route *domain.Route) float64
 	return surcharge
 }
 
-// countTightConnections counts connections with less than 2 hours between segments
+// countTightConnections counts connections that leave little margin over the minimum connection time
 func (is *InsuranceService) countTightConnections(route *domain.Route) int {
+	rules := is.config.ConnectionRules
+	if rules == nil {
+		rules = &domain.MinConnectionTable{Default: time.Hour}
+	}
+
 	count := 0
 	for i := 0; i < len(route.Segments)-1; i++ {
-		currentSegment := route.Segments[i]
-		nextSegment := route.Segments[i+1]
+		connection := domain.Connection{From: &route.Segments[i], To: &route.Segments[i+1]}
+		if i < len(route.Connections) {
+			connection = route.Connections[i]
+			connection.From = &route.Segments[i]
+			connection.To = &route.Segments[i+1]
+		}
+		rules.Check(&connection)
 
-		connectionTime := nextSegment.DepartureTime.Sub(currentSegment.ArrivalTime)
-		if connectionTime < 2*time.Hour {
+		available := connection.Gap
+		if connection.TransferType != "" {
+			available -= connection.TransferDuration
+		}
+		if available < connection.MinConnectionTime+is.config.TightConnectionBuffer {
 			count++
 		}
 	}
@@ -110,10 +185,10 @@ func (is *InsuranceService) hasNightFlights(route *domain.Route) bool {
 	return false
 }
 
-// hasRiverTransport checks if route includes river transport
+// hasRiverTransport checks if route includes river transport (boats and ferries)
 func (is *InsuranceService) hasRiverTransport(route *domain.Route) bool {
 	for _, segment := range route.Segments {
-		if segment.TransportType == domain.TransportRiver {
+		if segment.TransportType == domain.TransportRiver || segment.TransportType == domain.TransportFerry {
 			return true
 		}
 	}
@@ -124,7 +199,7 @@ func (is *InsuranceService) hasRiverTransport(route *domain.Route) bool {
 func (is *InsuranceService) GetPremiumBreakdown(route *domain.Route) map[string]float64 {
 	breakdown := make(map[string]float64)
 
-	totalPrice := route.TotalPrice
+	totalPrice := route.TotalPrice.Major()
 	breakdown["base_price"] = totalPrice
 	breakdown["base_premium_rate"] = is.config.BasePremiumRate
 	breakdown["base_premium"] = totalPrice * is.config.BasePremiumRate
@@ -147,7 +222,63 @@ func (is *InsuranceService) GetPremiumBreakdown(route *domain.Route) map[string]
 		breakdown["multi_segment_surcharge"] = totalPrice * is.config.MultiSegmentSurcharge
 	}
 
-	breakdown["total_premium"] = is.CalculatePremium(route)
+	breakdown["total_premium"] = is.CalculatePremium(route).Major()
 
 	return breakdown
 }
+
+// --- Mock Insurance Provider for MVP/Hackathon ---
+
+// MockInsuranceProvider simulates an insurer that accepts every policy and claim
+type MockInsuranceProvider struct {
+	name string
+}
+
+// NewMockInsuranceProvider creates a mock insurance provider
+func NewMockInsuranceProvider() *MockInsuranceProvider {
+	return &MockInsuranceProvider{name: "mock_insurer"}
+}
+
+// IssuePolicy issues a policy covering the booking's journey
+// The coverage limit is what the tickets cost, commission included; the policy runs
+// from the first departure until a day after the last arrival.
+func (mip *MockInsuranceProvider) IssuePolicy(ctx context.Context, booking *domain.Booking) (*domain.InsurancePolicy, error) {
+	tickets := booking.ActiveSegments()
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("booking %s has no tickets to insure", booking.ID)
+	}
+
+	now := time.Now()
+	policy := &domain.InsurancePolicy{
+		Number:        fmt.Sprintf("LL-INS-%d-%s", now.Year(), strings.ToUpper(utils.GenerateID()[:8])),
+		Provider:      mip.name,
+		Premium:       booking.InsurancePremium,
+		CoverageLimit: booking.TotalPrice.Add(booking.TotalCommission),
+		PaidOut:       domain.Money{Currency: booking.TotalPrice.Currency},
+		Status:        domain.PolicyActive,
+		ValidFrom:     tickets[0].DepartureTime,
+		ValidUntil:    tickets[0].ArrivalTime,
+		IssuedAt:      now,
+	}
+	for _, ticket := range tickets {
+		if ticket.DepartureTime.Before(policy.ValidFrom) {
+			policy.ValidFrom = ticket.DepartureTime
+		}
+		if ticket.ArrivalTime.After(policy.ValidUntil) {
+			policy.ValidUntil = ticket.ArrivalTime
+		}
+	}
+	policy.ValidUntil = policy.ValidUntil.Add(24 * time.Hour)
+
+	return policy, nil
+}
+
+// SubmitClaim simulates the insurer registering a claim
+func (mip *MockInsuranceProvider) SubmitClaim(ctx context.Context, policy *domain.InsurancePolicy, claim *domain.InsuranceClaim) (string, error) {
+	return fmt.Sprintf("MOCK-CLAIM-%s", utils.GenerateID()[:8]), nil
+}
+
+// CancelPolicy simulates the insurer cancelling a policy
+func (mip *MockInsuranceProvider) CancelPolicy(ctx context.Context, policy *domain.InsurancePolicy) error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// PaymentExpiryConfig holds parameters for reconciling redirect payments
+type PaymentExpiryConfig struct {
+	SweepInterval time.Duration // How often bookings awaiting payment are reconciled
+	PollAfter     time.Duration // Payments pending this long are checked with the gateway, in case the webhook was lost
+	TTL           time.Duration // Payments still pending this long expire; keep it within the seat hold TTL
+}
+
+// DefaultPaymentExpiryConfig returns default payment expiry configuration
+func DefaultPaymentExpiryConfig() PaymentExpiryConfig {
+	return PaymentExpiryConfig{
+		SweepInterval: time.Minute,
+		PollAfter:     2 * time.Minute,
+		TTL:           15 * time.Minute, // Same as the seat hold TTL
+	}
+}
+
+// PaymentExpiryService reconciles bookings stuck in pending_payment
+// A booking waits there while the customer confirms the payment by redirect.
+// The payment webhook normally settles it; when the webhook is late or lost the
+// gateway is asked for the payment status, and a payment nobody completed within
+// the TTL is cancelled at the gateway, failing the booking and cancelling its
+// provider tickets.
+type PaymentExpiryService struct {
+	bookingRepo repository.BookingRepository
+	payments    *PaymentService
+	bookings    *BookingService
+	config      PaymentExpiryConfig
+}
+
+// NewPaymentExpiryService creates a new payment expiry service
+func NewPaymentExpiryService(bookingRepo repository.BookingRepository, payments *PaymentService, bookings *BookingService, config PaymentExpiryConfig) *PaymentExpiryService {
+	return &PaymentExpiryService{
+		bookingRepo: bookingRepo,
+		payments:    payments,
+		bookings:    bookings,
+		config:      config,
+	}
+}
+
+// SweepInterval returns how often pending payments should be reconciled
+func (s *PaymentExpiryService) SweepInterval() time.Duration {
+	return s.config.SweepInterval
+}
+
+// ReconcilePending settles bookings awaiting payment for longer than PollAfter
+// Returns how many bookings were confirmed or failed; a paid-first booking whose payment
+// is authorized gets its tickets issued. A booking whose payment status
+// cannot be fetched is left alone and retried next time, so a payment the customer
+// completed is never expired unchecked.
+func (s *PaymentExpiryService) ReconcilePending(ctx context.Context) (int, error) {
+	bookings, err := s.bookingRepo.FindByStatus(ctx, domain.BookingPendingPayment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load bookings awaiting payment: %w", err)
+	}
+
+	now := time.Now()
+	settled := 0
+	for i := range bookings {
+		booking := &bookings[i]
+		if booking.Payment == nil {
+			continue
+		}
+
+		age := now.Sub(booking.Payment.CreatedAt)
+		if age < s.config.PollAfter {
+			continue
+		}
+
+		status, err := s.payments.CheckPaymentStatus(ctx, booking.Payment)
+		if err != nil {
+			// In production, this should be logged and monitored
+			fmt.Printf("Warning: failed to check payment of booking %s: %v\n", booking.ID, err)
+			continue
+		}
+
+		switch {
+		case status == domain.PaymentAuthorized:
+			err = s.bookings.AuthorizePayment(ctx, booking, domain.ActorSystem, "")
+		case status == domain.PaymentCompleted:
+			err = s.bookings.ConfirmPayment(ctx, booking, domain.ActorSystem, "")
+		case status == domain.PaymentFailed:
+			err = s.bookings.FailPayment(ctx, booking, domain.ActorSystem, "Payment canceled by user or provider")
+		case age >= s.config.TTL:
+			// Cancel it at the gateway so the customer can no longer pay; a payment completed
+			// anyway is given back when its notification arrives
+			if err := s.payments.CancelPending(ctx, booking.Payment); err != nil {
+				fmt.Printf("Warning: failed to cancel expired payment of booking %s: %v\n", booking.ID, err)
+			}
+			err = s.bookings.FailPayment(ctx, booking, domain.ActorSystem, fmt.Sprintf("Payment not completed within %s", s.config.TTL))
+		default:
+			continue
+		}
+
+		if err != nil {
+			fmt.Printf("Warning: failed to reconcile payment of booking %s: %v\n", booking.ID, err)
+			continue
+		}
+		settled++
+	}
+
+	return settled, nil
+}
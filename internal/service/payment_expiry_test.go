@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository/memory"
+)
+
+// fakeExpiryGateway reports every payment as still pending and records cancellations
+type fakeExpiryGateway struct {
+	PaymentGateway
+	cancelled []string
+}
+
+func (g *fakeExpiryGateway) GetPaymentStatus(ctx context.Context, paymentID string) (domain.PaymentStatus, error) {
+	return domain.PaymentPending, nil
+}
+
+func (g *fakeExpiryGateway) CancelPayment(ctx context.Context, paymentID string) error {
+	g.cancelled = append(g.cancelled, paymentID)
+	return nil
+}
+
+func TestReconcilePendingExpiresUnpaidBookings(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewBookingRepository()
+	gateway := &fakeExpiryGateway{}
+	provider := &fakeProviderBooking{failing: make(map[string]bool)}
+	seats := &fakeSeatRepo{}
+
+	payments := NewPaymentService(NewGatewayRegistry(gateway))
+	bookings := NewBookingService(nil, nil, repo, nil, nil, nil, payments, provider,
+		NewSeatInventoryService(seats, DefaultSeatHoldConfig()), nil, nil, DefaultBookingConfig())
+	expiry := NewPaymentExpiryService(repo, payments, bookings, DefaultPaymentExpiryConfig())
+
+	now := time.Now()
+	pending := func(id string, age time.Duration) {
+		booking := &domain.Booking{
+			ID:       id,
+			Status:   domain.BookingPendingPayment,
+			Segments: []domain.BookedSegment{{ID: "s-" + id, ProviderBookingRef: "ref-" + id, BookingStatus: domain.BookingConfirmed}},
+			Payment: &domain.Payment{
+				ID:                "pay-" + id,
+				OrderID:           id,
+				ProviderPaymentID: "gw-" + id,
+				Amount:            domain.Rubles(5000),
+				Method:            domain.PaymentYooKassa,
+				Status:            domain.PaymentPending,
+				CreatedAt:         now.Add(-age),
+			},
+		}
+		if err := repo.Save(ctx, booking); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pending("expired", 20*time.Minute)
+	pending("waiting", 5*time.Minute)
+
+	settled, err := expiry.ReconcilePending(ctx)
+	if err != nil || settled != 1 {
+		t.Fatalf("expected one booking to expire, got %d (%v)", settled, err)
+	}
+
+	expired, _ := repo.FindByID(ctx, "expired")
+	if expired.Status != domain.BookingFailed || expired.Payment.Status != domain.PaymentFailed {
+		t.Fatalf("expected the expired booking to fail, got %s with payment %s", expired.Status, expired.Payment.Status)
+	}
+	if len(gateway.cancelled) != 1 || gateway.cancelled[0] != "gw-expired" {
+		t.Fatalf("expected the expired payment to be cancelled at the gateway, got %v", gateway.cancelled)
+	}
+	if len(provider.cancelled) != 1 || provider.cancelled[0] != "ref-expired" || len(seats.released) != 1 {
+		t.Fatalf("expected the tickets cancelled and the seats released, got %v and %v", provider.cancelled, seats.released)
+	}
+
+	if waiting, _ := repo.FindByID(ctx, "waiting"); waiting.Status != domain.BookingPendingPayment {
+		t.Fatalf("expected the booking still within its TTL to wait, got %s", waiting.Status)
+	}
+}
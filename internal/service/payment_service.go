@@ -12,37 +12,98 @@ import (
 // PaymentGateway defines interface for payment processing
 type PaymentGateway interface {
 	ProcessPayment(ctx context.Context, payment *domain.Payment) error
-	RefundPayment(ctx context.Context, paymentID string, amount float64) error
+	RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error // receipt is nil for payments without one
 	GetPaymentStatus(ctx context.Context, paymentID string) (domain.PaymentStatus, error)
+	CapturePayment(ctx context.Context, paymentID string, amount domain.Money) error
+	CancelPayment(ctx context.Context, paymentID string) error
+	FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error)
+	FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error)
+}
+
+// GatewayPayment is a payment as the gateway reports it
+// Notifications are checked against it rather than trusted.
+type GatewayPayment struct {
+	ID      string
+	OrderID string // From the metadata sent when the payment was created
+	Status  domain.PaymentStatus
+	Amount  domain.Money
+}
+
+// GatewayRefund is a refund as the gateway reports it
+type GatewayRefund struct {
+	ID        string
+	PaymentID string // Gateway ID of the refunded payment
+	Succeeded bool
+	Amount    domain.Money
+}
+
+// NotificationVerifier is implemented by gateways that sign their notifications
+type NotificationVerifier interface {
+	// VerifyNotification checks the signature of a notification's body (or form fields)
+	VerifyNotification(body []byte, signature string) error
+}
+
+// RedirectGateway is implemented by gateways whose every payment the customer confirms
+// by redirect; they cannot charge a customer who is not at the checkout
+type RedirectGateway interface {
+	RedirectsCustomer()
+}
+
+// GatewayRegistry picks the payment gateway of a payment method
+// Methods without a gateway of their own go through the default gateway.
+type GatewayRegistry struct {
+	gateways map[domain.PaymentMethod]PaymentGateway
+	fallback PaymentGateway
+}
+
+// NewGatewayRegistry creates a registry routing every method to the default gateway
+func NewGatewayRegistry(fallback PaymentGateway) *GatewayRegistry {
+	return &GatewayRegistry{
+		gateways: make(map[domain.PaymentMethod]PaymentGateway),
+		fallback: fallback,
+	}
+}
+
+// Register routes a payment method to a gateway
+func (r *GatewayRegistry) Register(method domain.PaymentMethod, gateway PaymentGateway) {
+	r.gateways[method] = gateway
+}
+
+// Gateway returns the gateway of a payment method
+func (r *GatewayRegistry) Gateway(method domain.PaymentMethod) PaymentGateway {
+	if gateway, ok := r.gateways[method]; ok {
+		return gateway
+	}
+	return r.fallback
 }
 
 // PaymentService handles payment processing
 type PaymentService struct {
-	gateway PaymentGateway
+	gateways *GatewayRegistry
 }
 
 // NewPaymentService creates a new payment service
-func NewPaymentService(gateway PaymentGateway) *PaymentService {
-	return &PaymentService{gateway: gateway}
+func NewPaymentService(gateways *GatewayRegistry) *PaymentService {
+	return &PaymentService{gateways: gateways}
 }
 
 // CreatePayment creates a new payment for booking
-func (ps *PaymentService) CreatePayment(orderID string, amount float64, method domain.PaymentMethod) *domain.Payment {
+func (ps *PaymentService) CreatePayment(orderID string, amount domain.Money, method domain.PaymentMethod) *domain.Payment {
 	return &domain.Payment{
-		ID:        utils.GenerateID(),
-		OrderID:   orderID,
-		Amount:    amount,
-		Currency:  "RUB",
-		Method:    method,
-		Status:    domain.PaymentPending,
-		CreatedAt: time.Now(),
+		ID:             utils.GenerateID(),
+		OrderID:        orderID,
+		Amount:         amount,
+		RefundedAmount: domain.Money{Currency: amount.Currency},
+		Method:         method,
+		Status:         domain.PaymentPending,
+		CreatedAt:      time.Now(),
 	}
 }
 
 // ProcessPayment processes a payment
 func (ps *PaymentService) ProcessPayment(ctx context.Context, payment *domain.Payment) error {
 	// Process payment through gateway
-	if err := ps.gateway.ProcessPayment(ctx, payment); err != nil {
+	if err := ps.gateways.Gateway(payment.Method).ProcessPayment(ctx, payment); err != nil {
 		payment.Status = domain.PaymentFailed
 		payment.FailureReason = err.Error()
 		return fmt.Errorf("payment processing failed: %w", err)
@@ -54,23 +115,198 @@ func (ps *PaymentService) ProcessPayment(ctx context.Context, payment *domain.Pa
 	return nil
 }
 
-// RefundPayment processes a refund
+// Authorize creates a two-stage payment: the gateway holds the funds until Capture
+// The payment stays pending while the customer confirms it by redirect and becomes
+// authorized once the funds are held.
+func (ps *PaymentService) Authorize(ctx context.Context, payment *domain.Payment) error {
+	payment.TwoStage = true
+	if err := ps.gateways.Gateway(payment.Method).ProcessPayment(ctx, payment); err != nil {
+		payment.Status = domain.PaymentFailed
+		payment.FailureReason = err.Error()
+		return fmt.Errorf("payment authorization failed: %w", err)
+	}
+
+	payment.Status = domain.PaymentPending
+	if payment.ConfirmationURL == "" {
+		payment.Status = domain.PaymentAuthorized
+	}
+	return nil
+}
+
+// Capture takes the funds held by an authorized payment
+func (ps *PaymentService) Capture(ctx context.Context, payment *domain.Payment) error {
+	if payment.Status != domain.PaymentAuthorized {
+		return fmt.Errorf("cannot capture payment in status: %s", payment.Status)
+	}
+
+	if err := ps.gateways.Gateway(payment.Method).CapturePayment(ctx, gatewayPaymentID(payment), payment.Amount); err != nil {
+		return fmt.Errorf("capture failed: %w", err)
+	}
+
+	payment.Status = domain.PaymentCompleted
+	now := time.Now()
+	payment.CompletedAt = &now
+	return nil
+}
+
+// CancelAuthorization releases the funds held by an authorized payment
+func (ps *PaymentService) CancelAuthorization(ctx context.Context, payment *domain.Payment) error {
+	if payment.Status != domain.PaymentAuthorized {
+		return fmt.Errorf("cannot cancel payment in status: %s", payment.Status)
+	}
+
+	if err := ps.gateways.Gateway(payment.Method).CancelPayment(ctx, gatewayPaymentID(payment)); err != nil {
+		return fmt.Errorf("cancellation failed: %w", err)
+	}
+
+	payment.Status = domain.PaymentFailed
+	return nil
+}
+
+// CanCharge reports whether payments of the method can be charged without the customer
+func (ps *PaymentService) CanCharge(method domain.PaymentMethod) bool {
+	_, redirects := ps.gateways.Gateway(method).(RedirectGateway)
+	return !redirects
+}
+
+// CancelPending cancels a payment the customer has not completed, so it can no longer be paid
+func (ps *PaymentService) CancelPending(ctx context.Context, payment *domain.Payment) error {
+	if payment.Status != domain.PaymentPending {
+		return fmt.Errorf("cannot cancel payment in status: %s", payment.Status)
+	}
+
+	if err := ps.gateways.Gateway(payment.Method).CancelPayment(ctx, gatewayPaymentID(payment)); err != nil {
+		return fmt.Errorf("cancellation failed: %w", err)
+	}
+
+	payment.Status = domain.PaymentFailed
+	return nil
+}
+
+// Charge charges an amount to a paid booking (e.g. the fare difference of a booking change)
+// The charge is a payment of its own, with its own receipt, kept in booking.Charges so
+// that refunds of it go against its own gateway payment. Gateways the customer has to
+// confirm by redirect (every gateway we have) fail with domain.ErrChargeNotSupported
+// before anything is created there; callers check CanCharge up front.
+func (ps *PaymentService) Charge(ctx context.Context, booking *domain.Booking, amount domain.Money, receipt *domain.Receipt) (*domain.Payment, error) {
+	payment := booking.Payment
+	if payment == nil || payment.Status != domain.PaymentCompleted {
+		return nil, fmt.Errorf("booking %s has no completed payment to charge", booking.ID)
+	}
+	if !ps.CanCharge(payment.Method) {
+		return nil, fmt.Errorf("%w: %s payments need the customer to confirm them", domain.ErrChargeNotSupported, payment.Method)
+	}
+
+	charge := ps.CreatePayment(booking.ID, amount, payment.Method)
+	charge.Receipt = receipt
+	if err := ps.ProcessPayment(ctx, charge); err != nil {
+		return nil, fmt.Errorf("charge failed: %w", err)
+	}
+
+	booking.Charges = append(booking.Charges, *charge)
+	return &booking.Charges[len(booking.Charges)-1], nil
+}
+
+// RefundBooking refunds an amount over the booking's payments, each against its own
+// gateway payment and up to what it has left
+// receipt lists what is refunded (see ReceiptService.RefundReceipt); each payment refunds
+// the items it was taken for, with a receipt of them (see domain.Booking.SplitRefund).
+func (ps *PaymentService) RefundBooking(ctx context.Context, booking *domain.Booking, amount domain.Money, receipt *domain.Receipt) error {
+	if amount.Cmp(booking.RefundableAmount()) > 0 {
+		return fmt.Errorf("refund of %s exceeds refundable %s", amount, booking.RefundableAmount())
+	}
+
+	for _, part := range booking.SplitRefund(amount, receipt) {
+		if err := ps.RefundPartial(ctx, part.Payment, part.Amount, part.Receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefundPayment refunds whatever has not been refunded yet
+// The whole payment is given back, with the receipt it was paid with.
 func (ps *PaymentService) RefundPayment(ctx context.Context, payment *domain.Payment) error {
+	return ps.RefundPartial(ctx, payment, payment.RefundableAmount(), payment.Receipt)
+}
+
+// RefundPartial refunds part of a completed payment
+// receipt lists the items refunded; payments made without a receipt are refunded without one.
+// The payment becomes refunded once nothing is left to refund.
+func (ps *PaymentService) RefundPartial(ctx context.Context, payment *domain.Payment, amount domain.Money, receipt *domain.Receipt) error {
 	if payment.Status != domain.PaymentCompleted {
 		return fmt.Errorf("cannot refund payment in status: %s", payment.Status)
 	}
+	if amount.Cmp(payment.RefundableAmount()) > 0 {
+		return fmt.Errorf("refund of %s exceeds refundable %s", amount, payment.RefundableAmount())
+	}
 
-	if err := ps.gateway.RefundPayment(ctx, payment.ID, payment.Amount); err != nil {
-		return fmt.Errorf("refund failed: %w", err)
+	if amount.IsPositive() {
+		if payment.Receipt == nil {
+			receipt = nil
+		}
+		if err := ps.gateways.Gateway(payment.Method).RefundPayment(ctx, gatewayPaymentID(payment), amount, receipt); err != nil {
+			return fmt.Errorf("refund failed: %w", err)
+		}
+		payment.RefundedAmount = payment.RefundedAmount.Add(amount)
 	}
 
-	payment.Status = domain.PaymentRefunded
+	if !payment.RefundableAmount().IsPositive() {
+		payment.Status = domain.PaymentRefunded
+	}
 	return nil
 }
 
 // CheckPaymentStatus checks payment status from gateway
+// Gateways know the payment by their own ID once it has been created there.
 func (ps *PaymentService) CheckPaymentStatus(ctx context.Context, payment *domain.Payment) (domain.PaymentStatus, error) {
-	return ps.gateway.GetPaymentStatus(ctx, payment.ID)
+	return ps.gateways.Gateway(payment.Method).GetPaymentStatus(ctx, gatewayPaymentID(payment))
+}
+
+// FindPayment fetches a payment from the gateway of a payment method by the gateway's ID
+func (ps *PaymentService) FindPayment(ctx context.Context, method domain.PaymentMethod, providerPaymentID string) (*GatewayPayment, error) {
+	return ps.gateways.Gateway(method).FindPayment(ctx, providerPaymentID)
+}
+
+// FindRefund fetches a refund from the gateway of a payment method by the gateway's ID
+func (ps *PaymentService) FindRefund(ctx context.Context, method domain.PaymentMethod, refundID string) (*GatewayRefund, error) {
+	return ps.gateways.Gateway(method).FindRefund(ctx, refundID)
+}
+
+// VerifyNotification checks the signature of a notification sent by the gateway of a payment method
+func (ps *PaymentService) VerifyNotification(method domain.PaymentMethod, body []byte, signature string) error {
+	verifier, ok := ps.gateways.Gateway(method).(NotificationVerifier)
+	if !ok {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("The %s gateway does not sign notifications", method))
+	}
+	if err := verifier.VerifyNotification(body, signature); err != nil {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", err.Error())
+	}
+	return nil
+}
+
+// VerifyPayment checks that the gateway's payment is the order's payment:
+// same order, same gateway ID once known, same amount and currency
+func (ps *PaymentService) VerifyPayment(payment *domain.Payment, remote *GatewayPayment) error {
+	if remote.OrderID != payment.OrderID {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Payment %s belongs to order %q, not %s", remote.ID, remote.OrderID, payment.OrderID))
+	}
+	if payment.ProviderPaymentID != "" && remote.ID != payment.ProviderPaymentID {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Payment %s is not the payment of order %s", remote.ID, payment.OrderID))
+	}
+	if remote.Amount != payment.Amount {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Payment %s is %s, order %s expects %s",
+			remote.ID, remote.Amount, payment.OrderID, payment.Amount))
+	}
+	return nil
+}
+
+// gatewayPaymentID returns the ID the gateway knows the payment by
+func gatewayPaymentID(payment *domain.Payment) string {
+	if payment.ProviderPaymentID != "" {
+		return payment.ProviderPaymentID
+	}
+	return payment.ID
 }
 
 // --- Mock Payment Gateway for MVP/Hackathon ---
@@ -107,7 +343,7 @@ func (mpg *MockPaymentGateway) ProcessPayment(ctx context.Context, payment *doma
 }
 
 // RefundPayment simulates refund processing
-func (mpg *MockPaymentGateway) RefundPayment(ctx context.Context, paymentID string, amount float64) error {
+func (mpg *MockPaymentGateway) RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error {
 	// Simulate processing delay
 	time.Sleep(100 * time.Millisecond)
 
@@ -121,18 +357,30 @@ func (mpg *MockPaymentGateway) GetPaymentStatus(ctx context.Context, paymentID s
 	return domain.PaymentCompleted, nil
 }
 
-// --- Future: Real Payment Gateway Implementations ---
+// CapturePayment simulates capturing an authorized payment
+func (mpg *MockPaymentGateway) CapturePayment(ctx context.Context, paymentID string, amount domain.Money) error {
+	// Simulate processing delay
+	time.Sleep(100 * time.Millisecond)
 
-// YooKassaGateway would implement real YooKassa integration
-// type YooKassaGateway struct {
-// 	apiKey    string
-// 	shopID    string
-// 	apiClient *http.Client
-// }
+	// Always succeed in mock mode
+	return nil
+}
+
+// CancelPayment simulates cancelling an authorized payment
+func (mpg *MockPaymentGateway) CancelPayment(ctx context.Context, paymentID string) error {
+	// Simulate processing delay
+	time.Sleep(100 * time.Millisecond)
+
+	// Always succeed in mock mode
+	return nil
+}
 
-// CloudPaymentsGateway would implement real CloudPayments integration
-// type CloudPaymentsGateway struct {
-// 	publicID  string
-// 	apiSecret string
-// 	apiClient *http.Client
-// }
+// FindPayment is not supported: the mock gateway sends no notifications to verify
+func (mpg *MockPaymentGateway) FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error) {
+	return nil, fmt.Errorf("mock gateway does not keep payments")
+}
+
+// FindRefund is not supported: the mock gateway sends no notifications to verify
+func (mpg *MockPaymentGateway) FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error) {
+	return nil, fmt.Errorf("mock gateway does not keep refunds")
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// maxReceiptDescription is the longest item description a receipt accepts
+const maxReceiptDescription = 128
+
+// ReceiptConfig holds the VAT rates of receipt items
+type ReceiptConfig struct {
+	TicketVAT        map[domain.TransportType]domain.VATCode // Ticket VAT per transport type
+	DefaultTicketVAT domain.VATCode                          // Tickets of other transport types
+	CommissionVAT    domain.VATCode
+	InsuranceVAT     domain.VATCode
+}
+
+// DefaultReceiptConfig returns default receipt configuration
+func DefaultReceiptConfig() ReceiptConfig {
+	return ReceiptConfig{
+		TicketVAT: map[domain.TransportType]domain.VATCode{
+			domain.TransportAir:  domain.VAT0, // Domestic air and long-distance rail travel is zero-rated
+			domain.TransportRail: domain.VAT0,
+		},
+		DefaultTicketVAT: domain.VAT20,
+		CommissionVAT:    domain.VAT20,
+		InsuranceVAT:     domain.VATNone, // Insurance is exempt
+	}
+}
+
+// ReceiptService builds fiscal receipts (54-FZ) for booking payments
+// Every ticket is an item of its own; the commission and the insurance premium are
+// itemized separately so each carries its VAT rate.
+type ReceiptService struct {
+	config ReceiptConfig
+}
+
+// NewReceiptService creates a new receipt service
+func NewReceiptService(config ReceiptConfig) *ReceiptService {
+	return &ReceiptService{config: config}
+}
+
+// BookingReceipt builds the receipt of a booking's payment
+// The items add up to the booking's grand total.
+func (rs *ReceiptService) BookingReceipt(booking *domain.Booking) *domain.Receipt {
+	receipt := &domain.Receipt{Customer: receiptCustomer(booking)}
+
+	commission := domain.Money{Currency: booking.GrandTotal.Currency}
+	for i := range booking.Segments {
+		ticket := &booking.Segments[i]
+		if ticket.BookingStatus == domain.BookingCancelled {
+			continue
+		}
+
+		receipt.Items = append(receipt.Items, domain.ReceiptItem{
+			Description: receiptDescription(fmt.Sprintf("Билет %s — %s, %s",
+				stopName(ticket.From), stopName(ticket.To), ticket.DepartureTime.Format("02.01.2006 15:04"))),
+			Amount:          ticket.Price,
+			VAT:             rs.ticketVAT(ticket.TransportType),
+			Subject:         domain.ReceiptSubjectService,
+			BookedSegmentID: ticket.ID,
+		})
+		commission = commission.Add(ticket.Commission)
+	}
+
+	if commission.IsPositive() {
+		receipt.Items = append(receipt.Items, domain.ReceiptItem{
+			Description: "Сервисный сбор LenaLink",
+			Amount:      commission,
+			VAT:         rs.config.CommissionVAT,
+			Subject:     domain.ReceiptSubjectAgentCommission,
+		})
+	}
+
+	if booking.IncludeInsurance && booking.InsurancePremium.IsPositive() {
+		receipt.Items = append(receipt.Items, domain.ReceiptItem{
+			Description: "Страховой полис",
+			Amount:      booking.InsurancePremium,
+			VAT:         rs.config.InsuranceVAT,
+			Subject:     domain.ReceiptSubjectService,
+		})
+	}
+
+	return receipt
+}
+
+// ChangeReceipt builds the receipt of the surcharge for a booking change
+func (rs *ReceiptService) ChangeReceipt(booking *domain.Booking, replacement *domain.BookedSegment, amount domain.Money) *domain.Receipt {
+	return &domain.Receipt{
+		Customer: receiptCustomer(booking),
+		Items: []domain.ReceiptItem{{
+			Description: receiptDescription(fmt.Sprintf("Доплата за обмен билета %s — %s",
+				stopName(replacement.From), stopName(replacement.To))),
+			Amount:          amount,
+			VAT:             rs.ticketVAT(replacement.TransportType),
+			Subject:         domain.ReceiptSubjectService,
+			BookedSegmentID: replacement.ID,
+		}},
+	}
+}
+
+// ChangeRefundReceipt builds the receipt of the fare difference refunded for a booking change
+// The difference is refunded from what was paid for the old ticket.
+func (rs *ReceiptService) ChangeRefundReceipt(booking *domain.Booking, old, replacement *domain.BookedSegment, amount domain.Money) *domain.Receipt {
+	return &domain.Receipt{
+		Customer: receiptCustomer(booking),
+		Items: []domain.ReceiptItem{{
+			Description: receiptDescription(fmt.Sprintf("Возврат разницы за обмен билета %s — %s",
+				stopName(replacement.From), stopName(replacement.To))),
+			Amount:          amount,
+			VAT:             rs.ticketVAT(replacement.TransportType),
+			Subject:         domain.ReceiptSubjectService,
+			BookedSegmentID: old.ID,
+		}},
+	}
+}
+
+// RefundReceipt builds the receipt of refunding tickets, and the insurance premium when refunded
+// Each ticket is refunded as an item of its own (its fare less the penalty), the commission
+// refunded with them as one item, and the premium as the last, so each keeps its VAT rate.
+func (rs *ReceiptService) RefundReceipt(booking *domain.Booking, refunds []domain.SegmentRefund, insurance bool) *domain.Receipt {
+	receipt := &domain.Receipt{Customer: receiptCustomer(booking)}
+
+	commission := domain.Money{Currency: domain.SettlementCurrency}
+	for _, refund := range refunds {
+		ticket, ok := booking.FindSegment(refund.BookedSegmentID)
+		if !ok {
+			continue
+		}
+
+		if fare := refund.Amount.Sub(refund.CommissionRefunded); fare.IsPositive() {
+			receipt.Items = append(receipt.Items, domain.ReceiptItem{
+				Description: receiptDescription(fmt.Sprintf("Билет %s — %s, %s",
+					stopName(ticket.From), stopName(ticket.To), ticket.DepartureTime.Format("02.01.2006 15:04"))),
+				Amount:          fare,
+				VAT:             rs.ticketVAT(ticket.TransportType),
+				Subject:         domain.ReceiptSubjectService,
+				BookedSegmentID: ticket.ID,
+			})
+		}
+		commission = commission.Add(refund.CommissionRefunded)
+	}
+
+	if commission.IsPositive() {
+		receipt.Items = append(receipt.Items, domain.ReceiptItem{
+			Description: "Сервисный сбор LenaLink",
+			Amount:      commission,
+			VAT:         rs.config.CommissionVAT,
+			Subject:     domain.ReceiptSubjectAgentCommission,
+		})
+	}
+
+	if insurance && booking.InsurancePremium.IsPositive() {
+		receipt.Items = append(receipt.Items, domain.ReceiptItem{
+			Description: "Страховой полис",
+			Amount:      booking.InsurancePremium,
+			VAT:         rs.config.InsuranceVAT,
+			Subject:     domain.ReceiptSubjectService,
+		})
+	}
+
+	return receipt
+}
+
+// ticketVAT returns the VAT rate of a ticket
+func (rs *ReceiptService) ticketVAT(transportType domain.TransportType) domain.VATCode {
+	if vat, ok := rs.config.TicketVAT[transportType]; ok {
+		return vat
+	}
+	return rs.config.DefaultTicketVAT
+}
+
+// receiptCustomer returns the lead passenger's contacts, who pays for the booking
+func receiptCustomer(booking *domain.Booking) domain.ReceiptCustomer {
+	return domain.ReceiptCustomer{
+		Email: booking.Passenger.Email,
+		Phone: receiptPhone(booking.Passenger.Phone),
+	}
+}
+
+// receiptPhone normalizes a phone number to digits, e.g. "8 (900) 123-45-67" to "79001234567"
+func receiptPhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+
+	if len(digits) == 11 && digits[0] == '8' {
+		digits = "7" + digits[1:]
+	}
+	return digits
+}
+
+// receiptDescription shortens a description to what a receipt item accepts
+func receiptDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) > maxReceiptDescription {
+		return string(runes[:maxReceiptDescription])
+	}
+	return description
+}
+
+// stopName returns the city of a stop, or its name when the city is unknown
+func stopName(stop domain.Stop) string {
+	if stop.City != "" {
+		return stop.City
+	}
+	return stop.Name
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/routing"
+)
+
+const (
+	// DefaultAlternatives is the number of alternatives returned when the client doesn't ask
+	DefaultAlternatives = 5
+	// MaxAlternatives caps the number of alternatives in a single response
+	MaxAlternatives = 20
+)
+
+// selectAlternatives removes duplicate and dominated routes
+// and returns the rest ranked from best to worst
+func selectAlternatives(routes []domain.Route, weights *domain.CostWeights) []domain.Route {
+	routes = paretoFront(dedupRoutes(routes))
+	if weights != nil {
+		return rankRoutesByWeights(routes, weights)
+	}
+	return rankRoutes(routes)
+}
+
+// dedupRoutes collapses routes that travel the same legs at the same times
+// (e.g. the same train offered in several classes), keeping the cheapest
+func dedupRoutes(routes []domain.Route) []domain.Route {
+	index := make(map[string]int, len(routes))
+	unique := make([]domain.Route, 0, len(routes))
+
+	for _, route := range routes {
+		key := routeKey(&route)
+		if i, exists := index[key]; exists {
+			if route.TotalPrice.Cmp(unique[i].TotalPrice) < 0 {
+				unique[i] = route
+			}
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, route)
+	}
+
+	return unique
+}
+
+// routeKey identifies a route by its legs
+func routeKey(route *domain.Route) string {
+	legs := make([]string, len(route.Segments))
+	for i, segment := range route.Segments {
+		legs[i] = fmt.Sprintf("%s:%s:%s:%d",
+			segment.TransportType,
+			segment.StartStop.ID,
+			segment.EndStop.ID,
+			segment.DepartureTime.Unix(),
+		)
+	}
+	return strings.Join(legs, "|")
+}
+
+// paretoFront keeps routes that are not dominated on price, duration,
+// transfers and reliability by any other route
+func paretoFront(routes []domain.Route) []domain.Route {
+	front := make([]domain.Route, 0, len(routes))
+
+	for i := range routes {
+		dominated := false
+		for j := range routes {
+			if i != j && routeDominates(&routes[j], &routes[i]) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, routes[i])
+		}
+	}
+
+	return front
+}
+
+// routeDominates reports whether a is at least as good as b on every criterion
+// and strictly better on at least one
+func routeDominates(a, b *domain.Route) bool {
+	if a.TotalPrice.Cmp(b.TotalPrice) > 0 ||
+		a.TotalDuration > b.TotalDuration ||
+		len(a.Segments) > len(b.Segments) ||
+		a.ReliabilityScore < b.ReliabilityScore {
+		return false
+	}
+
+	return a.TotalPrice.Cmp(b.TotalPrice) < 0 ||
+		a.TotalDuration < b.TotalDuration ||
+		len(a.Segments) < len(b.Segments) ||
+		a.ReliabilityScore > b.ReliabilityScore
+}
+
+// rankRoutes orders routes by a balanced score: price and duration relative to the
+// best available, plus penalties for transfers and unreliability (lower is better)
+func rankRoutes(routes []domain.Route) []domain.Route {
+	if len(routes) == 0 {
+		return routes
+	}
+
+	minPrice := routes[0].TotalPrice
+	minDuration := routes[0].TotalDuration
+	for _, route := range routes[1:] {
+		if route.TotalPrice.Cmp(minPrice) < 0 {
+			minPrice = route.TotalPrice
+		}
+		if route.TotalDuration < minDuration {
+			minDuration = route.TotalDuration
+		}
+	}
+
+	score := func(route *domain.Route) float64 {
+		value := float64(len(route.Segments)-1)*0.1 + (100-route.ReliabilityScore)/100
+		if minPrice.IsPositive() {
+			value += float64(route.TotalPrice.Amount) / float64(minPrice.Amount)
+		}
+		if minDuration > 0 {
+			value += float64(route.TotalDuration) / float64(minDuration)
+		}
+		return value
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return score(&routes[i]) < score(&routes[j])
+	})
+
+	return routes
+}
+
+// rankRoutesByWeights orders routes by the client's cost blend (lower is better)
+func rankRoutesByWeights(routes []domain.Route, weights *domain.CostWeights) []domain.Route {
+	cost := routing.WeightedCost{
+		Price:     weights.Price,
+		Time:      weights.Time,
+		Transfers: weights.Transfers,
+	}
+
+	score := func(route *domain.Route) float64 {
+		return cost.Total(route.TotalPrice.Major(), route.TotalDuration, len(route.Segments)-1)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return score(&routes[i]) < score(&routes[j])
+	})
+
+	return routes
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/graph"
+	"github.com/lenalink/backend/internal/routing"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// composeRoutes builds multi-modal routes from synced segments
+// using the transport graph and the pathfinder
+func (s *RouteService) composeRoutes(ctx context.Context, criteria *domain.RouteSearchCriteria) ([]domain.Route, error) {
+	if s.segmentRepo == nil {
+		return nil, nil
+	}
+
+	segments, err := s.loadSegments(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, &routing.PathError{Err: routing.ErrUnknownNode, From: criteria.FromCity, To: criteria.ToCity}
+	}
+
+	segmentsByID := make(map[string]*domain.Segment, len(segments))
+	for i := range segments {
+		segmentsByID[segments[i].ID] = &segments[i]
+	}
+
+	// Out-of-season segments (river outside navigation, closed winter roads) are dropped
+	builder := graph.NewBuilder().ForDate(criteria.DepartureDate)
+	g := builder.BuildFromSegments(segments)
+
+	// Let journeys switch between nearby stops of different providers
+	builder.AddTransferEdges(s.config.Transfers)
+
+	rules := s.connectionRules()
+	pathfinder := routing.NewParetoPathfinder(
+		g,
+		connectionRule{table: rules},
+		time.Duration(criteria.MaxTransferTime)*time.Minute,
+		criteria.MaxConnections+1,
+	)
+
+	paths, err := pathfinder.FindParetoPaths(
+		nodeIDs(g.NodesInCity(criteria.FromCityKey())),
+		nodeIDs(g.NodesInCity(criteria.ToCityKey())),
+		criteria.DepartureDate,
+	)
+	if err != nil {
+		// Report cities rather than the stop IDs searched between
+		var pathErr *routing.PathError
+		if errors.As(err, &pathErr) {
+			pathErr.From = criteria.FromCity
+			pathErr.To = criteria.ToCity
+		}
+		return nil, err
+	}
+
+	routes := make([]domain.Route, 0, len(paths))
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		if len(path.Edges) == 0 {
+			continue
+		}
+
+		signature := pathSignature(path)
+		if seen[signature] {
+			continue
+		}
+		seen[signature] = true
+
+		route, ok := composeRoute(path, segmentsByID, criteria, rules)
+		if !ok {
+			continue
+		}
+
+		s.cacheRoute(&route)
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// loadSegments fetches segments departing within the live search window
+func (s *RouteService) loadSegments(ctx context.Context, criteria *domain.RouteSearchCriteria) ([]domain.Segment, error) {
+	windowStart := criteria.DepartureDate
+	windowEnd := windowStart.Add(s.config.SearchWindow)
+
+	// Multi-hop routes need every segment in the window, not only direct ones
+	window, err := s.segmentRepo.FindByDepartureWindow(ctx, windowStart, windowEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error loading segments: %w", err)
+	}
+
+	segments := make([]domain.Segment, 0, len(window))
+	for _, segment := range window {
+		if segment.IsCancelled() {
+			continue
+		}
+		// Routes are priced in the settlement currency
+		if err := convertSegmentPrice(s.config.ExchangeRates, &segment); err != nil {
+			fmt.Printf("Warning: skipping segment with unconvertible price: %v\n", err)
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// convertSegmentPrice converts a segment's provider price into the settlement currency
+func convertSegmentPrice(rates *domain.ExchangeRates, segment *domain.Segment) error {
+	price, err := rates.Convert(segment.Price)
+	if err != nil {
+		return fmt.Errorf("segment %s: %w", segment.ID, err)
+	}
+	segment.Price = price
+	return nil
+}
+
+// connectionRules returns the configured minimum connection time table
+// or a flat table using MinTransferTime
+func (s *RouteService) connectionRules() *domain.MinConnectionTable {
+	if s.config.ConnectionRules != nil {
+		return s.config.ConnectionRules
+	}
+	return &domain.MinConnectionTable{Default: s.config.MinTransferTime}
+}
+
+// composeRoute converts a path into a domain route
+// Returns false if the path cannot be travelled or violates the criteria
+func composeRoute(path *routing.Path, segmentsByID map[string]*domain.Segment, criteria *domain.RouteSearchCriteria, rules *domain.MinConnectionTable) (domain.Route, bool) {
+	route := domain.Route{
+		ID:               utils.GenerateID(),
+		FromCity:         criteria.FromCity,
+		ToCity:           criteria.ToCity,
+		Segments:         make([]domain.Segment, 0, len(path.Edges)),
+		ReliabilityScore: 100,
+		SavedAt:          time.Now(),
+	}
+
+	// Walk/taxi transfer taken before each segment (index = segment position)
+	transfers := make(map[int]*graph.Edge)
+	var pendingTransfer *graph.Edge
+
+	transportTypes := make(map[domain.TransportType]bool)
+	for _, edge := range path.Edges {
+		if edge.Transfer {
+			pendingTransfer = edge
+			continue
+		}
+
+		segment, exists := segmentsByID[edge.ID]
+		if !exists {
+			return domain.Route{}, false
+		}
+
+		// Transfers before the first segment stay inside the origin city and are dropped
+		if len(route.Segments) > 0 && pendingTransfer != nil {
+			transfers[len(route.Segments)] = pendingTransfer
+		}
+		pendingTransfer = nil
+
+		route.Segments = append(route.Segments, *segment)
+		route.TotalPrice = route.TotalPrice.Add(segment.Price)
+		route.ReliabilityScore *= segment.ReliabilityRate / 100
+
+		if !transportTypes[segment.TransportType] {
+			transportTypes[segment.TransportType] = true
+			route.TransportTypes = append(route.TransportTypes, segment.TransportType)
+		}
+	}
+
+	if len(route.Segments) == 0 || len(route.Segments)-1 > criteria.MaxConnections {
+		return domain.Route{}, false
+	}
+
+	for i := 0; i < len(route.Segments)-1; i++ {
+		connection := buildConnection(&route.Segments[i], &route.Segments[i+1], transfers[i+1])
+		rules.Check(&connection)
+		if !connection.IsValid || connection.Gap > time.Duration(criteria.MaxTransferTime)*time.Minute {
+			return domain.Route{}, false
+		}
+		route.Connections = append(route.Connections, connection)
+	}
+
+	first := route.Segments[0]
+	last := route.Segments[len(route.Segments)-1]
+	route.DepartureTime = first.DepartureTime
+	route.ArrivalTime = last.ArrivalTime
+	route.TotalDuration = last.ArrivalTime.Sub(first.DepartureTime)
+
+	if criteria.BudgetMax > 0 && route.TotalPrice.Major() > criteria.BudgetMax {
+		return domain.Route{}, false
+	}
+	if criteria.BudgetMin > 0 && route.TotalPrice.Major() < criteria.BudgetMin {
+		return domain.Route{}, false
+	}
+
+	return route, true
+}
+
+// buildConnection describes the transfer between two consecutive segments
+// transfer is the generated walk/taxi edge between their stops, if any.
+// IsValid is decided by the minimum connection time table (see MinConnectionTable.Check).
+func buildConnection(from, to *domain.Segment, transfer *graph.Edge) domain.Connection {
+	gap := to.DepartureTime.Sub(from.ArrivalTime)
+
+	connection := domain.Connection{
+		From:             from,
+		To:               to,
+		TransferDuration: gap,
+		Gap:              gap,
+	}
+
+	if transfer != nil {
+		connection.TransferType = domain.TransportType(transfer.TransportType)
+		connection.TransferDuration = transfer.Duration
+		connection.TransferDistance = int(math.Round(transfer.Distance))
+		connection.TransferPrice = domain.MoneyFromFloat(transfer.Price, domain.SettlementCurrency)
+		connection.RequiresTransport = connection.TransferType == domain.TransportTaxi
+	} else if from.EndStop.ID != to.StartStop.ID {
+		connection.TransferDistance = int(utils.CalculateDistance(
+			from.EndStop.Latitude, from.EndStop.Longitude,
+			to.StartStop.Latitude, to.StartStop.Longitude,
+		))
+		connection.RequiresTransport = connection.TransferDistance > 0
+	}
+
+	return connection
+}
+
+// pathSignature identifies a path by its sequence of edges
+func pathSignature(path *routing.Path) string {
+	ids := make([]string, len(path.Edges))
+	for i, edge := range path.Edges {
+		ids[i] = edge.ID
+	}
+	return strings.Join(ids, "|")
+}
+
+// nodeIDs returns the IDs of the given nodes
+func nodeIDs(nodes []*graph.Node) []string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
+
+// cacheRoute keeps a composed route so it can be fetched by ID later
+func (s *RouteService) cacheRoute(route *domain.Route) {
+	if s.routeCache == nil {
+		return
+	}
+	s.routeCache.Set(routeCacheKey(route.ID), *route)
+}
+
+// cachedRoute returns a previously composed route
+func (s *RouteService) cachedRoute(id string) (*domain.Route, bool) {
+	if s.routeCache == nil {
+		return nil, false
+	}
+
+	value, ok := s.routeCache.Get(routeCacheKey(id))
+	if !ok {
+		return nil, false
+	}
+
+	route, ok := value.(domain.Route)
+	if !ok {
+		return nil, false
+	}
+	return &route, true
+}
+
+func routeCacheKey(id string) string {
+	return "route:" + id
+}
@@ -2,35 +2,98 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/graph"
 	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/internal/routing"
 	"github.com/lenalink/backend/pkg/utils"
 )
 
+// RouteSearchConfig holds parameters for live route composition
+type RouteSearchConfig struct {
+	MinTransferTime time.Duration // Minimum time between arrival and next departure when no rule matches
+	SearchWindow    time.Duration // Segments departing within this window after the departure date are considered
+	Transfers       graph.TransferConfig
+	ConnectionRules *domain.MinConnectionTable // Minimum connection times per stop/city and transport pair
+	ExchangeRates   *domain.ExchangeRates      // Converts provider prices into the settlement currency
+}
+
+// DefaultRouteSearchConfig returns default live search configuration
+func DefaultRouteSearchConfig() RouteSearchConfig {
+	return RouteSearchConfig{
+		MinTransferTime: 60 * time.Minute, // Same as the 1-hour connection rule
+		SearchWindow:    72 * time.Hour,
+		Transfers:       graph.DefaultTransferConfig(),
+		ConnectionRules: domain.DefaultMinConnectionTable(),
+		ExchangeRates:   domain.DefaultExchangeRates(),
+	}
+}
+
 // RouteService implements business logic for routes
 type RouteService struct {
-	routeRepo repository.RouteRepository
+	routeRepo   repository.RouteRepository
+	segmentRepo repository.SegmentRepository
+	stopRepo    repository.StopRepository
+	cities      *CityResolver
+	routeCache  *utils.Cache
+	config      RouteSearchConfig
 }
 
 // NewRouteService creates a new route service
-func NewRouteService(routeRepo repository.RouteRepository) *RouteService {
+func NewRouteService(routeRepo repository.RouteRepository, segmentRepo repository.SegmentRepository, stopRepo repository.StopRepository, cities *CityResolver, routeCache *utils.Cache, config RouteSearchConfig) *RouteService {
 	return &RouteService{
-		routeRepo: routeRepo,
+		routeRepo:   routeRepo,
+		segmentRepo: segmentRepo,
+		stopRepo:    stopRepo,
+		cities:      cities,
+		routeCache:  routeCache,
+		config:      config,
 	}
 }
 
 // GetRouteByID retrieves a route by ID
+// Live-composed routes are only saved once booked, so they are looked up in the cache first
 func (s *RouteService) GetRouteByID(ctx context.Context, id string) (*domain.Route, error) {
 	if id == "" {
 		return nil, fmt.Errorf("route ID cannot be empty")
 	}
 
+	if route, ok := s.cachedRoute(id); ok {
+		return route, nil
+	}
+
 	return s.routeRepo.FindByID(ctx, id)
 }
 
+// BookableRoute retrieves a route to be booked
+// Bookings reference their route, so a live-composed route is saved on its first booking.
+// Only the route itself is saved: its segments are synced ones, shared with other routes.
+func (s *RouteService) BookableRoute(ctx context.Context, id string) (*domain.Route, error) {
+	route, ok := s.cachedRoute(id)
+	if !ok {
+		return s.GetRouteByID(ctx, id)
+	}
+
+	_, err := s.routeRepo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrRouteNotFound) {
+		if err := s.routeRepo.Save(ctx, route); err != nil {
+			// Another booking of the route may have saved it meanwhile
+			if _, findErr := s.routeRepo.FindByID(ctx, id); findErr != nil {
+				return nil, err
+			}
+		}
+		return route, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
 // SearchRoutes searches for routes based on criteria
 func (s *RouteService) SearchRoutes(ctx context.Context, criteria *domain.RouteSearchCriteria) (*domain.RouteSearchResult, error) {
 	if criteria == nil {
@@ -41,24 +104,20 @@ func (s *RouteService) SearchRoutes(ctx context.Context, criteria *domain.RouteS
 		return nil, err
 	}
 
+	// "Yakutsk", "Якутск" and "YKS" all search the same city
+	if s.cities != nil {
+		if err := s.cities.ResolveCriteria(ctx, criteria); err != nil {
+			return nil, err
+		}
+	}
+
 	// Find all routes matching criteria
-	routes, err := s.routeRepo.FindByCriteria(ctx, criteria)
+	routes, err := s.findRoutes(ctx, criteria)
 	if err != nil {
 		return nil, err
 	}
+	routes = selectAlternatives(routes, criteria.Weights)
 
-	if len(routes) == 0 {
-		return &domain.RouteSearchResult{
-			RequestID:     utils.GenerateID(),
-			FromCity:      criteria.FromCity,
-			ToCity:        criteria.ToCity,
-			DepartureDate: criteria.DepartureDate,
-			PassengerCount: criteria.PassengerCount,
-			SearchedAt:    time.Now(),
-		}, nil
-	}
-
-	// Select optimal, fastest, and cheapest routes
 	result := &domain.RouteSearchResult{
 		RequestID:      utils.GenerateID(),
 		FromCity:       criteria.FromCity,
@@ -68,11 +127,24 @@ func (s *RouteService) SearchRoutes(ctx context.Context, criteria *domain.RouteS
 		SearchedAt:     time.Now(),
 	}
 
-	// Find optimal route (highest reliability score)
+	if len(routes) == 0 {
+		// Saved routes have no diagnostics to explain an empty result
+		if criteria.Mode == domain.SearchModeSaved {
+			return result, nil
+		}
+		return nil, fmt.Errorf("no route matches the search from %s to %s: %w", criteria.FromCity, criteria.ToCity, routing.ErrNoPath)
+	}
+
+	// Select optimal, fastest, and cheapest routes
+
+	// Find optimal route (highest reliability score, or lowest blended cost
+	// when the client supplied weights - routes are already ranked by it)
 	optimalIdx := 0
-	for i := 1; i < len(routes); i++ {
-		if routes[i].ReliabilityScore > routes[optimalIdx].ReliabilityScore {
-			optimalIdx = i
+	if criteria.Weights == nil {
+		for i := 1; i < len(routes); i++ {
+			if routes[i].ReliabilityScore > routes[optimalIdx].ReliabilityScore {
+				optimalIdx = i
+			}
 		}
 	}
 	result.OptimalRoute = &routes[optimalIdx]
@@ -89,15 +161,63 @@ func (s *RouteService) SearchRoutes(ctx context.Context, criteria *domain.RouteS
 	// Find cheapest route (lowest price)
 	cheapestIdx := 0
 	for i := 1; i < len(routes); i++ {
-		if routes[i].TotalPrice < routes[cheapestIdx].TotalPrice {
+		if routes[i].TotalPrice.Cmp(routes[cheapestIdx].TotalPrice) < 0 {
 			cheapestIdx = i
 		}
 	}
 	result.CheapestRoute = &routes[cheapestIdx]
 
+	// Ranked alternatives
+	if len(routes) > criteria.Alternatives {
+		result.Alternatives = routes[:criteria.Alternatives]
+	} else {
+		result.Alternatives = routes
+	}
+
 	return result, nil
 }
 
+// findRoutes collects candidate routes according to the search mode
+func (s *RouteService) findRoutes(ctx context.Context, criteria *domain.RouteSearchCriteria) ([]domain.Route, error) {
+	switch criteria.Mode {
+	case domain.SearchModeSaved:
+		routes, err := s.routeRepo.FindByCriteria(ctx, criteria)
+		if err != nil {
+			return nil, err
+		}
+		return withoutCancelled(routes), nil
+	case domain.SearchModeLive:
+		return s.composeRoutes(ctx, criteria)
+	default:
+		routes, err := s.routeRepo.FindByCriteria(ctx, criteria)
+		if err != nil {
+			return nil, err
+		}
+		if routes = withoutCancelled(routes); len(routes) > 0 {
+			return routes, nil
+		}
+		return s.composeRoutes(ctx, criteria)
+	}
+}
+
+// withoutCancelled drops saved routes that use a segment cancelled by its carrier
+func withoutCancelled(routes []domain.Route) []domain.Route {
+	running := routes[:0]
+	for _, route := range routes {
+		cancelled := false
+		for i := range route.Segments {
+			if route.Segments[i].IsCancelled() {
+				cancelled = true
+				break
+			}
+		}
+		if !cancelled {
+			running = append(running, route)
+		}
+	}
+	return running
+}
+
 // SaveRoute saves a new route
 func (s *RouteService) SaveRoute(ctx context.Context, route *domain.Route) error {
 	if route == nil {
@@ -172,6 +292,22 @@ func (s *RouteService) validateSearchCriteria(criteria *domain.RouteSearchCriter
 		criteria.MaxTransferTime = 1440 // 24 hours
 	}
 
+	if criteria.Alternatives <= 0 {
+		criteria.Alternatives = DefaultAlternatives
+	}
+
+	if criteria.Alternatives > MaxAlternatives {
+		criteria.Alternatives = MaxAlternatives
+	}
+
+	switch criteria.Mode {
+	case "":
+		criteria.Mode = domain.SearchModeAuto
+	case domain.SearchModeAuto, domain.SearchModeSaved, domain.SearchModeLive:
+	default:
+		return fmt.Errorf("unknown search mode: %s", criteria.Mode)
+	}
+
 	return nil
 }
 
@@ -192,7 +328,7 @@ func (s *RouteService) validateRoute(route *domain.Route) error {
 		return fmt.Errorf("route must have at least one segment")
 	}
 
-	if route.TotalPrice <= 0 {
+	if !route.TotalPrice.IsPositive() {
 		return fmt.Errorf("total_price must be greater than 0")
 	}
 
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// fakeRouteRepo keeps saved routes by ID, as the routes table would
+type fakeRouteRepo struct {
+	repository.RouteRepository
+	routes map[string]*domain.Route
+	saves  int
+}
+
+func (r *fakeRouteRepo) FindByID(ctx context.Context, id string) (*domain.Route, error) {
+	if route, ok := r.routes[id]; ok {
+		return route, nil
+	}
+	return nil, domain.ErrRouteNotFound
+}
+
+func (r *fakeRouteRepo) Save(ctx context.Context, route *domain.Route) error {
+	r.saves++
+	r.routes[route.ID] = route
+	return nil
+}
+
+func TestBookableRouteSavesComposedRoutes(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRouteRepo{routes: make(map[string]*domain.Route)}
+	cache := utils.NewCache(time.Hour, 100)
+	defer cache.Stop()
+	svc := NewRouteService(repo, nil, nil, nil, cache, DefaultRouteSearchConfig())
+
+	composed := domain.Route{ID: "live-1", FromCity: "Якутск", ToCity: "Ленск", Segments: []domain.Segment{{ID: "air"}}}
+	svc.cacheRoute(&composed)
+
+	for i := 0; i < 2; i++ {
+		route, err := svc.BookableRoute(ctx, "live-1")
+		if err != nil || len(route.Segments) != 1 {
+			t.Fatalf("expected the composed route with its segments, got %+v (%v)", route, err)
+		}
+	}
+	if repo.saves != 1 {
+		t.Fatalf("expected the composed route to be saved once for bookings to reference, saved %d times", repo.saves)
+	}
+
+	if _, err := svc.BookableRoute(ctx, "unknown"); err != domain.ErrRouteNotFound {
+		t.Fatalf("expected an unknown route not to be found, got %v", err)
+	}
+}
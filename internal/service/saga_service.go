@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// errManualCompensation marks a step that cannot be undone automatically
+var errManualCompensation = errors.New("needs manual compensation")
+
+// SagaConfig controls compensation retries and stuck saga detection
+type SagaConfig struct {
+	MaxCompensationAttempts int           // Attempts before a step is given up on
+	InitialBackoff          time.Duration // Delay before the second attempt, doubled each time
+	MaxBackoff              time.Duration // Upper bound for the delay
+	RetryInterval           time.Duration // How often due compensations are retried
+	StuckAfter              time.Duration // Unfinished sagas idle this long are reported as stuck
+	OrphanAfter             time.Duration // Running sagas idle this long were abandoned by their instance
+}
+
+// DefaultSagaConfig returns default saga configuration
+func DefaultSagaConfig() SagaConfig {
+	return SagaConfig{
+		MaxCompensationAttempts: 8,
+		InitialBackoff:          30 * time.Second,
+		MaxBackoff:              30 * time.Minute,
+		RetryInterval:           time.Minute,
+		StuckAfter:              15 * time.Minute,
+		OrphanAfter:             5 * time.Minute,
+	}
+}
+
+// SagaService records booking steps durably and undoes them when a booking fails
+// Every step is persisted before it runs, so a booking interrupted by a crash
+// can be compensated after restart.
+type SagaService struct {
+	repo            repository.SagaRepository
+	bookingRepo     repository.BookingRepository
+	providerBooking ProviderBookingService
+	seats           *SeatInventoryService
+	config          SagaConfig
+}
+
+// NewSagaService creates a new saga service
+func NewSagaService(
+	repo repository.SagaRepository,
+	bookingRepo repository.BookingRepository,
+	providerBooking ProviderBookingService,
+	seats *SeatInventoryService,
+	config SagaConfig,
+) *SagaService {
+	return &SagaService{
+		repo:            repo,
+		bookingRepo:     bookingRepo,
+		providerBooking: providerBooking,
+		seats:           seats,
+		config:          config,
+	}
+}
+
+// Start records a new running saga for a booking
+func (s *SagaService) Start(ctx context.Context, bookingID string) (*domain.BookingSaga, error) {
+	now := time.Now()
+	saga := &domain.BookingSaga{
+		ID:        utils.GenerateID(),
+		BookingID: bookingID,
+		Status:    domain.SagaRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Save(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to start saga: %w", err)
+	}
+
+	return saga, nil
+}
+
+// BeginStep records a pending step before it runs and returns its index
+func (s *SagaService) BeginStep(ctx context.Context, saga *domain.BookingSaga, stepType domain.SagaStepType, segmentID, passengerID string) (int, error) {
+	now := time.Now()
+	saga.Steps = append(saga.Steps, domain.SagaStep{
+		ID:          utils.GenerateID(),
+		SagaID:      saga.ID,
+		Sequence:    len(saga.Steps) + 1,
+		Type:        stepType,
+		SegmentID:   segmentID,
+		PassengerID: passengerID,
+		Status:      domain.SagaStepPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+
+	step := len(saga.Steps) - 1
+	if err := s.repo.SaveStep(ctx, &saga.Steps[step]); err != nil {
+		return step, fmt.Errorf("failed to record saga step: %w", err)
+	}
+
+	return step, nil
+}
+
+// CompleteStep records that a step succeeded, with the reference needed to undo it
+func (s *SagaService) CompleteStep(ctx context.Context, saga *domain.BookingSaga, step int, reference string) error {
+	saga.Steps[step].Status = domain.SagaStepDone
+	saga.Steps[step].Reference = reference
+	return s.saveStep(ctx, &saga.Steps[step])
+}
+
+// FailStep records that a step failed and has nothing to undo
+func (s *SagaService) FailStep(ctx context.Context, saga *domain.BookingSaga, step int, cause error) error {
+	saga.Steps[step].Status = domain.SagaStepFailed
+	saga.Steps[step].Error = cause.Error()
+	return s.saveStep(ctx, &saga.Steps[step])
+}
+
+// Complete marks the saga as finished successfully
+func (s *SagaService) Complete(ctx context.Context, saga *domain.BookingSaga) error {
+	return s.setStatus(ctx, saga, domain.SagaCompleted, "")
+}
+
+// Compensate undoes the completed steps of a failed saga in reverse order
+// Steps that cannot be undone right now are retried later with backoff.
+func (s *SagaService) Compensate(ctx context.Context, saga *domain.BookingSaga, reason string) error {
+	if err := s.setStatus(ctx, saga, domain.SagaCompensating, reason); err != nil {
+		return err
+	}
+	return s.compensate(ctx, saga)
+}
+
+// Recover resumes sagas left unfinished by a crashed instance
+// A running saga touches updated_at with every step, so one idle for OrphanAfter was
+// abandoned; sagas that are still progressing belong to a live instance and are left
+// alone. OrphanAfter must exceed the longest provider or gateway call.
+func (s *SagaService) Recover(ctx context.Context) (int, error) {
+	sagas, err := s.repo.FindByStatus(ctx, domain.SagaRunning, domain.SagaCompensating)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.resume(ctx, sagas, time.Now())
+}
+
+// RetryCompensations retries compensation steps whose backoff has elapsed and
+// recovers sagas orphaned since the last run
+func (s *SagaService) RetryCompensations(ctx context.Context) error {
+	_, err := s.Recover(ctx)
+	return err
+}
+
+// FindStuck returns sagas that need manual attention
+func (s *SagaService) FindStuck(ctx context.Context) ([]domain.BookingSaga, error) {
+	sagas, err := s.repo.FindByStatus(ctx, domain.SagaRunning, domain.SagaCompensating, domain.SagaFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stuck := make([]domain.BookingSaga, 0, len(sagas))
+	for _, saga := range sagas {
+		if saga.IsStuck(now, s.config.StuckAfter) {
+			stuck = append(stuck, saga)
+		}
+	}
+
+	return stuck, nil
+}
+
+// RetryInterval returns how often RetryCompensations should run
+func (s *SagaService) RetryInterval() time.Duration {
+	return s.config.RetryInterval
+}
+
+// resume compensates sagas due for it and recovers orphaned running ones
+func (s *SagaService) resume(ctx context.Context, sagas []domain.BookingSaga, now time.Time) (int, error) {
+	resumed := 0
+	for i := range sagas {
+		saga := &sagas[i]
+		if saga.Status == domain.SagaRunning {
+			if now.Sub(saga.UpdatedAt) <= s.config.OrphanAfter {
+				continue
+			}
+			if err := s.recoverRunning(ctx, saga); err != nil {
+				return resumed, err
+			}
+		} else if err := s.compensate(ctx, saga); err != nil {
+			return resumed, err
+		}
+		resumed++
+	}
+
+	return resumed, nil
+}
+
+// recoverRunning finishes a saga interrupted mid-way: if its booking was saved
+// the saga had in fact succeeded, otherwise everything it did is undone.
+// A paid-first booking still awaiting its tickets was interrupted while they were
+// issued; it is ticketed again when its payment is reconciled.
+func (s *SagaService) recoverRunning(ctx context.Context, saga *domain.BookingSaga) error {
+	booking, err := s.bookingRepo.FindByID(ctx, saga.BookingID)
+	if err == nil && (booking.Status == domain.BookingConfirmed ||
+		(booking.Status == domain.BookingPendingPayment && !booking.AwaitingTickets())) {
+		return s.Complete(ctx, saga)
+	}
+
+	return s.Compensate(ctx, saga, "interrupted before the booking was saved")
+}
+
+// compensate runs every due compensation once and updates the saga status
+func (s *SagaService) compensate(ctx context.Context, saga *domain.BookingSaga) error {
+	now := time.Now()
+	waiting, gaveUp := false, false
+
+	for i := len(saga.Steps) - 1; i >= 0; i-- {
+		step := &saga.Steps[i]
+		if !step.NeedsCompensation() {
+			gaveUp = gaveUp || step.Status == domain.SagaStepCompensationFailed
+			continue
+		}
+		if step.NextAttemptAt != nil && now.Before(*step.NextAttemptAt) {
+			waiting = true
+			continue
+		}
+
+		err := s.undo(ctx, step)
+		step.Attempts++
+		switch {
+		case err == nil:
+			step.Status = domain.SagaStepCompensated
+			step.Error = ""
+			step.NextAttemptAt = nil
+		case errors.Is(err, errManualCompensation) || step.Attempts >= s.config.MaxCompensationAttempts:
+			step.Status = domain.SagaStepCompensationFailed
+			step.Error = err.Error()
+			step.NextAttemptAt = nil
+			gaveUp = true
+		default:
+			next := now.Add(s.backoff(step.Attempts))
+			step.Error = err.Error()
+			step.NextAttemptAt = &next
+			waiting = true
+		}
+
+		if err := s.saveStep(ctx, step); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case waiting:
+		return nil
+	case gaveUp:
+		return s.setStatus(ctx, saga, domain.SagaFailed, "some steps could not be compensated")
+	default:
+		// Holds expire on their own, so a failure here is not worth retrying
+		_ = s.seats.Release(ctx, saga.BookingID)
+		return s.setStatus(ctx, saga, domain.SagaCompensated, saga.LastError)
+	}
+}
+
+// undo reverses a single step
+func (s *SagaService) undo(ctx context.Context, step *domain.SagaStep) error {
+	switch step.Type {
+	case domain.SagaStepBookSegment:
+		if step.Reference == "" {
+			return fmt.Errorf("provider booking reference unknown, check segment %s with the provider: %w", step.SegmentID, errManualCompensation)
+		}
+		return s.providerBooking.CancelBooking(ctx, step.Reference)
+	case domain.SagaStepProcessPayment:
+		if step.Status == domain.SagaStepDone {
+			return fmt.Errorf("payment %s was taken, refund it through the gateway: %w", step.Reference, errManualCompensation)
+		}
+		return fmt.Errorf("payment outcome unknown, check it with the gateway: %w", errManualCompensation)
+	case domain.SagaStepCapturePayment:
+		if step.Status == domain.SagaStepDone {
+			return fmt.Errorf("payment %s was captured, refund it through the gateway: %w", step.Reference, errManualCompensation)
+		}
+		return fmt.Errorf("capture outcome unknown, check it with the gateway: %w", errManualCompensation)
+	default:
+		return fmt.Errorf("unknown step type %s: %w", step.Type, errManualCompensation)
+	}
+}
+
+// backoff returns the delay before the next attempt: InitialBackoff doubled per attempt, capped at MaxBackoff
+func (s *SagaService) backoff(attempts int) time.Duration {
+	delay := s.config.InitialBackoff
+	for i := 1; i < attempts && delay < s.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.config.MaxBackoff {
+		delay = s.config.MaxBackoff
+	}
+	return delay
+}
+
+func (s *SagaService) saveStep(ctx context.Context, step *domain.SagaStep) error {
+	step.UpdatedAt = time.Now()
+	if err := s.repo.SaveStep(ctx, step); err != nil {
+		return fmt.Errorf("failed to record saga step: %w", err)
+	}
+	return nil
+}
+
+func (s *SagaService) setStatus(ctx context.Context, saga *domain.BookingSaga, status domain.SagaStatus, lastError string) error {
+	saga.Status = status
+	saga.LastError = lastError
+	saga.UpdatedAt = time.Now()
+	if err := s.repo.UpdateStatus(ctx, saga); err != nil {
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// fakeSagaRepo keeps sagas in memory the way the database would: steps are saved on their own
+type fakeSagaRepo struct {
+	sagas map[string]*domain.BookingSaga
+}
+
+func (r *fakeSagaRepo) Save(ctx context.Context, saga *domain.BookingSaga) error {
+	stored := *saga
+	stored.Steps = append([]domain.SagaStep(nil), saga.Steps...)
+	r.sagas[saga.ID] = &stored
+	return nil
+}
+
+func (r *fakeSagaRepo) SaveStep(ctx context.Context, step *domain.SagaStep) error {
+	saga, ok := r.sagas[step.SagaID]
+	if !ok {
+		return fmt.Errorf("saga not found: %s", step.SagaID)
+	}
+	for i := range saga.Steps {
+		if saga.Steps[i].ID == step.ID {
+			saga.Steps[i] = *step
+			saga.UpdatedAt = step.UpdatedAt
+			return nil
+		}
+	}
+	saga.Steps = append(saga.Steps, *step)
+	saga.UpdatedAt = step.UpdatedAt
+	return nil
+}
+
+func (r *fakeSagaRepo) UpdateStatus(ctx context.Context, saga *domain.BookingSaga) error {
+	stored, ok := r.sagas[saga.ID]
+	if !ok {
+		return fmt.Errorf("saga not found: %s", saga.ID)
+	}
+	stored.Status, stored.LastError, stored.UpdatedAt = saga.Status, saga.LastError, saga.UpdatedAt
+	return nil
+}
+
+func (r *fakeSagaRepo) FindByID(ctx context.Context, id string) (*domain.BookingSaga, error) {
+	saga, ok := r.sagas[id]
+	if !ok {
+		return nil, fmt.Errorf("saga not found: %s", id)
+	}
+	found := *saga
+	found.Steps = append([]domain.SagaStep(nil), saga.Steps...)
+	return &found, nil
+}
+
+func (r *fakeSagaRepo) FindByStatus(ctx context.Context, statuses ...domain.SagaStatus) ([]domain.BookingSaga, error) {
+	sagas := make([]domain.BookingSaga, 0)
+	for id, saga := range r.sagas {
+		for _, status := range statuses {
+			if saga.Status == status {
+				found, _ := r.FindByID(ctx, id)
+				sagas = append(sagas, *found)
+			}
+		}
+	}
+	return sagas, nil
+}
+
+// fakeProviderBooking records cancellations and fails the refs in failing
+type fakeProviderBooking struct {
+	cancelled []string
+	failing   map[string]bool
+}
+
+func (p *fakeProviderBooking) BookSegment(ctx context.Context, segment *domain.Segment, passenger *domain.Passenger) (string, string, error) {
+	return "", "", errors.New("not used")
+}
+
+func (p *fakeProviderBooking) CancelBooking(ctx context.Context, bookingRef string) error {
+	if p.failing[bookingRef] {
+		return errors.New("provider unavailable")
+	}
+	p.cancelled = append(p.cancelled, bookingRef)
+	return nil
+}
+
+type fakeSagaBookingRepo struct {
+	repository.BookingRepository
+	bookings map[string]*domain.Booking
+}
+
+func (r *fakeSagaBookingRepo) FindByID(ctx context.Context, id string) (*domain.Booking, error) {
+	if booking, ok := r.bookings[id]; ok {
+		return booking, nil
+	}
+	return nil, domain.ErrBookingNotFound
+}
+
+type fakeSeatRepo struct {
+	repository.SeatInventoryRepository
+	released []string
+}
+
+func (r *fakeSeatRepo) ReleaseByBooking(ctx context.Context, bookingID string) error {
+	r.released = append(r.released, bookingID)
+	return nil
+}
+
+func (r *fakeSeatRepo) ReturnSeats(ctx context.Context, bookingID, segmentID string, seats int) error {
+	return nil
+}
+
+type sagaFixture struct {
+	svc      *SagaService
+	repo     *fakeSagaRepo
+	provider *fakeProviderBooking
+	bookings *fakeSagaBookingRepo
+	seats    *fakeSeatRepo
+}
+
+func newSagaFixture() *sagaFixture {
+	f := &sagaFixture{
+		repo:     &fakeSagaRepo{sagas: make(map[string]*domain.BookingSaga)},
+		provider: &fakeProviderBooking{failing: make(map[string]bool)},
+		bookings: &fakeSagaBookingRepo{bookings: make(map[string]*domain.Booking)},
+		seats:    &fakeSeatRepo{},
+	}
+	seats := NewSeatInventoryService(f.seats, DefaultSeatHoldConfig())
+	f.svc = NewSagaService(f.repo, f.bookings, f.provider, seats, DefaultSagaConfig())
+	return f
+}
+
+// bookSegments starts a saga for the booking with one completed provider booking per ref
+func (f *sagaFixture) bookSegments(t *testing.T, bookingID string, refs ...string) *domain.BookingSaga {
+	t.Helper()
+	ctx := context.Background()
+
+	saga, err := f.svc.Start(ctx, bookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ref := range refs {
+		step, err := f.svc.BeginStep(ctx, saga, domain.SagaStepBookSegment, fmt.Sprintf("seg-%d", i), "p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.svc.CompleteStep(ctx, saga, step, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return saga
+}
+
+// age moves a stored saga and its steps into the past
+func (f *sagaFixture) age(sagaID string, by time.Duration) {
+	saga := f.repo.sagas[sagaID]
+	saga.UpdatedAt = saga.UpdatedAt.Add(-by)
+	for i := range saga.Steps {
+		if next := saga.Steps[i].NextAttemptAt; next != nil {
+			earlier := next.Add(-by)
+			saga.Steps[i].NextAttemptAt = &earlier
+		}
+	}
+}
+
+func TestSagaCompensatesInReverseOrder(t *testing.T) {
+	f := newSagaFixture()
+	ctx := context.Background()
+
+	saga := f.bookSegments(t, "b1", "ref-1", "ref-2", "ref-3")
+	step, _ := f.svc.BeginStep(ctx, saga, domain.SagaStepBookSegment, "seg-3", "p1")
+	f.svc.FailStep(ctx, saga, step, errors.New("no seats"))
+
+	if err := f.svc.Compensate(ctx, saga, "no seats"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(f.provider.cancelled); got != "[ref-3 ref-2 ref-1]" {
+		t.Fatalf("expected provider bookings cancelled last to first, got %s", got)
+	}
+	stored := f.repo.sagas[saga.ID]
+	if stored.Status != domain.SagaCompensated {
+		t.Fatalf("expected a compensated saga, got %s", stored.Status)
+	}
+	if stored.Steps[3].Status != domain.SagaStepFailed {
+		t.Fatalf("expected the failed step to be left alone, got %s", stored.Steps[3].Status)
+	}
+	if len(f.seats.released) != 1 || f.seats.released[0] != "b1" {
+		t.Fatalf("expected the booking's seats to be released, got %v", f.seats.released)
+	}
+}
+
+func TestSagaRetriesCompensationWithBackoff(t *testing.T) {
+	f := newSagaFixture()
+	ctx := context.Background()
+	config := f.svc.config
+
+	saga := f.bookSegments(t, "b1", "ref-1", "ref-2")
+	f.provider.failing["ref-2"] = true
+
+	if err := f.svc.Compensate(ctx, saga, "payment declined"); err != nil {
+		t.Fatal(err)
+	}
+	stored := f.repo.sagas[saga.ID]
+	step := stored.Steps[1]
+	if stored.Status != domain.SagaCompensating || step.Attempts != 1 || step.NextAttemptAt == nil {
+		t.Fatalf("expected the failed cancellation to be scheduled for retry, got %s %+v", stored.Status, step)
+	}
+	if wait := time.Until(*step.NextAttemptAt); wait <= 0 || wait > config.InitialBackoff {
+		t.Fatalf("expected the first retry within %s, got %s", config.InitialBackoff, wait)
+	}
+
+	// Not due yet: nothing is attempted
+	if err := f.svc.RetryCompensations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if f.repo.sagas[saga.ID].Steps[1].Attempts != 1 {
+		t.Fatal("expected no attempt before the backoff elapsed")
+	}
+
+	// Due and failing again: the delay doubles
+	f.age(saga.ID, config.InitialBackoff)
+	if err := f.svc.RetryCompensations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	step = f.repo.sagas[saga.ID].Steps[1]
+	if wait := time.Until(*step.NextAttemptAt); step.Attempts != 2 || wait <= config.InitialBackoff || wait > 2*config.InitialBackoff {
+		t.Fatalf("expected the second retry after %s, got attempt %d in %s", 2*config.InitialBackoff, step.Attempts, wait)
+	}
+
+	// Provider is back: the saga finishes
+	f.provider.failing["ref-2"] = false
+	f.age(saga.ID, 2*config.InitialBackoff)
+	if err := f.svc.RetryCompensations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stored := f.repo.sagas[saga.ID]; stored.Status != domain.SagaCompensated || stored.Steps[1].Status != domain.SagaStepCompensated {
+		t.Fatalf("expected the saga to be compensated, got %s", stored.Status)
+	}
+
+	if got := f.svc.backoff(30); got != config.MaxBackoff {
+		t.Fatalf("expected the backoff to be capped at %s, got %s", config.MaxBackoff, got)
+	}
+}
+
+func TestSagaGivesUpAfterMaxAttempts(t *testing.T) {
+	f := newSagaFixture()
+	ctx := context.Background()
+	f.svc.config.MaxCompensationAttempts = 2
+
+	saga := f.bookSegments(t, "b1", "ref-1")
+	f.provider.failing["ref-1"] = true
+
+	f.svc.Compensate(ctx, saga, "payment declined")
+	f.age(saga.ID, time.Hour)
+	if err := f.svc.RetryCompensations(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := f.repo.sagas[saga.ID]
+	if stored.Status != domain.SagaFailed || stored.Steps[0].Status != domain.SagaStepCompensationFailed {
+		t.Fatalf("expected the saga to fail after 2 attempts, got %s", stored.Status)
+	}
+	if len(f.seats.released) != 0 {
+		t.Fatal("expected seats of a failed saga to stay held for manual handling")
+	}
+}
+
+func TestSagaRecoverOrphans(t *testing.T) {
+	f := newSagaFixture()
+	ctx := context.Background()
+
+	// Booking was saved before the crash: the saga had succeeded
+	saved := f.bookSegments(t, "saved", "ref-saved")
+	f.bookings.bookings["saved"] = &domain.Booking{ID: "saved", Status: domain.BookingConfirmed}
+	f.age(saved.ID, time.Hour)
+
+	// Booking never saved: everything is undone
+	lost := f.bookSegments(t, "lost", "ref-lost")
+	f.age(lost.ID, time.Hour)
+
+	// Still progressing on another instance
+	live := f.bookSegments(t, "live", "ref-live")
+
+	recovered, err := f.svc.Recover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != 2 {
+		t.Fatalf("expected 2 orphaned sagas recovered, got %d", recovered)
+	}
+
+	if status := f.repo.sagas[saved.ID].Status; status != domain.SagaCompleted {
+		t.Fatalf("expected the saved booking's saga to complete, got %s", status)
+	}
+	if status := f.repo.sagas[lost.ID].Status; status != domain.SagaCompensated {
+		t.Fatalf("expected the lost booking's saga to be compensated, got %s", status)
+	}
+	if status := f.repo.sagas[live.ID].Status; status != domain.SagaRunning {
+		t.Fatalf("expected the live saga to be left alone, got %s", status)
+	}
+	if got := fmt.Sprint(f.provider.cancelled); got != "[ref-lost]" {
+		t.Fatalf("expected only the lost booking to be cancelled, got %s", got)
+	}
+}
+
+func TestSagaFindStuck(t *testing.T) {
+	f := newSagaFixture()
+	ctx := context.Background()
+	f.svc.config.MaxCompensationAttempts = 1
+
+	failed := f.bookSegments(t, "failed", "ref-failed")
+	f.provider.failing["ref-failed"] = true
+	f.svc.Compensate(ctx, failed, "payment declined")
+
+	idle := f.bookSegments(t, "idle", "ref-idle")
+	f.age(idle.ID, f.svc.config.StuckAfter+time.Minute)
+
+	f.bookSegments(t, "busy", "ref-busy")
+
+	stuck, err := f.svc.FindStuck(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, saga := range stuck {
+		found[saga.BookingID] = true
+	}
+	if len(stuck) != 2 || !found["failed"] || !found["idle"] {
+		t.Fatalf("expected the failed and idle sagas to be stuck, got %v", found)
+	}
+}
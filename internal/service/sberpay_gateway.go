@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// SberPayGateway implements PaymentGateway for SberPay (Sberbank internet acquiring)
+// The customer pays on the bank's payment form. Sberbank knows the payment by the
+// order ID it assigns on registration; amounts are in kopecks.
+type SberPayGateway struct {
+	baseURL       string
+	userName      string
+	password      string
+	callbackToken string
+	returnURL     string
+	client        *http.Client
+}
+
+// NewSberPayGateway creates a new SberPay payment gateway
+func NewSberPayGateway(baseURL, userName, password, callbackToken, returnURL string) *SberPayGateway {
+	return &SberPayGateway{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		userName:      userName,
+		password:      password,
+		callbackToken: callbackToken,
+		returnURL:     returnURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// sberPayOrderStatus is the response of getOrderStatusExtended.do
+type sberPayOrderStatus struct {
+	OrderNumber         string `json:"orderNumber"`
+	OrderStatus         int    `json:"orderStatus"`
+	Amount              int64  `json:"amount"`
+	Currency            string `json:"currency"`
+	MerchantOrderParams []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"merchantOrderParams"`
+	PaymentAmountInfo struct {
+		RefundedAmount int64 `json:"refundedAmount"`
+	} `json:"paymentAmountInfo"`
+}
+
+// ProcessPayment registers an order in SberPay
+func (g *SberPayGateway) ProcessPayment(ctx context.Context, payment *domain.Payment) error {
+	jsonParams, err := json.Marshal(map[string]string{
+		"order_id":   payment.OrderID,
+		"payment_id": payment.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("sberpay register order failed: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("orderNumber", payment.ID)
+	params.Set("amount", strconv.FormatInt(payment.Amount.Amount, 10))
+	params.Set("currency", sberPayCurrencyCode(payment.Amount.Currency))
+	params.Set("returnUrl", g.returnURL)
+	params.Set("description", fmt.Sprintf("LenaLink: Бронирование %s", payment.OrderID))
+	params.Set("jsonParams", string(jsonParams))
+
+	// Two-stage payments are held and deposited after the tickets are issued
+	method := "register.do"
+	if payment.TwoStage {
+		method = "registerPreAuth.do"
+	}
+
+	var order struct {
+		OrderID string `json:"orderId"`
+		FormURL string `json:"formUrl"`
+	}
+	if err := g.call(ctx, method, params, &order); err != nil {
+		return fmt.Errorf("sberpay register order failed: %w", err)
+	}
+
+	payment.ProviderPaymentID = order.OrderID
+	payment.ConfirmationURL = order.FormURL
+
+	// Payment is pending until the customer pays on the bank's form
+	payment.Status = domain.PaymentPending
+
+	return nil
+}
+
+// RedirectsCustomer marks the gateway as redirect-only: SberPay orders are paid on the bank's form
+func (g *SberPayGateway) RedirectsCustomer() {}
+
+// RefundPayment refunds (part of) a deposited order in SberPay
+// Receipts are not sent: SberPay's order bundles are not supported yet.
+func (g *SberPayGateway) RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error {
+	params := url.Values{}
+	params.Set("orderId", paymentID)
+	params.Set("amount", strconv.FormatInt(amount.Amount, 10))
+
+	if err := g.call(ctx, "refund.do", params, nil); err != nil {
+		return fmt.Errorf("sberpay refund failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetPaymentStatus retrieves payment status from SberPay
+func (g *SberPayGateway) GetPaymentStatus(ctx context.Context, paymentID string) (domain.PaymentStatus, error) {
+	status, err := g.orderStatus(ctx, paymentID)
+	if err != nil {
+		return "", fmt.Errorf("sberpay get order status failed: %w", err)
+	}
+
+	return sberPayStatus(status.OrderStatus), nil
+}
+
+// CapturePayment deposits a pre-authorized (two-stage) order in SberPay
+func (g *SberPayGateway) CapturePayment(ctx context.Context, paymentID string, amount domain.Money) error {
+	params := url.Values{}
+	params.Set("orderId", paymentID)
+	params.Set("amount", strconv.FormatInt(amount.Amount, 10))
+
+	if err := g.call(ctx, "deposit.do", params, nil); err != nil {
+		return fmt.Errorf("sberpay deposit failed: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPayment reverses a pre-authorized (two-stage) order in SberPay, releasing the held funds
+func (g *SberPayGateway) CancelPayment(ctx context.Context, paymentID string) error {
+	params := url.Values{}
+	params.Set("orderId", paymentID)
+
+	if err := g.call(ctx, "reverse.do", params, nil); err != nil {
+		return fmt.Errorf("sberpay reverse failed: %w", err)
+	}
+
+	return nil
+}
+
+// FindPayment retrieves an order from SberPay
+func (g *SberPayGateway) FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error) {
+	status, err := g.orderStatus(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("sberpay get order status failed: %w", err)
+	}
+
+	payment := &GatewayPayment{
+		ID:     paymentID,
+		Status: sberPayStatus(status.OrderStatus),
+		Amount: domain.NewMoney(status.Amount, sberPayCurrency(status.Currency)),
+	}
+	for _, param := range status.MerchantOrderParams {
+		if param.Name == "order_id" {
+			payment.OrderID = param.Value
+		}
+	}
+
+	return payment, nil
+}
+
+// FindRefund retrieves the refunds of an order from SberPay
+// SberPay refunds have no ID of their own; they are looked up by the order ID.
+func (g *SberPayGateway) FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error) {
+	status, err := g.orderStatus(ctx, refundID)
+	if err != nil {
+		return nil, fmt.Errorf("sberpay get order status failed: %w", err)
+	}
+
+	return &GatewayRefund{
+		ID:        refundID,
+		PaymentID: refundID,
+		Succeeded: status.PaymentAmountInfo.RefundedAmount > 0,
+		Amount:    domain.NewMoney(status.PaymentAmountInfo.RefundedAmount, sberPayCurrency(status.Currency)),
+	}, nil
+}
+
+// VerifyNotification checks the checksum of a callback
+// The callback's parameters except checksum and sign_alias, sorted by name and joined
+// as "name;value;", are signed with HMAC-SHA256 and the callback token.
+func (g *SberPayGateway) VerifyNotification(body []byte, signature string) error {
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Errorf("invalid SberPay callback: %w", err)
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "checksum" && name != "sign_alias" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var signed strings.Builder
+	for _, name := range names {
+		signed.WriteString(name + ";" + params.Get(name) + ";")
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.callbackToken))
+	mac.Write([]byte(signed.String()))
+	expected := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	if !hmac.Equal([]byte(expected), []byte(strings.ToUpper(signature))) {
+		return errors.New("invalid SberPay callback checksum")
+	}
+	return nil
+}
+
+// orderStatus fetches the extended status of an order
+func (g *SberPayGateway) orderStatus(ctx context.Context, orderID string) (*sberPayOrderStatus, error) {
+	params := url.Values{}
+	params.Set("orderId", orderID)
+
+	var status sberPayOrderStatus
+	if err := g.call(ctx, "getOrderStatusExtended.do", params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// call posts a form to a SberPay REST method and decodes the response
+// A non-zero errorCode is an error, whatever the HTTP status.
+func (g *SberPayGateway) call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	params.Set("userName", g.userName)
+	params.Set("password", g.password)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	// errorCode is a string in some methods and a number in others
+	var result struct {
+		ErrorCode    json.RawMessage `json:"errorCode"`
+		ErrorMessage string          `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if code := strings.Trim(string(result.ErrorCode), `"`); code != "" && code != "0" {
+		return fmt.Errorf("request rejected (%s): %s", code, result.ErrorMessage)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sberPayStatus maps a SberPay order status to the domain status
+func sberPayStatus(status int) domain.PaymentStatus {
+	switch status {
+	case 1: // Amount held (pre-authorized)
+		return domain.PaymentAuthorized
+	case 2: // Amount deposited
+		return domain.PaymentCompleted
+	case 3, 6: // Authorization reversed, or declined
+		return domain.PaymentFailed
+	case 4: // Refunded
+		return domain.PaymentRefunded
+	default: // 0 registered, 5 ACS authorization in progress
+		return domain.PaymentPending
+	}
+}
+
+// sberPayCurrencyCodes maps currencies to the ISO 4217 numeric codes SberPay uses
+var sberPayCurrencyCodes = map[domain.Currency]string{
+	domain.CurrencyRUB: "643",
+	domain.CurrencyUSD: "840",
+	domain.CurrencyEUR: "978",
+	domain.CurrencyCNY: "156",
+}
+
+// sberPayCurrencyCode returns the ISO 4217 numeric code SberPay expects
+func sberPayCurrencyCode(currency domain.Currency) string {
+	if code, ok := sberPayCurrencyCodes[currency]; ok {
+		return code
+	}
+	return string(currency)
+}
+
+// sberPayCurrency returns the currency of a SberPay numeric code
+// Orders registered without a currency are in rubles.
+func sberPayCurrency(code string) domain.Currency {
+	if code == "" {
+		return domain.CurrencyRUB
+	}
+	for currency, numeric := range sberPayCurrencyCodes {
+		if numeric == code {
+			return currency
+		}
+	}
+	return domain.Currency(code)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// fakeSberPay serves the SberPay REST methods the gateway uses
+func fakeSberPay(t *testing.T, requests map[string]url.Values) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("%s: invalid form: %v", r.URL.Path, err)
+		}
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		requests[method] = r.PostForm
+
+		if r.PostForm.Get("userName") != "merchant-api" || r.PostForm.Get("password") != "secret" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errorCode": "5", "errorMessage": "Access denied"})
+			return
+		}
+
+		var response map[string]interface{}
+		switch method {
+		case "register.do", "registerPreAuth.do":
+			response = map[string]interface{}{"orderId": "sber-1", "formUrl": "https://securepayments.sberbank.ru/payment/merchants/lenalink/payment_ru.html?mdOrder=sber-1"}
+		case "getOrderStatusExtended.do":
+			response = map[string]interface{}{
+				"errorCode":           "0",
+				"orderNumber":         "pay-1",
+				"orderStatus":         2,
+				"amount":              150050,
+				"currency":            "643",
+				"merchantOrderParams": []map[string]string{{"name": "order_id", "value": "booking-1"}},
+				"paymentAmountInfo":   map[string]interface{}{"refundedAmount": 50000},
+			}
+		default:
+			response = map[string]interface{}{"errorCode": 0}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestSberPayGateway(t *testing.T) {
+	ctx := context.Background()
+	requests := make(map[string]url.Values)
+	server := fakeSberPay(t, requests)
+	defer server.Close()
+
+	gateway := NewSberPayGateway(server.URL, "merchant-api", "secret", "token", "https://lenalink.ru/payment/success")
+
+	payment := &domain.Payment{ID: "pay-1", OrderID: "booking-1", Amount: domain.Rubles(1500.50), TwoStage: true}
+	if err := gateway.ProcessPayment(ctx, payment); err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+	if payment.ProviderPaymentID != "sber-1" || !strings.Contains(payment.ConfirmationURL, "mdOrder=sber-1") {
+		t.Fatalf("unexpected payment after registering the order: %+v", payment)
+	}
+	register, ok := requests["registerPreAuth.do"]
+	if !ok {
+		t.Fatal("expected a two-stage payment to be registered with pre-authorization")
+	}
+	if register.Get("amount") != "150050" || register.Get("currency") != "643" || register.Get("orderNumber") != "pay-1" {
+		t.Fatalf("unexpected register request: %v", register)
+	}
+
+	remote, err := gateway.FindPayment(ctx, "sber-1")
+	if err != nil {
+		t.Fatalf("FindPayment: %v", err)
+	}
+	if remote.Status != domain.PaymentCompleted || remote.OrderID != "booking-1" || remote.Amount != domain.Rubles(1500.50) {
+		t.Fatalf("unexpected payment: %+v", remote)
+	}
+
+	refund, err := gateway.FindRefund(ctx, "sber-1")
+	if err != nil || !refund.Succeeded || refund.Amount != domain.Rubles(500) || refund.PaymentID != "sber-1" {
+		t.Fatalf("unexpected refund: %+v (%v)", refund, err)
+	}
+
+	if err := gateway.CapturePayment(ctx, "sber-1", domain.Rubles(1500.50)); err != nil {
+		t.Fatalf("CapturePayment: %v", err)
+	}
+	if deposit := requests["deposit.do"]; deposit.Get("orderId") != "sber-1" || deposit.Get("amount") != "150050" {
+		t.Fatalf("unexpected deposit request: %v", deposit)
+	}
+
+	if err := NewSberPayGateway(server.URL, "merchant-api", "wrong", "token", "").CancelPayment(ctx, "sber-1"); err == nil {
+		t.Fatal("expected a non-zero errorCode to fail the request")
+	}
+}
+
+func TestSberPayCallbackChecksum(t *testing.T) {
+	gateway := NewSberPayGateway("http://localhost", "merchant-api", "secret", "token", "")
+
+	mac := hmac.New(sha256.New, []byte("token"))
+	mac.Write([]byte("mdOrder;sber-1;operation;deposited;orderNumber;pay-1;status;1;"))
+	checksum := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	callback := "orderNumber=pay-1&mdOrder=sber-1&operation=deposited&status=1&sign_alias=SHA-256&checksum=" + checksum
+	if err := gateway.VerifyNotification([]byte(callback), checksum); err != nil {
+		t.Fatalf("expected a valid checksum, got %v", err)
+	}
+
+	forged := strings.Replace(callback, "operation=deposited", "operation=refunded", 1)
+	if err := gateway.VerifyNotification([]byte(forged), checksum); err == nil {
+		t.Fatal("expected a tampered callback to be rejected")
+	}
+}
+
+func TestGatewayRegistry(t *testing.T) {
+	fallback := NewMockPaymentGateway(0)
+	sberPay := NewSberPayGateway("http://localhost", "merchant-api", "secret", "token", "")
+
+	registry := NewGatewayRegistry(fallback)
+	registry.Register(domain.PaymentSberPay, sberPay)
+
+	if registry.Gateway(domain.PaymentSberPay) != sberPay {
+		t.Fatal("expected SberPay payments to use the SberPay gateway")
+	}
+	if registry.Gateway(domain.PaymentCloudPay) != fallback || registry.Gateway(domain.PaymentCard) != fallback {
+		t.Fatal("expected methods without a gateway to use the fallback")
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+	"github.com/lenalink/backend/pkg/utils"
+)
+
+// SeatHoldConfig controls how long seats stay reserved for an unfinished booking
+type SeatHoldConfig struct {
+	HoldTTL       time.Duration // How long seats are held before payment must complete
+	SweepInterval time.Duration // How often expired holds are released
+}
+
+// DefaultSeatHoldConfig returns default seat hold configuration
+func DefaultSeatHoldConfig() SeatHoldConfig {
+	return SeatHoldConfig{
+		HoldTTL:       15 * time.Minute,
+		SweepInterval: time.Minute,
+	}
+}
+
+// SeatInventoryService holds seats on segments while bookings are made
+type SeatInventoryService struct {
+	repo   repository.SeatInventoryRepository
+	config SeatHoldConfig
+}
+
+// NewSeatInventoryService creates a new seat inventory service
+func NewSeatInventoryService(repo repository.SeatInventoryRepository, config SeatHoldConfig) *SeatInventoryService {
+	return &SeatInventoryService{
+		repo:   repo,
+		config: config,
+	}
+}
+
+// HoldRoute holds seats on every seated segment of the route for a booking
+// Either all segments are held or none; a short segment fails with SEATS_UNAVAILABLE.
+func (s *SeatInventoryService) HoldRoute(ctx context.Context, bookingID string, route *domain.Route, seats int) ([]domain.SeatHold, error) {
+	if seats <= 0 {
+		return nil, nil
+	}
+
+	requested := make(map[string]int, len(route.Segments))
+	for _, segment := range route.Segments {
+		if segment.TransportType.HasSeats() {
+			requested[segment.ID] = seats
+		}
+	}
+
+	return s.HoldSegments(ctx, bookingID, requested)
+}
+
+// HoldSegments holds seats on several segments (segment ID -> seats) for a booking
+// Either all segments are held or none; a short segment fails with SEATS_UNAVAILABLE.
+func (s *SeatInventoryService) HoldSegments(ctx context.Context, bookingID string, seats map[string]int) ([]domain.SeatHold, error) {
+	now := time.Now()
+	holds := make([]domain.SeatHold, 0, len(seats))
+	for segmentID, n := range seats {
+		if n <= 0 {
+			continue
+		}
+
+		holds = append(holds, domain.SeatHold{
+			ID:        utils.GenerateID(),
+			SegmentID: segmentID,
+			BookingID: bookingID,
+			Seats:     n,
+			Status:    domain.SeatHoldActive,
+			ExpiresAt: now.Add(s.config.HoldTTL),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.repo.Hold(ctx, holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// Available returns free seats on a segment
+func (s *SeatInventoryService) Available(ctx context.Context, segmentID string) (int, error) {
+	return s.repo.Available(ctx, segmentID)
+}
+
+// Confirm marks the booking's held seats as sold
+// Holds that expired before the payment arrived are sold only if the seats are still free.
+func (s *SeatInventoryService) Confirm(ctx context.Context, bookingID string) error {
+	return s.repo.ConfirmByBooking(ctx, bookingID)
+}
+
+// Release gives the booking's seats back to the segments
+func (s *SeatInventoryService) Release(ctx context.Context, bookingID string) error {
+	return s.repo.ReleaseByBooking(ctx, bookingID)
+}
+
+// ReturnSeats gives the seats of cancelled tickets on a segment back
+func (s *SeatInventoryService) ReturnSeats(ctx context.Context, bookingID, segmentID string, seats int) error {
+	if seats <= 0 {
+		return nil
+	}
+	return s.repo.ReturnSeats(ctx, bookingID, segmentID, seats)
+}
+
+// ReleaseHolds gives back seats held by holds that were never confirmed
+func (s *SeatInventoryService) ReleaseHolds(ctx context.Context, holds []domain.SeatHold) error {
+	ids := make([]string, len(holds))
+	for i, hold := range holds {
+		ids[i] = hold.ID
+	}
+	return s.repo.ReleaseHolds(ctx, ids)
+}
+
+// ReleaseExpired releases holds whose time ran out and returns how many were released
+func (s *SeatInventoryService) ReleaseExpired(ctx context.Context) (int, error) {
+	return s.repo.ReleaseExpired(ctx, time.Now())
+}
+
+// SweepInterval returns how often ReleaseExpired should run
+func (s *SeatInventoryService) SweepInterval() time.Duration {
+	return s.config.SweepInterval
+}
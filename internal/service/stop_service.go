@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+const (
+	// DefaultSuggestLimit is the number of stops returned by autocomplete by default
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit caps the number of stops returned by autocomplete
+	MaxSuggestLimit = 50
+	// minSuggestQueryLength is the shortest query worth searching for
+	minSuggestQueryLength = 2
+)
+
+// StopService implements business logic for stops
+type StopService struct {
+	stopRepo repository.StopRepository
+}
+
+// NewStopService creates a new stop service
+func NewStopService(stopRepo repository.StopRepository) *StopService {
+	return &StopService{stopRepo: stopRepo}
+}
+
+// Suggest returns stops matching a partially typed stop or city name, best matches first
+func (s *StopService) Suggest(ctx context.Context, query string, limit int) ([]domain.Stop, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minSuggestQueryLength {
+		return nil, domain.NewDomainError("VALIDATION_FAILED", fmt.Sprintf("Query must be at least %d characters", minSuggestQueryLength))
+	}
+
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	return s.stopRepo.Search(ctx, query, limit)
+}
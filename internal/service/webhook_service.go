@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// ErrStaleEvent marks a notification the payment's current state does not match
+// (delivered out of order, or not sent by the gateway at all)
+var ErrStaleEvent = errors.New("event does not match the current state at the gateway")
+
+// WebhookConfig holds parameters for accepting payment notifications
+type WebhookConfig struct {
+	Allowlist      []*net.IPNet // Networks notifications are accepted from (empty = any)
+	TrustedProxies []*net.IPNet // Proxies whose X-Forwarded-For names the client (empty = the connection is the client)
+}
+
+// DefaultWebhookConfig returns default webhook configuration
+// Notifications are accepted from any address: they are checked against the gateway anyway.
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{}
+}
+
+// ParseAllowlist parses a comma-separated list of networks (CIDR) and single addresses
+func ParseAllowlist(list string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// WebhookService applies payment notifications to bookings
+// Only allowed addresses are accepted, and each notification is processed once:
+// gateways retry until they get 200, and a retry must not confirm or fail a booking again.
+// Notifications are never trusted: the payment or refund is fetched from the gateway and
+// checked against the booking.
+type WebhookService struct {
+	events   repository.WebhookEventRepository
+	payments *PaymentService
+	bookings *BookingService
+	config   WebhookConfig
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(events repository.WebhookEventRepository, payments *PaymentService, bookings *BookingService, config WebhookConfig) *WebhookService {
+	return &WebhookService{
+		events:   events,
+		payments: payments,
+		bookings: bookings,
+		config:   config,
+	}
+}
+
+// Allowed checks if a notification may come from the client of a request
+// remoteAddr is the connection's address (host or host:port); forwardedFor the
+// X-Forwarded-For header, only believed when the connection comes from a trusted proxy.
+func (s *WebhookService) Allowed(remoteAddr, forwardedFor string) bool {
+	if len(s.config.Allowlist) == 0 {
+		return true
+	}
+
+	ip := s.clientIP(remoteAddr, forwardedFor)
+	return ip != nil && containsIP(s.config.Allowlist, ip)
+}
+
+// ProcessOnce applies a notification unless it was processed before
+// Returns nil when the notification should be acknowledged, so the gateway stops retrying.
+// A notification that failed or did not match the gateway's state is released, so that
+// a retry or the genuine event is still processed.
+func (s *WebhookService) ProcessOnce(ctx context.Context, event *domain.WebhookEvent, apply func(ctx context.Context) error) error {
+	claimed, err := s.events.Claim(ctx, event)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		fmt.Printf("[%s Webhook] Ignoring duplicate event %s\n", event.Provider, event.ID)
+		return nil
+	}
+
+	err = apply(ctx)
+
+	// Late or duplicate events (e.g. a payment succeeding after a refund) are acknowledged but not applied
+	var transitionErr *domain.TransitionError
+	if errors.As(err, &transitionErr) {
+		fmt.Printf("[%s Webhook] Ignoring event %s: %v\n", event.Provider, event.ID, err)
+		return nil
+	}
+
+	if errors.Is(err, ErrStaleEvent) {
+		fmt.Printf("[%s Webhook] Ignoring event %s: %v\n", event.Provider, event.ID, err)
+		s.release(ctx, event)
+		return nil
+	}
+
+	if err != nil {
+		fmt.Printf("[%s Webhook] Error processing event %s: %v\n", event.Provider, event.ID, err)
+		s.release(ctx, event)
+		return err
+	}
+	return nil
+}
+
+// RefundEvent identifies a refund notification of a gateway whose refunds have no ID of their own
+// SberPay calls back every refund of an order alike; the order's refunded total at the
+// gateway tells them apart, so a later partial or final refund is not taken for a retry.
+func (s *WebhookService) RefundEvent(ctx context.Context, method domain.PaymentMethod, provider, event, orderID string) (*domain.WebhookEvent, error) {
+	refund, err := s.payments.FindRefund(ctx, method, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify refund: %w", err)
+	}
+	return domain.NewWebhookEvent(provider, event, fmt.Sprintf("%s:%d", orderID, refund.Amount.Amount)), nil
+}
+
+// ApplyPayment moves a booking to the state of its payment at the gateway
+// The payment is fetched from the gateway of the method; notified is the status the
+// notification reported (empty when it reports none), and a payment no longer in it
+// is a stale event. The payment must be the booking's payment, for the booking's amount.
+func (s *WebhookService) ApplyPayment(ctx context.Context, method domain.PaymentMethod, actor, providerPaymentID string, notified domain.PaymentStatus) error {
+	remote, err := s.payments.FindPayment(ctx, method, providerPaymentID)
+	if err != nil {
+		return fmt.Errorf("cannot verify payment: %w", err)
+	}
+	if notified != "" && remote.Status != notified {
+		return ErrStaleEvent
+	}
+	if remote.OrderID == "" {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Payment %s has no order", remote.ID))
+	}
+
+	booking, err := s.bookings.GetBooking(ctx, remote.OrderID)
+	if err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
+	if booking.Payment == nil {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Booking %s has no payment", booking.ID))
+	}
+	if booking.Payment.ProviderPaymentID != "" && booking.Payment.ProviderPaymentID != remote.ID {
+		// Another payment of the order, e.g. the fare difference of a booking change, settled when it was made
+		fmt.Printf("[%s Webhook] Ignoring payment %s: not the payment of booking %s\n", method, remote.ID, booking.ID)
+		return nil
+	}
+	if err := s.payments.VerifyPayment(booking.Payment, remote); err != nil {
+		return err
+	}
+	if booking.Status == domain.BookingFailed {
+		// Paid after the booking failed (e.g. on the gateway's page after it expired): give it back
+		return s.bookings.ReturnLatePayment(ctx, booking, remote.ID, remote.Status)
+	}
+
+	switch remote.Status {
+	case domain.PaymentAuthorized:
+		// Issue tickets and capture the payment; a failed ticket cancels the authorization
+		return s.bookings.AuthorizePayment(ctx, booking, actor, remote.ID)
+	case domain.PaymentCompleted:
+		// Confirm booking (issuing tickets if it was paid first), take the held seats off sale and save
+		return s.bookings.ConfirmPayment(ctx, booking, actor, remote.ID)
+	case domain.PaymentFailed:
+		// Mark booking as failed, cancel provider bookings and give the held seats back
+		return s.bookings.FailPayment(ctx, booking, actor, "Payment canceled by user or provider")
+	default:
+		// Still pending, or refunded (applied by the refund notification)
+		return ErrStaleEvent
+	}
+}
+
+// ApplyRefund marks a booking refunded once all of its payments are refunded in full at the gateway
+func (s *WebhookService) ApplyRefund(ctx context.Context, method domain.PaymentMethod, actor, refundID string) error {
+	refund, err := s.payments.FindRefund(ctx, method, refundID)
+	if err != nil {
+		return fmt.Errorf("cannot verify refund: %w", err)
+	}
+	if !refund.Succeeded {
+		return ErrStaleEvent
+	}
+
+	// Refunds carry no metadata: the order is found through the refunded payment
+	remote, err := s.payments.FindPayment(ctx, method, refund.PaymentID)
+	if err != nil {
+		return fmt.Errorf("cannot verify refunded payment: %w", err)
+	}
+
+	booking, err := s.bookings.GetBooking(ctx, remote.OrderID)
+	if err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
+	if _, ok := booking.FindPayment(refund.PaymentID); !ok {
+		return domain.NewDomainError("PAYMENT_NOT_VERIFIED", fmt.Sprintf("Refund %s is not a refund of booking %s", refund.ID, booking.ID))
+	}
+	if booking.RefundableAmount().IsPositive() {
+		// Partial refund (a cancelled ticket or booking change): the booking keeps its status
+		return nil
+	}
+
+	if err := booking.TransitionTo(domain.BookingRefunded, actor, "Refund succeeded"); err != nil {
+		return err
+	}
+
+	booking.Payment.Status = domain.PaymentRefunded
+
+	return s.bookings.UpdateBooking(ctx, booking)
+}
+
+// clientIP returns the address a request came from
+// Behind trusted proxies it is the last X-Forwarded-For entry they did not add.
+func (s *WebhookService) clientIP(remoteAddr, forwardedFor string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || forwardedFor == "" || !containsIP(s.config.TrustedProxies, ip) {
+		return ip
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip = net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil || !containsIP(s.config.TrustedProxies, ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+// release forgets a notification that was not applied, so that its retry is processed
+func (s *WebhookService) release(ctx context.Context, event *domain.WebhookEvent) {
+	if err := s.events.Release(context.WithoutCancel(ctx), event); err != nil {
+		// In production, this should be logged and monitored
+		fmt.Printf("Warning: failed to release webhook event %s: %v\n", event.ID, err)
+	}
+}
+
+// containsIP checks if any of the networks contains the address
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lenalink/backend/internal/domain"
+	"github.com/lenalink/backend/internal/repository"
+)
+
+// fakeWebhookEventRepo claims events the way the unique key of webhook_events does
+type fakeWebhookEventRepo struct {
+	claimed  map[string]bool
+	released []string
+}
+
+func (r *fakeWebhookEventRepo) Claim(ctx context.Context, event *domain.WebhookEvent) (bool, error) {
+	if r.claimed[event.ID] {
+		return false, nil
+	}
+	r.claimed[event.ID] = true
+	return true, nil
+}
+
+func (r *fakeWebhookEventRepo) Release(ctx context.Context, event *domain.WebhookEvent) error {
+	delete(r.claimed, event.ID)
+	r.released = append(r.released, event.ID)
+	return nil
+}
+
+// fakeWebhookGateway reports payments and refunds as set up by the test
+type fakeWebhookGateway struct {
+	PaymentGateway
+	payments map[string]*GatewayPayment
+	refunds  map[string]*GatewayRefund
+}
+
+func (g *fakeWebhookGateway) FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error) {
+	if payment, ok := g.payments[paymentID]; ok {
+		return payment, nil
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (g *fakeWebhookGateway) FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error) {
+	if refund, ok := g.refunds[refundID]; ok {
+		return refund, nil
+	}
+	return nil, errors.New("refund not found")
+}
+
+type fakeWebhookBookingRepo struct {
+	repository.BookingRepository
+	bookings map[string]*domain.Booking
+	lookups  int
+	updates  int
+}
+
+func (r *fakeWebhookBookingRepo) FindByID(ctx context.Context, id string) (*domain.Booking, error) {
+	r.lookups++
+	if booking, ok := r.bookings[id]; ok {
+		return booking, nil
+	}
+	return nil, domain.ErrBookingNotFound
+}
+
+func (r *fakeWebhookBookingRepo) Update(ctx context.Context, booking *domain.Booking) error {
+	r.updates++
+	return nil
+}
+
+type webhookFixture struct {
+	svc      *WebhookService
+	events   *fakeWebhookEventRepo
+	gateway  *fakeWebhookGateway
+	bookings *fakeWebhookBookingRepo
+}
+
+func newWebhookFixture(config WebhookConfig) *webhookFixture {
+	f := &webhookFixture{
+		events:   &fakeWebhookEventRepo{claimed: make(map[string]bool)},
+		gateway:  &fakeWebhookGateway{payments: make(map[string]*GatewayPayment), refunds: make(map[string]*GatewayRefund)},
+		bookings: &fakeWebhookBookingRepo{bookings: make(map[string]*domain.Booking)},
+	}
+	payments := NewPaymentService(NewGatewayRegistry(f.gateway))
+	bookings := NewBookingService(nil, nil, f.bookings, nil, nil, nil, payments, nil, nil, nil, nil, DefaultBookingConfig())
+	f.svc = NewWebhookService(f.events, payments, bookings, config)
+	return f
+}
+
+// refundedBooking adds a confirmed booking whose payment the gateway has refunded in full
+func (f *webhookFixture) refundedBooking(id string) *domain.Booking {
+	amount := domain.Rubles(5000)
+	booking := &domain.Booking{
+		ID:     id,
+		Status: domain.BookingConfirmed,
+		Payment: &domain.Payment{
+			ID:                "pay-" + id,
+			OrderID:           id,
+			ProviderPaymentID: "gw-" + id,
+			Amount:            amount,
+			RefundedAmount:    amount,
+			Method:            domain.PaymentYooKassa,
+			Status:            domain.PaymentCompleted,
+		},
+	}
+	f.bookings.bookings[id] = booking
+	f.gateway.payments["gw-"+id] = &GatewayPayment{ID: "gw-" + id, OrderID: id, Status: domain.PaymentRefunded, Amount: amount}
+	return booking
+}
+
+func TestWebhookProcessOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(DefaultWebhookConfig())
+	event := domain.NewWebhookEvent("yookassa", "payment.succeeded", "gw-1")
+
+	calls := 0
+	apply := func(ctx context.Context) error {
+		calls++
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.svc.ProcessOnce(ctx, event, apply); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the retried event to be applied once, applied %d times", calls)
+	}
+
+	// A failed event is released so that the gateway's retry is processed
+	failing := domain.NewWebhookEvent("yookassa", "payment.canceled", "gw-2")
+	if err := f.svc.ProcessOnce(ctx, failing, func(ctx context.Context) error { return errors.New("database is down") }); err == nil {
+		t.Fatal("expected the failure to be returned so that the gateway retries")
+	}
+	if f.events.claimed[failing.ID] {
+		t.Fatal("expected the failed event to be released")
+	}
+	calls = 0
+	if err := f.svc.ProcessOnce(ctx, failing, apply); err != nil || calls != 1 {
+		t.Fatalf("expected the retry to be applied, got %v after %d calls", err, calls)
+	}
+
+	// A stale event is acknowledged but released: the genuine one may still follow
+	stale := domain.NewWebhookEvent("yookassa", "payment.succeeded", "gw-3")
+	if err := f.svc.ProcessOnce(ctx, stale, func(ctx context.Context) error { return ErrStaleEvent }); err != nil {
+		t.Fatalf("expected a stale event to be acknowledged, got %v", err)
+	}
+	if f.events.claimed[stale.ID] {
+		t.Fatal("expected the stale event to be released")
+	}
+
+	// An event the booking is past is acknowledged and stays processed
+	late := domain.NewWebhookEvent("yookassa", "payment.succeeded", "gw-4")
+	transition := &domain.TransitionError{BookingID: "b1", From: domain.BookingRefunded, To: domain.BookingConfirmed}
+	if err := f.svc.ProcessOnce(ctx, late, func(ctx context.Context) error { return transition }); err != nil {
+		t.Fatalf("expected a late event to be acknowledged, got %v", err)
+	}
+	if !f.events.claimed[late.ID] {
+		t.Fatal("expected the late event to stay claimed")
+	}
+}
+
+func TestWebhookApplyPaymentChecksGatewayState(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(DefaultWebhookConfig())
+	f.gateway.payments["gw-1"] = &GatewayPayment{ID: "gw-1", OrderID: "b1", Status: domain.PaymentPending, Amount: domain.Rubles(5000)}
+
+	// Notified as succeeded while the gateway still has it pending: forged or out of order
+	err := f.svc.ApplyPayment(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, "gw-1", domain.PaymentCompleted)
+	if !errors.Is(err, ErrStaleEvent) {
+		t.Fatalf("expected a stale event, got %v", err)
+	}
+	if f.bookings.lookups != 0 {
+		t.Fatal("expected the booking to be left alone")
+	}
+
+	// A payment notification arriving after the refund finds the payment refunded
+	f.refundedBooking("b2")
+	err = f.svc.ApplyPayment(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, "gw-b2", "")
+	if !errors.Is(err, ErrStaleEvent) || f.bookings.updates != 0 {
+		t.Fatalf("expected the late payment notification to be stale, got %v", err)
+	}
+}
+
+func TestWebhookApplyRefund(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(DefaultWebhookConfig())
+	booking := f.refundedBooking("b1")
+	f.gateway.refunds["rf-pending"] = &GatewayRefund{ID: "rf-pending", PaymentID: "gw-b1"}
+	f.gateway.refunds["rf-1"] = &GatewayRefund{ID: "rf-1", PaymentID: "gw-b1", Succeeded: true}
+	f.gateway.refunds["rf-2"] = &GatewayRefund{ID: "rf-2", PaymentID: "gw-b1", Succeeded: true}
+
+	if err := f.svc.ApplyRefund(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, "rf-pending"); !errors.Is(err, ErrStaleEvent) {
+		t.Fatalf("expected a refund that has not succeeded to be stale, got %v", err)
+	}
+
+	refund := func(refundID string) error {
+		event := domain.NewWebhookEvent("yookassa", "refund.succeeded", refundID)
+		return f.svc.ProcessOnce(ctx, event, func(ctx context.Context) error {
+			return f.svc.ApplyRefund(ctx, domain.PaymentYooKassa, domain.ActorYooKassa, refundID)
+		})
+	}
+	if err := refund("rf-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if booking.Status != domain.BookingRefunded || booking.Payment.Status != domain.PaymentRefunded || f.bookings.updates != 1 {
+		t.Fatalf("expected the booking to be refunded, got %s", booking.Status)
+	}
+
+	// Retried, or another refund of the same payment arriving late: acknowledged, not applied again
+	if err := refund("rf-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := refund("rf-2"); err != nil {
+		t.Fatalf("expected the late refund to be acknowledged, got %v", err)
+	}
+	if f.bookings.updates != 1 || len(booking.Transitions) != 1 {
+		t.Fatalf("expected the booking to be refunded once, updated %d times", f.bookings.updates)
+	}
+}
+
+func TestWebhookRefundEventPerRefund(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(DefaultWebhookConfig())
+	f.gateway.refunds["sber-1"] = &GatewayRefund{ID: "sber-1", PaymentID: "sber-1", Succeeded: true, Amount: domain.Rubles(1000)}
+
+	first, err := f.svc.RefundEvent(ctx, domain.PaymentSberPay, "sberpay", "refunded", "sber-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retry, _ := f.svc.RefundEvent(ctx, domain.PaymentSberPay, "sberpay", "refunded", "sber-1")
+	if retry.ID != first.ID {
+		t.Fatalf("expected a retried callback to be the same event, got %s and %s", first.ID, retry.ID)
+	}
+
+	// A second partial refund of the order is called back with the same parameters
+	f.gateway.refunds["sber-1"].Amount = domain.Rubles(2500)
+	second, _ := f.svc.RefundEvent(ctx, domain.PaymentSberPay, "sberpay", "refunded", "sber-1")
+	if second.ID == first.ID {
+		t.Fatalf("expected the next refund to be a new event, got %s twice", first.ID)
+	}
+}
+
+func TestWebhookAllowed(t *testing.T) {
+	yookassa, _ := ParseAllowlist("185.71.76.0/27")
+	proxies, _ := ParseAllowlist("10.0.0.1")
+
+	open := newWebhookFixture(DefaultWebhookConfig()).svc
+	if !open.Allowed("203.0.113.5:443", "") {
+		t.Fatal("expected notifications from any address without an allowlist")
+	}
+
+	direct := newWebhookFixture(WebhookConfig{Allowlist: yookassa}).svc
+	if !direct.Allowed("185.71.76.10:443", "") || direct.Allowed("203.0.113.5:443", "") {
+		t.Fatal("expected only the allowlisted network to be accepted")
+	}
+	if direct.Allowed("203.0.113.5:443", "185.71.76.10") {
+		t.Fatal("expected X-Forwarded-For to be ignored from an untrusted address")
+	}
+
+	proxied := newWebhookFixture(WebhookConfig{Allowlist: yookassa, TrustedProxies: proxies}).svc
+	if !proxied.Allowed("10.0.0.1:51000", "185.71.76.10") {
+		t.Fatal("expected the client forwarded by the trusted proxy to be accepted")
+	}
+	// A client cannot prepend an allowlisted address: the proxy appends the one it saw
+	if proxied.Allowed("10.0.0.1:51000", "185.71.76.10, 203.0.113.5") {
+		t.Fatal("expected the address seen by the proxy to be checked")
+	}
+	if proxied.Allowed("10.0.0.1:51000", "") {
+		t.Fatal("expected the proxy itself not to be allowed")
+	}
+}
@@ -1,11 +1,17 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rvinnie/yookassa-sdk-go/yookassa"
 	yoocommon "github.com/rvinnie/yookassa-sdk-go/yookassa/common"
+	yooerror "github.com/rvinnie/yookassa-sdk-go/yookassa/errors"
 	yoopayment "github.com/rvinnie/yookassa-sdk-go/yookassa/payment"
 	yoorefund "github.com/rvinnie/yookassa-sdk-go/yookassa/refund"
 
@@ -13,10 +19,24 @@ import (
 )
 
 // YooKassaGateway implements PaymentGateway for YooKassa
+// Payments and refunds carry their fiscal receipt (54-FZ), which YooKassa registers
+// with the online cash register.
 type YooKassaGateway struct {
 	paymentHandler *yookassa.PaymentHandler
 	refundHandler  *yookassa.RefundHandler
 	returnURL      string
+
+	// Refunds with a receipt are created directly: the SDK's refund has no receipt
+	baseURL   string
+	shopID    string
+	secretKey string
+	client    *http.Client
+}
+
+// yooKassaRefundRequest is a refund with its receipt
+type yooKassaRefundRequest struct {
+	yoorefund.Refund
+	Receipt *yoopayment.Receipt `json:"receipt,omitempty"`
 }
 
 // NewYooKassaGateway creates a new YooKassa payment gateway
@@ -26,6 +46,10 @@ func NewYooKassaGateway(shopID, secretKey, returnURL string) *YooKassaGateway {
 		paymentHandler: yookassa.NewPaymentHandler(client),
 		refundHandler:  yookassa.NewRefundHandler(client),
 		returnURL:      returnURL,
+		baseURL:        yookassa.BaseURL,
+		shopID:         shopID,
+		secretKey:      secretKey,
+		client:         &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -33,10 +57,7 @@ func NewYooKassaGateway(shopID, secretKey, returnURL string) *YooKassaGateway {
 func (g *YooKassaGateway) ProcessPayment(ctx context.Context, payment *domain.Payment) error {
 	// Build payment request
 	paymentRequest := &yoopayment.Payment{
-		Amount: &yoocommon.Amount{
-			Value:    fmt.Sprintf("%.2f", payment.Amount),
-			Currency: payment.Currency,
-		},
+		Amount: yooKassaAmount(payment.Amount),
 		Confirmation: &yoopayment.Redirect{
 			Type:      yoopayment.TypeRedirect,
 			ReturnURL: g.returnURL,
@@ -46,7 +67,8 @@ func (g *YooKassaGateway) ProcessPayment(ctx context.Context, payment *domain.Pa
 			"order_id":   payment.OrderID,
 			"payment_id": payment.ID,
 		},
-		Capture: true, // Auto-capture payment
+		Capture: !payment.TwoStage, // Two-stage payments are captured after the tickets are issued
+		Receipt: yooKassaReceipt(payment.Receipt),
 	}
 
 	// Create payment in YooKassa
@@ -71,25 +93,83 @@ func (g *YooKassaGateway) ProcessPayment(ctx context.Context, payment *domain.Pa
 	return nil
 }
 
-// RefundPayment creates a refund in YooKassa
-func (g *YooKassaGateway) RefundPayment(ctx context.Context, paymentID string, amount float64) error {
-	refundRequest := &yoorefund.Refund{
-		PaymentId: paymentID,
-		Amount: &yoocommon.Amount{
-			Value:    fmt.Sprintf("%.2f", amount),
-			Currency: "RUB",
-		},
-		Description: "Возврат средств за отмену бронирования",
+// RedirectsCustomer marks the gateway as redirect-only: YooKassa payments are confirmed on its page
+func (g *YooKassaGateway) RedirectsCustomer() {}
+
+// CapturePayment captures the funds held by a two-stage payment in YooKassa
+func (g *YooKassaGateway) CapturePayment(ctx context.Context, paymentID string, amount domain.Money) error {
+	captureRequest := &yoopayment.Payment{
+		ID:     paymentID,
+		Amount: yooKassaAmount(amount),
+	}
+
+	_, err := g.paymentHandler.CapturePayment(captureRequest)
+	if err != nil {
+		return fmt.Errorf("yookassa capture payment failed: %w", err)
 	}
 
-	_, err := g.refundHandler.CreateRefund(refundRequest)
+	return nil
+}
+
+// CancelPayment cancels a two-stage payment in YooKassa, releasing the held funds
+func (g *YooKassaGateway) CancelPayment(ctx context.Context, paymentID string) error {
+	_, err := g.paymentHandler.CancelPayment(paymentID)
 	if err != nil {
+		return fmt.Errorf("yookassa cancel payment failed: %w", err)
+	}
+
+	return nil
+}
+
+// RefundPayment creates a refund in YooKassa with its refund receipt
+func (g *YooKassaGateway) RefundPayment(ctx context.Context, paymentID string, amount domain.Money, receipt *domain.Receipt) error {
+	refundRequest := &yooKassaRefundRequest{
+		Refund: yoorefund.Refund{
+			PaymentId:   paymentID,
+			Amount:      yooKassaAmount(amount),
+			Description: "Возврат средств за отмену бронирования",
+		},
+		Receipt: yooKassaReceipt(receipt),
+	}
+
+	if err := g.createRefund(ctx, refundRequest); err != nil {
 		return fmt.Errorf("yookassa refund failed: %w", err)
 	}
 
 	return nil
 }
 
+// createRefund posts a refund to the YooKassa API
+func (g *YooKassaGateway) createRefund(ctx context.Context, refund *yooKassaRefundRequest) error {
+	body, err := json.Marshal(refund)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+yookassa.RefundEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.SetBasicAuth(g.shopID, g.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotence-Key", uuid.NewString())
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		yooErr, err := yooerror.GetError(resp.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return yooErr
+	}
+	return nil
+}
+
 // GetPaymentStatus retrieves payment status from YooKassa
 func (g *YooKassaGateway) GetPaymentStatus(ctx context.Context, paymentID string) (domain.PaymentStatus, error) {
 	resp, err := g.paymentHandler.FindPayment(paymentID)
@@ -97,17 +177,109 @@ func (g *YooKassaGateway) GetPaymentStatus(ctx context.Context, paymentID string
 		return "", fmt.Errorf("yookassa get payment failed: %w", err)
 	}
 
-	// Map YooKassa status to domain status
-	switch resp.Status {
+	return paymentStatus(resp.Status), nil
+}
+
+// FindPayment retrieves a payment from YooKassa
+func (g *YooKassaGateway) FindPayment(ctx context.Context, paymentID string) (*GatewayPayment, error) {
+	resp, err := g.paymentHandler.FindPayment(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("yookassa get payment failed: %w", err)
+	}
+
+	payment := &GatewayPayment{
+		ID:     resp.ID,
+		Status: paymentStatus(resp.Status),
+	}
+	if metadata, ok := resp.Metadata.(map[string]interface{}); ok {
+		payment.OrderID, _ = metadata["order_id"].(string)
+	}
+	if resp.Amount != nil {
+		if payment.Amount, err = parseYooKassaAmount(resp.Amount); err != nil {
+			return nil, fmt.Errorf("yookassa payment %s has invalid amount: %w", resp.ID, err)
+		}
+	}
+
+	return payment, nil
+}
+
+// FindRefund retrieves a refund from YooKassa
+func (g *YooKassaGateway) FindRefund(ctx context.Context, refundID string) (*GatewayRefund, error) {
+	resp, err := g.refundHandler.FindRefund(refundID)
+	if err != nil {
+		return nil, fmt.Errorf("yookassa get refund failed: %w", err)
+	}
+
+	refund := &GatewayRefund{
+		ID:        resp.Id,
+		PaymentID: resp.PaymentId,
+		Succeeded: resp.Status == yoorefund.Succeeded,
+	}
+	if resp.Amount != nil {
+		if refund.Amount, err = parseYooKassaAmount(resp.Amount); err != nil {
+			return nil, fmt.Errorf("yookassa refund %s has invalid amount: %w", resp.Id, err)
+		}
+	}
+
+	return refund, nil
+}
+
+// yooKassaAmount converts an amount to YooKassa's decimal string and currency code
+func yooKassaAmount(amount domain.Money) *yoocommon.Amount {
+	return &yoocommon.Amount{
+		Value:    amount.Decimal(),
+		Currency: string(amount.Currency),
+	}
+}
+
+// yooKassaReceipt converts a receipt to YooKassa's; every item is one unit paid in full
+func yooKassaReceipt(receipt *domain.Receipt) *yoopayment.Receipt {
+	if receipt == nil {
+		return nil
+	}
+
+	items := make([]*yoocommon.Item, len(receipt.Items))
+	for i, item := range receipt.Items {
+		items[i] = &yoocommon.Item{
+			Description:    item.Description,
+			Quantity:       "1",
+			Amount:         yooKassaAmount(item.Amount),
+			VatCode:        int16(item.VAT),
+			PaymentMode:    "full_payment",
+			PaymentSubject: string(item.Subject),
+		}
+	}
+
+	return &yoopayment.Receipt{
+		Customer: &yoocommon.Customer{
+			Email: receipt.Customer.Email,
+			Phone: receipt.Customer.Phone,
+		},
+		Items: items,
+	}
+}
+
+// parseYooKassaAmount reads an amount reported by YooKassa
+func parseYooKassaAmount(amount *yoocommon.Amount) (domain.Money, error) {
+	currency, err := domain.ParseCurrency(amount.Currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	return domain.ParseMoney(amount.Value, currency)
+}
+
+// paymentStatus maps a YooKassa payment status to the domain status
+func paymentStatus(status yoopayment.Status) domain.PaymentStatus {
+	switch status {
 	case yoopayment.Pending:
-		return domain.PaymentPending, nil
+		return domain.PaymentPending
 	case yoopayment.WaitingForCapture:
-		return domain.PaymentPending, nil
+		return domain.PaymentAuthorized
 	case yoopayment.Succeeded:
-		return domain.PaymentCompleted, nil
+		return domain.PaymentCompleted
 	case yoopayment.Canceled:
-		return domain.PaymentFailed, nil
+		return domain.PaymentFailed
 	default:
-		return domain.PaymentPending, nil
+		return domain.PaymentPending
 	}
 }
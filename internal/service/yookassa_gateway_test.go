@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+func TestYooKassaRefundReceipt(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "shop" || password != "secret" || r.URL.Path != "/refunds" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"type": "error", "code": "invalid_credentials"})
+			return
+		}
+		if r.Header.Get("Idempotence-Key") == "" {
+			t.Error("expected an idempotence key")
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(map[string]string{"id": "refund-1", "status": "succeeded"})
+	}))
+	defer server.Close()
+
+	gateway := NewYooKassaGateway("shop", "secret", "")
+	gateway.baseURL = server.URL + "/"
+
+	booking := &domain.Booking{
+		Passenger:        domain.Passenger{Email: "ivan@example.com", Phone: "8 (914) 123-45-67"},
+		IncludeInsurance: true,
+		InsurancePremium: domain.Rubles(150),
+	}
+	booking.AddSegment(domain.BookedSegment{
+		ID:            "s1",
+		TransportType: domain.TransportBus,
+		From:          domain.Stop{City: "Якутск"},
+		To:            domain.Stop{City: "Нерюнгри"},
+		DepartureTime: time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC),
+		Price:         domain.Rubles(3000),
+		Commission:    domain.Rubles(210),
+		TotalPrice:    domain.Rubles(3210),
+	})
+
+	receipts := NewReceiptService(DefaultReceiptConfig())
+	receipt := receipts.BookingReceipt(booking)
+	if receipt.Total() != booking.GrandTotal || len(receipt.Items) != 3 || receipt.Customer.Phone != "79141234567" {
+		t.Fatalf("expected ticket, commission and insurance for %v: %+v", booking.GrandTotal, receipt)
+	}
+
+	// The refund receipt lists what is refunded: the ticket less its 500 RUB penalty, the commission and the insurance
+	refund := domain.SegmentRefund{BookedSegmentID: "s1", CommissionRefunded: domain.Rubles(210), Amount: domain.Rubles(2710)}
+	refundReceipt := receipts.RefundReceipt(booking, []domain.SegmentRefund{refund}, true)
+	if refundReceipt.Total() != domain.Rubles(2860) || refundReceipt.Items[0].Amount != domain.Rubles(2500) {
+		t.Fatalf("unexpected refund receipt: %+v", refundReceipt)
+	}
+
+	if err := gateway.RefundPayment(context.Background(), "yk-1", refundReceipt.Total(), refundReceipt); err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+
+	sent, _ := request["receipt"].(map[string]interface{})
+	items, _ := sent["items"].([]interface{})
+	if request["payment_id"] != "yk-1" || len(items) != 3 {
+		t.Fatalf("expected the refund with its receipt, got %v", request)
+	}
+	commission := items[1].(map[string]interface{})
+	if commission["payment_subject"] != "agent_commission" || commission["vat_code"] != float64(domain.VAT20) ||
+		commission["amount"].(map[string]interface{})["value"] != "210.00" {
+		t.Fatalf("unexpected commission item: %v", commission)
+	}
+
+	rejected := NewYooKassaGateway("shop", "wrong", "")
+	rejected.baseURL = server.URL + "/"
+	if err := rejected.RefundPayment(context.Background(), "yk-1", booking.GrandTotal, nil); err == nil {
+		t.Fatal("expected a rejected refund to fail")
+	}
+}
@@ -74,12 +74,14 @@ func GenerateGeoJSON(path *routing.Path) *GeoJSONFeatureCollection {
 // GetSegmentColor returns color for transport type
 func GetSegmentColor(transportType string) string {
 	colors := map[string]string{
-		"air":   "#FF6B6B",
-		"rail":  "#4ECDC4",
-		"bus":   "#45B7D1",
-		"river": "#96CEB4",
-		"walk":  "#FFEAA7",
-		"taxi":  "#DFE6E9",
+		"air":      "#FF6B6B",
+		"rail":     "#4ECDC4",
+		"bus":      "#45B7D1",
+		"river":    "#96CEB4",
+		"ferry":    "#88D8B0",
+		"ice_road": "#A0C4FF",
+		"walk":     "#FFEAA7",
+		"taxi":     "#DFE6E9",
 	}
 	if color, exists := colors[transportType]; exists {
 		return color
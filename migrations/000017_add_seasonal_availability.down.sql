@@ -0,0 +1,13 @@
+-- Remove seasonal availability
+-- WARNING: This will delete segments using ferry or ice_road transport
+
+ALTER TABLE stops DROP COLUMN IF EXISTS season;
+ALTER TABLE segments DROP COLUMN IF EXISTS season;
+
+DELETE FROM segments WHERE transport_type IN ('ferry', 'ice_road');
+
+ALTER TABLE segments DROP CONSTRAINT IF EXISTS ck_transport_type;
+ALTER TABLE segments
+ADD CONSTRAINT ck_transport_type CHECK (
+    transport_type IN ('air', 'rail', 'bus', 'river', 'taxi', 'walk')
+);
@@ -0,0 +1,18 @@
+-- Add seasonal availability to stops and segments
+-- Yakutia transport depends on seasons: winter roads (zimniks), ice crossings of the Lena
+-- and the summer river navigation window
+
+-- Allow the new transport types
+ALTER TABLE segments DROP CONSTRAINT IF EXISTS ck_transport_type;
+ALTER TABLE segments
+ADD CONSTRAINT ck_transport_type CHECK (
+    transport_type IN ('air', 'rail', 'bus', 'river', 'ferry', 'ice_road', 'taxi', 'walk')
+);
+
+-- Seasonal validity calendars: JSON array of {"from": "MM-DD", "to": "MM-DD"} windows
+-- NULL means all year (segments fall back to the default season of their transport type)
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS season JSONB DEFAULT NULL;
+ALTER TABLE stops ADD COLUMN IF NOT EXISTS season JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN segments.season IS 'Yearly validity windows [{"from":"MM-DD","to":"MM-DD"}]. NULL = default for transport type.';
+COMMENT ON COLUMN stops.season IS 'Yearly windows when the stop is served (e.g. ice crossing landings). NULL = all year.';
@@ -0,0 +1,5 @@
+-- Remove trigram indexes for stop autocomplete
+-- The pg_trgm extension is left installed as other objects may depend on it
+
+DROP INDEX IF EXISTS idx_stops_city_trgm;
+DROP INDEX IF EXISTS idx_stops_name_trgm;
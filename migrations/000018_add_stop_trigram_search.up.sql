@@ -0,0 +1,10 @@
+-- Trigram indexes for stop autocomplete
+-- Used as a fallback when full-text search finds nothing (typos, partially typed names)
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_stops_name_trgm ON stops USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_stops_city_trgm ON stops USING GIN (city gin_trgm_ops);
+
+COMMENT ON INDEX idx_stops_name_trgm IS 'Trigram index for fuzzy/prefix stop name search';
+COMMENT ON INDEX idx_stops_city_trgm IS 'Trigram index for fuzzy/prefix city search';
@@ -0,0 +1,7 @@
+-- Remove city normalization
+
+DROP INDEX IF EXISTS idx_stops_city_id;
+ALTER TABLE stops DROP COLUMN IF EXISTS city_id;
+
+DROP TABLE IF EXISTS city_aliases;
+DROP TABLE IF EXISTS cities;
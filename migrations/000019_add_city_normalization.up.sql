@@ -0,0 +1,83 @@
+-- City normalization
+-- Providers spell the same city differently ("Yakutsk", "Якутск", "YKS", RZD station codes).
+-- Stops and search criteria resolve to a canonical city ID.
+
+CREATE TABLE IF NOT EXISTS cities (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    name_en VARCHAR(255) DEFAULT NULL,
+    iata_code VARCHAR(3) DEFAULT NULL,
+    rzd_code VARCHAR(16) DEFAULT NULL,
+    country_code VARCHAR(2) DEFAULT NULL,
+    latitude DECIMAL(10, 7) DEFAULT NULL,
+    longitude DECIMAL(10, 7) DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cities_iata_code ON cities(iata_code) WHERE iata_code IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cities_rzd_code ON cities(rzd_code);
+
+-- Alternative spellings of a city (transliterations, old names, provider-specific names)
+CREATE TABLE IF NOT EXISTS city_aliases (
+    city_id VARCHAR(64) NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL,
+    source VARCHAR(32) NOT NULL DEFAULT 'manual',
+    PRIMARY KEY (city_id, alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_city_aliases_alias ON city_aliases(LOWER(alias));
+
+ALTER TABLE stops ADD COLUMN IF NOT EXISTS city_id VARCHAR(64) DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_stops_city_id ON stops(city_id);
+
+-- Cities served by the seed data
+INSERT INTO cities (id, name, name_en, iata_code, rzd_code, country_code, latitude, longitude) VALUES
+('moscow', 'Москва', 'Moscow', 'MOW', '2000000', 'RU', 55.7558, 37.6173),
+('yakutsk', 'Якутск', 'Yakutsk', 'YKS', NULL, 'RU', 62.0355, 129.6755),
+('mirny', 'Мирный', 'Mirny', 'MJZ', NULL, 'RU', 62.5353, 113.9611),
+('udachny', 'Удачный', 'Udachny', 'PYJ', NULL, 'RU', 66.4167, 112.4000),
+('neryungri', 'Нерюнгри', 'Neryungri', 'NER', NULL, 'RU', 56.6583, 124.7128),
+('aldan', 'Алдан', 'Aldan', 'ADH', NULL, 'RU', 58.6031, 125.3894),
+('lensk', 'Ленск', 'Lensk', 'ULK', NULL, 'RU', 60.7253, 114.9278),
+('olekminsk', 'Олёкминск', 'Olekminsk', 'OLZ', NULL, 'RU', 60.3744, 120.4264),
+('sangar', 'Сангар', 'Sangar', NULL, NULL, 'RU', 63.9242, 127.4739),
+('pokrovsk', 'Покровск', 'Pokrovsk', NULL, NULL, 'RU', 61.4844, 129.1481),
+('batagay', 'Батагай', 'Batagay', 'BQJ', NULL, 'RU', 67.6561, 134.6353),
+('tiksi', 'Тикси', 'Tiksi', 'IKS', NULL, 'RU', 71.6372, 128.8647),
+('verkhoyansk', 'Верхоянск', 'Verkhoyansk', 'VHV', NULL, 'RU', 67.5447, 133.3850),
+('oymyakon', 'Оймякон', 'Oymyakon', NULL, NULL, 'RU', 63.4608, 142.7858),
+('vilyuysk', 'Вилюйск', 'Vilyuysk', 'VYI', NULL, 'RU', 63.7553, 121.6247),
+('nyurba', 'Нюрба', 'Nyurba', 'NYR', NULL, 'RU', 63.2831, 118.3319),
+('zhatay', 'Жатай', 'Zhatay', NULL, NULL, 'RU', 62.1667, 129.8167),
+('tynda', 'Тында', 'Tynda', 'TYD', NULL, 'RU', 55.1547, 124.7467),
+('tommot', 'Томмот', 'Tommot', NULL, NULL, 'RU', 58.9586, 126.2878)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO city_aliases (city_id, alias, source) VALUES
+('moscow', 'Moskva', 'manual'),
+('yakutsk', 'Jakutsk', 'manual'),
+('yakutsk', 'Дьокуускай', 'manual'),
+('lensk', 'Lensky', 'manual'),
+('sangar', 'Sangur', 'manual'),
+('zhatay', 'Zhataay', 'manual'),
+('zhatay', 'Жатаай', 'manual'),
+('neryungri', 'Nerungri', 'manual')
+ON CONFLICT DO NOTHING;
+
+-- Link existing stops to their cities
+UPDATE stops s
+SET city_id = c.id
+FROM cities c
+WHERE s.city_id IS NULL
+  AND LOWER(s.city) IN (LOWER(c.name), LOWER(c.name_en), LOWER(c.iata_code));
+
+UPDATE stops s
+SET city_id = a.city_id
+FROM city_aliases a
+WHERE s.city_id IS NULL
+  AND LOWER(s.city) = LOWER(a.alias);
+
+COMMENT ON TABLE cities IS 'Canonical cities that stops and route searches resolve to';
+COMMENT ON TABLE city_aliases IS 'Alternative spellings of city names (transliterations, provider names)';
+COMMENT ON COLUMN stops.city_id IS 'Canonical city (cities.id); NULL if the city name could not be resolved';
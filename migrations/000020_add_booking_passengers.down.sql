@@ -0,0 +1,12 @@
+-- Remove multi-passenger bookings
+-- WARNING: Tickets of additional passengers are deleted; the lead passenger stays on bookings
+
+DELETE FROM booked_segments bs
+USING booking_passengers bp
+WHERE bs.passenger_id = bp.id AND bp.sequence_order > 1;
+
+DROP INDEX IF EXISTS idx_booked_segments_passenger;
+ALTER TABLE booked_segments DROP CONSTRAINT IF EXISTS fk_booked_segments_passenger;
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS passenger_id;
+
+DROP TABLE IF EXISTS booking_passengers;
@@ -0,0 +1,47 @@
+-- Multi-passenger bookings
+-- A booking covers several passengers; each gets a ticket (booked segment) per leg.
+-- The passenger_* columns on bookings keep the lead passenger (contact details, payer).
+
+CREATE TABLE IF NOT EXISTS booking_passengers (
+    id VARCHAR(36) PRIMARY KEY,
+    booking_id VARCHAR(36) NOT NULL,
+    passenger_type VARCHAR(10) NOT NULL DEFAULT 'adult',
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    middle_name VARCHAR(255),
+    date_of_birth DATE NOT NULL,
+    passport_number VARCHAR(50) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    sequence_order INTEGER NOT NULL,
+
+    CONSTRAINT fk_booking_passengers_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT ck_booking_passenger_type CHECK (passenger_type IN ('adult', 'child', 'infant')),
+    CONSTRAINT ck_booking_passenger_sequence_positive CHECK (sequence_order >= 1),
+    CONSTRAINT ck_booking_passenger_name_not_empty CHECK (
+        LENGTH(TRIM(first_name)) > 0 AND LENGTH(TRIM(last_name)) > 0
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_passengers_booking ON booking_passengers(booking_id, sequence_order);
+
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS passenger_id VARCHAR(36);
+ALTER TABLE booked_segments
+ADD CONSTRAINT fk_booked_segments_passenger FOREIGN KEY (passenger_id) REFERENCES booking_passengers(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_booked_segments_passenger ON booked_segments(passenger_id);
+
+-- Existing bookings have a single passenger; reuse the booking ID as the passenger ID
+INSERT INTO booking_passengers (
+    id, booking_id, passenger_type, first_name, last_name, middle_name,
+    date_of_birth, passport_number, email, phone, sequence_order
+)
+SELECT id, id, 'adult', passenger_first_name, passenger_last_name, passenger_middle_name,
+       passenger_date_of_birth, passenger_passport_number, passenger_email, passenger_phone, 1
+FROM bookings
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE booked_segments SET passenger_id = booking_id WHERE passenger_id IS NULL;
+
+COMMENT ON TABLE booking_passengers IS 'Passengers travelling on a booking, lead passenger first';
+COMMENT ON COLUMN booking_passengers.passenger_type IS 'Fare category by age on departure: adult (12+), child (2-11), infant (<2)';
+COMMENT ON COLUMN booked_segments.passenger_id IS 'Passenger the ticket is issued to';
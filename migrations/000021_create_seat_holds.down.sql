@@ -0,0 +1,5 @@
+-- Remove seat holds
+
+DROP INDEX IF EXISTS idx_seat_holds_booking;
+DROP INDEX IF EXISTS idx_seat_holds_active;
+DROP TABLE IF EXISTS seat_holds;
@@ -0,0 +1,25 @@
+-- Seat holds
+-- A booking holds seats on each segment before booking with providers.
+-- Free seats = segments.seat_count - seats in active, unexpired holds.
+-- Confirmed holds have already been taken off segments.seat_count.
+
+CREATE TABLE IF NOT EXISTS seat_holds (
+    id VARCHAR(36) PRIMARY KEY,
+    segment_id VARCHAR(36) NOT NULL,
+    booking_id VARCHAR(36) NOT NULL,
+    seats INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_seat_holds_segment FOREIGN KEY (segment_id) REFERENCES segments(id) ON DELETE CASCADE,
+    CONSTRAINT ck_seat_hold_status CHECK (status IN ('active', 'confirmed', 'released', 'expired')),
+    CONSTRAINT ck_seat_hold_seats_positive CHECK (seats > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seat_holds_active ON seat_holds(segment_id, expires_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_seat_holds_booking ON seat_holds(booking_id);
+
+COMMENT ON TABLE seat_holds IS 'Time-limited seat reservations made while a booking is in progress';
+COMMENT ON COLUMN seat_holds.booking_id IS 'Booking the seats are held for (the booking row may not exist yet)';
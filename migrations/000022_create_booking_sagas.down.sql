@@ -0,0 +1,6 @@
+-- Remove booking sagas
+
+DROP INDEX IF EXISTS idx_booking_sagas_booking;
+DROP INDEX IF EXISTS idx_booking_sagas_unfinished;
+DROP TABLE IF EXISTS booking_saga_steps;
+DROP TABLE IF EXISTS booking_sagas;
@@ -0,0 +1,48 @@
+-- Booking sagas
+-- Each multi-segment booking records its steps (provider bookings, payment) before running them,
+-- so that a booking interrupted by a failure or crash can be compensated later.
+
+CREATE TABLE IF NOT EXISTS booking_sagas (
+    id VARCHAR(36) PRIMARY KEY,
+    booking_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT ck_booking_saga_status CHECK (
+        status IN ('running', 'completed', 'compensating', 'compensated', 'failed')
+    )
+);
+
+CREATE TABLE IF NOT EXISTS booking_saga_steps (
+    id VARCHAR(36) PRIMARY KEY,
+    saga_id VARCHAR(36) NOT NULL,
+    sequence_order INTEGER NOT NULL,
+    step_type VARCHAR(30) NOT NULL,
+    segment_id VARCHAR(36),
+    passenger_id VARCHAR(36),
+    status VARCHAR(30) NOT NULL DEFAULT 'pending',
+    reference VARCHAR(255),
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_booking_saga_steps_saga FOREIGN KEY (saga_id) REFERENCES booking_sagas(id) ON DELETE CASCADE,
+    CONSTRAINT uq_booking_saga_steps_sequence UNIQUE (saga_id, sequence_order),
+    CONSTRAINT ck_booking_saga_step_type CHECK (step_type IN ('book_segment', 'process_payment')),
+    CONSTRAINT ck_booking_saga_step_status CHECK (
+        status IN ('pending', 'done', 'failed', 'compensated', 'compensation_failed')
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_sagas_unfinished ON booking_sagas(status, updated_at)
+    WHERE status IN ('running', 'compensating', 'failed');
+CREATE INDEX IF NOT EXISTS idx_booking_sagas_booking ON booking_sagas(booking_id);
+
+COMMENT ON TABLE booking_sagas IS 'Durable record of multi-segment booking attempts';
+COMMENT ON COLUMN booking_sagas.status IS 'failed = compensation gave up, needs manual attention';
+COMMENT ON COLUMN booking_saga_steps.reference IS 'Provider booking ref or payment ID used to compensate the step';
+COMMENT ON COLUMN booking_saga_steps.next_attempt_at IS 'When a failed compensation is retried (exponential backoff)';
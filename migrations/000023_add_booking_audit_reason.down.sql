@@ -0,0 +1,8 @@
+-- Restore trigger-based booking status audit
+
+CREATE TRIGGER trigger_audit_booking_status
+    AFTER UPDATE ON bookings
+    FOR EACH ROW
+    EXECUTE FUNCTION audit_booking_status_change();
+
+ALTER TABLE booking_status_audit DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,10 @@
+-- Booking status audit with actor and reason
+-- Status changes now go through the booking state machine, which writes the audit
+-- row itself (changed_by = actor). The trigger could not know who made the change.
+
+ALTER TABLE booking_status_audit ADD COLUMN IF NOT EXISTS reason TEXT;
+
+DROP TRIGGER IF EXISTS trigger_audit_booking_status ON bookings;
+
+COMMENT ON COLUMN booking_status_audit.changed_by IS 'Actor: system, customer, webhook:<provider>, ...';
+COMMENT ON COLUMN booking_status_audit.reason IS 'Why the status changed (cancellation reason, failure cause, ...)';
@@ -0,0 +1,16 @@
+-- Remove partial cancellation
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS ck_payment_refund_within_amount;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
+
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS refund_amount;
+
+UPDATE bookings SET status = 'confirmed' WHERE status = 'partially_cancelled';
+
+ALTER TABLE bookings
+    DROP CONSTRAINT IF EXISTS ck_booking_status;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT ck_booking_status CHECK (
+        status IN ('pending', 'pending_payment', 'confirmed', 'failed', 'cancelled', 'refunded')
+    );
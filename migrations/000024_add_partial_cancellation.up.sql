@@ -0,0 +1,22 @@
+-- Partial cancellation
+-- Single tickets of a confirmed booking can be cancelled and refunded; the booking
+-- stays partially_cancelled until its last ticket is cancelled.
+
+ALTER TABLE bookings
+    DROP CONSTRAINT IF EXISTS ck_booking_status;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT ck_booking_status CHECK (
+        status IN ('pending', 'pending_payment', 'confirmed', 'partially_cancelled', 'failed', 'cancelled', 'refunded')
+    );
+
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS refund_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+    ADD CONSTRAINT ck_payment_refund_within_amount CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+COMMENT ON COLUMN booked_segments.refund_amount IS 'Returned to the customer when the ticket was cancelled (fare minus carrier penalty)';
+COMMENT ON COLUMN payments.refunded_amount IS 'Sum of partial and full refunds issued for the payment';
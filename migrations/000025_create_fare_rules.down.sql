@@ -0,0 +1,7 @@
+-- Drop fare rules
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS tariff;
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS source;
+ALTER TABLE segments DROP COLUMN IF EXISTS tariff;
+ALTER TABLE segments DROP COLUMN IF EXISTS source;
+
+DROP TABLE IF EXISTS fare_rules;
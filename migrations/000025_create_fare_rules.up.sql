@@ -0,0 +1,50 @@
+-- Fare rules
+-- Refund and change conditions per provider tariff. Rows with an empty tariff apply
+-- to every tariff of the provider; segments without a matching rule use the built-in
+-- default for their transport type. GARS rules are synced from its fees catalog,
+-- Aviasales and RZD rules are maintained here (source = 'config').
+--
+-- tiers: [{"before_hours": 24, "penalty": 0.25, "fee": 0}, ...]
+-- The first tier whose before_hours the cancellation is made ahead of departure applies;
+-- penalty is the share of the fare kept by the carrier, fee a fixed amount on top.
+-- Cancelling later than every tier returns nothing.
+
+CREATE TABLE IF NOT EXISTS fare_rules (
+    id VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    tariff VARCHAR(100) NOT NULL DEFAULT '',
+    refundable BOOLEAN NOT NULL DEFAULT TRUE,
+    tiers JSONB NOT NULL DEFAULT '[]',
+    changeable BOOLEAN NOT NULL DEFAULT TRUE,
+    change_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    source VARCHAR(20) NOT NULL DEFAULT 'config',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_fare_rules_provider_tariff UNIQUE (provider, tariff),
+    CONSTRAINT ck_fare_rule_change_fee_positive CHECK (change_fee >= 0)
+);
+
+-- Provider and tariff of synced segments select their fare rule
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS tariff VARCHAR(100);
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS tariff VARCHAR(100);
+
+INSERT INTO fare_rules (id, provider, tariff, refundable, tiers, changeable, change_fee) VALUES
+('aviasales-economy', 'aviasales', 'economy', TRUE,
+    '[{"before_hours": 24, "penalty": 0.25, "fee": 0}, {"before_hours": 0, "penalty": 0.5, "fee": 0}]', TRUE, 3000),
+('aviasales-business', 'aviasales', 'business', TRUE,
+    '[{"before_hours": 0, "penalty": 0.1, "fee": 0}]', TRUE, 0),
+('aviasales-first', 'aviasales', 'first', TRUE,
+    '[{"before_hours": 0, "penalty": 0, "fee": 0}]', TRUE, 0),
+('rzd', 'rzd', '', TRUE,
+    '[{"before_hours": 8, "penalty": 0, "fee": 230}, {"before_hours": 2, "penalty": 0.25, "fee": 230}, {"before_hours": 0, "penalty": 0.5, "fee": 230}]', TRUE, 230),
+('rzd-sv', 'rzd', 'СВ (спальный вагон)', TRUE,
+    '[{"before_hours": 8, "penalty": 0, "fee": 230}, {"before_hours": 2, "penalty": 0.15, "fee": 230}, {"before_hours": 0, "penalty": 0.3, "fee": 230}]', TRUE, 230)
+ON CONFLICT (provider, tariff) DO NOTHING;
+
+COMMENT ON TABLE fare_rules IS 'Refund and change conditions per provider tariff';
+COMMENT ON COLUMN fare_rules.tiers IS 'Cancellation penalty tiers by hours before departure, earliest first';
+COMMENT ON COLUMN fare_rules.source IS 'Where the rule came from: config (maintained by hand) or gars (synced)';
+COMMENT ON COLUMN segments.source IS 'Provider the segment was synced from: gars, aviasales, rzd';
+COMMENT ON COLUMN segments.tariff IS 'Provider tariff or service class (RZD car type, Aviasales trip class)';
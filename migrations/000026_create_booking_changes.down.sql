@@ -0,0 +1,5 @@
+-- Drop booking changes
+DROP TABLE IF EXISTS booking_changes;
+
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS replaced_by;
+ALTER TABLE bookings DROP COLUMN IF EXISTS version;
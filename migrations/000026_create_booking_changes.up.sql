@@ -0,0 +1,34 @@
+-- Booking changes
+-- A ticket can be moved to another segment of the same leg (e.g. a later bus).
+-- The old ticket is cancelled and points at its replacement; every change bumps
+-- the booking version and is recorded here with the money charged or refunded.
+--
+-- tickets: [{"old_booked_segment_id": "...", "new_booked_segment_id": "...",
+--            "fare_difference": 150.00, "change_fee": 0}, ...]
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS replaced_by VARCHAR(36);
+
+CREATE TABLE IF NOT EXISTS booking_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    booking_id VARCHAR(36) NOT NULL,
+    version INTEGER NOT NULL,
+    tickets JSONB NOT NULL DEFAULT '[]',
+    fare_difference DECIMAL(10, 2) NOT NULL,
+    change_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(10, 2) NOT NULL,
+    payment_id VARCHAR(36),
+    actor VARCHAR(100) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_booking_changes_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT uq_booking_changes_version UNIQUE (booking_id, version),
+    CONSTRAINT ck_booking_change_version CHECK (version > 1),
+    CONSTRAINT ck_booking_change_fee_positive CHECK (change_fee >= 0)
+);
+
+COMMENT ON TABLE booking_changes IS 'Ticket changes of bookings; each row created a new booking version';
+COMMENT ON COLUMN booking_changes.amount IS 'Fare difference plus change fees: charged when positive, refunded when negative';
+COMMENT ON COLUMN booking_changes.payment_id IS 'Gateway payment that charged the amount';
+COMMENT ON COLUMN booked_segments.replaced_by IS 'Ticket that replaced this one in a booking change';
@@ -0,0 +1,5 @@
+-- Drop disruptions
+DROP TABLE IF EXISTS disruptions;
+
+ALTER TABLE segments DROP CONSTRAINT IF EXISTS ck_segment_status;
+ALTER TABLE segments DROP COLUMN IF EXISTS status;
@@ -0,0 +1,45 @@
+-- Disruptions
+-- A booked journey broken by a cancelled segment, or by a delay that makes the next
+-- connection shorter than the minimum connection time. The journey is re-routed from
+-- the disrupted point to the passenger's destination; the alternatives found are kept
+-- here, and when one of them runs the same legs the passenger is rebooked onto it.
+-- One row per passenger and disrupted segment, so later scans do not notify twice.
+
+-- Carriers cancel segments; sync keeps them with status 'cancelled'
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'scheduled';
+ALTER TABLE segments DROP CONSTRAINT IF EXISTS ck_segment_status;
+ALTER TABLE segments ADD CONSTRAINT ck_segment_status CHECK (status IN ('scheduled', 'cancelled'));
+
+CREATE TABLE IF NOT EXISTS disruptions (
+    id VARCHAR(36) PRIMARY KEY,
+    booking_id VARCHAR(36) NOT NULL,
+    passenger_id VARCHAR(36) NOT NULL,
+    segment_id VARCHAR(36) NOT NULL,
+    booked_segment_id VARCHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    delay BIGINT NOT NULL DEFAULT 0,
+    connection JSONB,
+    replaces TEXT[] NOT NULL DEFAULT '{}',
+    from_city VARCHAR(100) NOT NULL,
+    from_city_id VARCHAR(64),
+    to_city VARCHAR(100) NOT NULL,
+    to_city_id VARCHAR(64),
+    depart_after TIMESTAMP NOT NULL,
+    alternatives JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL,
+    change_id VARCHAR(36),
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP,
+
+    CONSTRAINT fk_disruptions_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT uq_disruptions_passenger_segment UNIQUE (booking_id, passenger_id, segment_id, type),
+    CONSTRAINT ck_disruption_type CHECK (type IN ('cancelled', 'delayed')),
+    CONSTRAINT ck_disruption_status CHECK (status IN ('proposed', 'rebooked', 'unresolved'))
+);
+
+COMMENT ON TABLE disruptions IS 'Booked journeys broken by cancelled or delayed segments, with re-routing alternatives';
+COMMENT ON COLUMN disruptions.delay IS 'Arrival delay of the segment in nanoseconds';
+COMMENT ON COLUMN disruptions.connection IS 'The connection that can no longer be made';
+COMMENT ON COLUMN disruptions.replaces IS 'Tickets the passenger can no longer use, in travel order';
+COMMENT ON COLUMN disruptions.change_id IS 'Booking change that rebooked the passenger';
+COMMENT ON COLUMN segments.status IS 'scheduled or cancelled by the carrier';
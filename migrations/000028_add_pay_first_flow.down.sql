@@ -0,0 +1,22 @@
+-- Remove pay-first flow
+DELETE FROM booking_saga_steps WHERE step_type = 'capture_payment';
+
+ALTER TABLE booking_saga_steps
+    DROP CONSTRAINT IF EXISTS ck_booking_saga_step_type;
+
+ALTER TABLE booking_saga_steps
+    ADD CONSTRAINT ck_booking_saga_step_type CHECK (step_type IN ('book_segment', 'process_payment'));
+
+ALTER TABLE payments DROP COLUMN IF EXISTS two_stage;
+
+UPDATE payments SET status = 'pending' WHERE status = 'authorized';
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS ck_payment_status;
+
+ALTER TABLE payments
+    ADD CONSTRAINT ck_payment_status CHECK (
+        status IN ('pending', 'completed', 'failed', 'refunded')
+    );
+
+COMMENT ON COLUMN payments.status IS 'Payment status: pending, completed, failed, refunded';
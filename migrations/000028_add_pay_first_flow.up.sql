@@ -0,0 +1,24 @@
+-- Pay-first flow
+-- The customer's funds are authorized before any provider ticket is issued and captured
+-- once every ticket is issued; a failed ticket cancels the authorization instead.
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS ck_payment_status;
+
+ALTER TABLE payments
+    ADD CONSTRAINT ck_payment_status CHECK (
+        status IN ('pending', 'authorized', 'completed', 'failed', 'refunded')
+    );
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS two_stage BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE booking_saga_steps
+    DROP CONSTRAINT IF EXISTS ck_booking_saga_step_type;
+
+ALTER TABLE booking_saga_steps
+    ADD CONSTRAINT ck_booking_saga_step_type CHECK (
+        step_type IN ('book_segment', 'process_payment', 'capture_payment')
+    );
+
+COMMENT ON COLUMN payments.status IS 'Payment status: pending, authorized, completed, failed, refunded';
+COMMENT ON COLUMN payments.two_stage IS 'Authorized first and captured after the tickets are issued (pay-first flow)';
@@ -0,0 +1,2 @@
+-- Remove webhook events
+DROP TABLE IF EXISTS webhook_events;
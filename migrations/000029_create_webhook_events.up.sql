@@ -0,0 +1,16 @@
+-- Webhook events
+-- Payment gateway notifications already processed. Gateways retry a notification until
+-- it is acknowledged, so a retried or duplicated one is recognised here and ignored.
+
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id VARCHAR(255) PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_object ON webhook_events(provider, object_id);
+
+COMMENT ON TABLE webhook_events IS 'Processed payment gateway notifications (idempotency)';
+COMMENT ON COLUMN webhook_events.id IS 'provider:event:object_id, e.g. yookassa:payment.succeeded:2d8f...';
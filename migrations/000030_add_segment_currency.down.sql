@@ -0,0 +1,2 @@
+-- Remove segment currency
+ALTER TABLE segments DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,7 @@
+-- Segment currency
+-- Providers may quote prices in other currencies than rubles. The segment keeps the
+-- quoted price and its currency; bookings are priced in rubles after conversion.
+
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+
+COMMENT ON COLUMN segments.currency IS 'ISO 4217 currency of price, e.g. RUB, USD';
@@ -0,0 +1,2 @@
+-- Remove payment receipts
+ALTER TABLE payments DROP COLUMN IF EXISTS receipt;
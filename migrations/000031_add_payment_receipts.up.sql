@@ -0,0 +1,7 @@
+-- Payment receipts
+-- The fiscal receipt (54-FZ) sent with a payment: tickets, commission and insurance
+-- itemized with their VAT rates. Refund receipts are built from it.
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS receipt JSONB;
+
+COMMENT ON COLUMN payments.receipt IS 'Fiscal receipt (54-FZ) of everything paid, NULL for payments without one';
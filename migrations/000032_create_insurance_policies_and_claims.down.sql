@@ -0,0 +1,3 @@
+-- Drop insurance policies and claims
+DROP TABLE IF EXISTS insurance_claims;
+DROP TABLE IF EXISTS insurance_policies;
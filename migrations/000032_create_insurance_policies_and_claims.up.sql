@@ -0,0 +1,61 @@
+-- Insurance policies and claims
+-- A booking with insurance gets a policy from the insurer once it is confirmed.
+-- A passenger who missed a connection because the previous leg arrived late can claim
+-- under it: the claim is checked against the synced arrival and, when approved, the
+-- missed ticket is paid out through a refund. Rejected claims are kept with the reason.
+
+CREATE TABLE IF NOT EXISTS insurance_policies (
+    booking_id VARCHAR(36) PRIMARY KEY,
+    number VARCHAR(64) NOT NULL UNIQUE,
+    provider VARCHAR(50) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    premium DECIMAL(10, 2) NOT NULL,
+    coverage_limit DECIMAL(10, 2) NOT NULL,
+    paid_out DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    valid_from TIMESTAMP NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_insurance_policies_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT ck_policy_status CHECK (status IN ('active', 'cancelled')),
+    CONSTRAINT ck_policy_paid_out CHECK (paid_out >= 0 AND paid_out <= coverage_limit)
+);
+
+CREATE TABLE IF NOT EXISTS insurance_claims (
+    id VARCHAR(36) PRIMARY KEY,
+    booking_id VARCHAR(36) NOT NULL,
+    policy_number VARCHAR(64) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    passenger_id VARCHAR(36) NOT NULL,
+    booked_segment_id VARCHAR(36) NOT NULL,
+    inbound_ticket_id VARCHAR(36) NOT NULL,
+    scheduled_arrival TIMESTAMP NOT NULL,
+    actual_arrival TIMESTAMP NOT NULL,
+    delay BIGINT NOT NULL DEFAULT 0,
+    connection JSONB,
+    status VARCHAR(20) NOT NULL,
+    payout DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    rejection_reason TEXT,
+    provider_claim_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_insurance_claims_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT ck_claim_type CHECK (type IN ('missed_connection')),
+    CONSTRAINT ck_claim_status CHECK (status IN ('paid', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_insurance_claims_booking ON insurance_claims(booking_id);
+-- A ticket is paid out once; rejected claims can be made again
+CREATE UNIQUE INDEX IF NOT EXISTS uq_insurance_claims_paid_ticket ON insurance_claims(booked_segment_id) WHERE status = 'paid';
+
+COMMENT ON TABLE insurance_policies IS 'Travel insurance policies issued for confirmed bookings';
+COMMENT ON COLUMN insurance_policies.coverage_limit IS 'Most that is paid out over all claims';
+COMMENT ON COLUMN insurance_policies.paid_out IS 'Sum of the claims paid out';
+COMMENT ON TABLE insurance_claims IS 'Missed-connection claims under insurance policies';
+COMMENT ON COLUMN insurance_claims.booked_segment_id IS 'The ticket that was missed';
+COMMENT ON COLUMN insurance_claims.inbound_ticket_id IS 'The ticket the passenger arrived on';
+COMMENT ON COLUMN insurance_claims.delay IS 'Arrival delay of the inbound segment in nanoseconds';
+COMMENT ON COLUMN insurance_claims.connection IS 'The connection as it turned out';
+COMMENT ON COLUMN insurance_claims.payout IS 'Refunded to the customer, 0 for rejected claims';
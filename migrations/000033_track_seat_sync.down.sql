@@ -0,0 +1,3 @@
+-- Drop seat sync tracking
+ALTER TABLE seat_holds DROP COLUMN IF EXISTS confirmed_at;
+ALTER TABLE segments DROP COLUMN IF EXISTS seats_synced_at;
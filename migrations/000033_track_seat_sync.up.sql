@@ -0,0 +1,11 @@
+-- Seat sync tracking
+-- segments.seat_count is the provider's figure and every sync overwrites it, so seats
+-- are no longer taken off it on confirmation. A confirmed hold counts against it until
+-- the next sync after its confirmation, by which time the provider reflects the sale:
+-- free seats = seat_count - active holds - holds confirmed after seats_synced_at.
+-- Holds confirmed before this migration were taken off seat_count already.
+
+ALTER TABLE segments ADD COLUMN IF NOT EXISTS seats_synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE seat_holds ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP;
+UPDATE seat_holds SET confirmed_at = updated_at WHERE status = 'confirmed' AND confirmed_at IS NULL;
@@ -0,0 +1,5 @@
+-- Drop payment charges
+DELETE FROM payments WHERE charge_of IS NOT NULL;
+DROP INDEX IF EXISTS idx_payments_order_payment;
+ALTER TABLE payments ADD CONSTRAINT unique_payment_per_order UNIQUE (order_id);
+ALTER TABLE payments DROP COLUMN IF EXISTS charge_of;
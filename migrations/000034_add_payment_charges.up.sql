@@ -0,0 +1,9 @@
+-- Payment charges
+-- Amounts charged after a booking was paid (e.g. the fare difference of a booking change)
+-- are payments of their own, with their own receipt, refunded against their own gateway
+-- payment. charge_of points to the booking's payment; a booking still has one of those.
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS charge_of VARCHAR(36) REFERENCES payments(id) ON DELETE CASCADE;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS unique_payment_per_order;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_order_payment ON payments(order_id) WHERE charge_of IS NULL;
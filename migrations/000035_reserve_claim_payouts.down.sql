@@ -0,0 +1,8 @@
+-- Drop claim payout reservations (pending claims are taken as paid out)
+DROP INDEX IF EXISTS uq_insurance_claims_approved_ticket;
+UPDATE insurance_claims SET status = 'paid' WHERE status = 'pending';
+
+ALTER TABLE insurance_claims DROP CONSTRAINT IF EXISTS ck_claim_status;
+ALTER TABLE insurance_claims ADD CONSTRAINT ck_claim_status CHECK (status IN ('paid', 'rejected'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_insurance_claims_paid_ticket ON insurance_claims(booked_segment_id) WHERE status = 'paid';
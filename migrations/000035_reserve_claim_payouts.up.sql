@@ -0,0 +1,12 @@
+-- Reserve claim payouts
+-- An approved claim is recorded as pending before the insurer and the payment gateway
+-- are called, and becomes paid once refunded. The unique index covers pending claims
+-- too, so two claims for the same ticket made at once cannot both be paid out, and a
+-- payout that failed half way stays on record.
+
+ALTER TABLE insurance_claims DROP CONSTRAINT IF EXISTS ck_claim_status;
+ALTER TABLE insurance_claims ADD CONSTRAINT ck_claim_status CHECK (status IN ('pending', 'paid', 'rejected'));
+
+DROP INDEX IF EXISTS uq_insurance_claims_paid_ticket;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_insurance_claims_approved_ticket
+    ON insurance_claims(booking_id, booked_segment_id) WHERE status IN ('pending', 'paid');
@@ -0,0 +1,3 @@
+-- Drop pending ticket refunds (refunds still pending have to be made by hand)
+DROP INDEX IF EXISTS idx_booked_segments_refund_pending;
+ALTER TABLE booked_segments DROP COLUMN IF EXISTS refund_pending;
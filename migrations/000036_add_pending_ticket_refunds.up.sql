@@ -0,0 +1,8 @@
+-- Pending ticket refunds
+-- A ticket cancelled at the provider is recorded as cancelled before its refund is
+-- made, so a failed refund leaves the ticket flagged for the background retry instead
+-- of a ticket that is gone at the carrier but still confirmed here.
+
+ALTER TABLE booked_segments ADD COLUMN IF NOT EXISTS refund_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_booked_segments_refund_pending ON booked_segments(booking_id) WHERE refund_pending;
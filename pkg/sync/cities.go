@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenalink/backend/internal/domain"
+)
+
+// loadCities loads the city directory used to normalize synced stops.
+func (s *service) loadCities(ctx context.Context) error {
+	cities, err := s.cityRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading cities: %w", err)
+	}
+
+	s.cities = domain.NewCityDirectory(cities)
+	return nil
+}
+
+// saveCity merges a provider city into the directory and persists it.
+// A city already known under another spelling or code keeps its canonical ID;
+// provider spellings are stored as aliases.
+func (s *service) saveCity(ctx context.Context, city *domain.City, source string) (*domain.City, error) {
+	if s.cities == nil {
+		if err := s.loadCities(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if existing, ok := s.resolveCity(city); ok {
+		known := make(map[string]bool)
+		for _, name := range existing.Names() {
+			known[name] = true
+		}
+
+		// Only spellings new to the city become aliases
+		merged := *existing
+		merged.Aliases = nil
+		for _, name := range []string{city.Name, city.NameEn, city.RZDCode} {
+			if name != "" && !known[name] {
+				merged.Aliases = append(merged.Aliases, name)
+			}
+		}
+		if merged.IATACode == "" {
+			merged.IATACode = city.IATACode
+		}
+		if merged.RZDCode == "" {
+			merged.RZDCode = city.RZDCode
+		}
+		city = &merged
+	}
+
+	if err := s.cityRepo.Upsert(ctx, city, source); err != nil {
+		return nil, err
+	}
+
+	return s.cities.Add(*city), nil
+}
+
+// resolveCity finds a city already in the directory by code or by name.
+func (s *service) resolveCity(city *domain.City) (*domain.City, bool) {
+	for _, key := range []string{city.IATACode, city.RZDCode, city.ID, city.Name, city.NameEn} {
+		if key == "" {
+			continue
+		}
+		if existing, ok := s.cities.Resolve(key); ok {
+			return existing, true
+		}
+	}
+	return nil, false
+}
+
+// normalizeStopCity links a stop to its canonical city, creating the city if it is new.
+// The provider's city name is kept on the stop since it is part of the stop's unique key.
+func (s *service) normalizeStopCity(ctx context.Context, stop *domain.Stop, source string) error {
+	if stop.City == "" {
+		return nil
+	}
+
+	if s.cities == nil {
+		if err := s.loadCities(ctx); err != nil {
+			return err
+		}
+	}
+
+	city, ok := s.cities.Resolve(stop.City)
+	if !ok {
+		created := domain.NewCity(stop.City)
+		created.Latitude = stop.Latitude
+		created.Longitude = stop.Longitude
+
+		saved, err := s.saveCity(ctx, &created, source)
+		if err != nil {
+			return err
+		}
+		city = saved
+	}
+
+	stop.CityID = city.ID
+	return nil
+}
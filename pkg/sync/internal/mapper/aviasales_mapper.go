@@ -26,6 +26,26 @@ func AviasalesAirportToDomain(airport aviasales.Airport) (*domain.Stop, error) {
 	}, nil
 }
 
+// AviasalesCityToDomain converts Aviasales City to domain.City
+// The cities endpoint is English-only, so the name is used for both scripts
+// until the city is merged with an existing Russian entry.
+func AviasalesCityToDomain(city aviasales.City) *domain.City {
+	name := city.Name
+	if city.NameTranslations.En != "" {
+		name = city.NameTranslations.En
+	}
+
+	return &domain.City{
+		ID:          domain.CityKey(name),
+		Name:        name,
+		NameEn:      name,
+		IATACode:    city.Code,
+		CountryCode: city.CountryCode,
+		Latitude:    city.Coordinates.Lat,
+		Longitude:   city.Coordinates.Lon,
+	}
+}
+
 // AviasalesFlightToSegment converts Aviasales Flight to domain.Segment.
 // Note: Flight origin/destination are city codes, not airport codes.
 // We use airports map to find the main airport for each city.
@@ -81,11 +101,13 @@ func AviasalesFlightToSegment(flight aviasales.Flight, airports map[string]avias
 		ID:              segmentID,
 		TransportType:   domain.TransportAir,
 		Provider:        fmt.Sprintf("Aviasales (%s)", flight.Gate),
+		Source:          "aviasales", // sync.ProviderAviasales
+		Tariff:          aviasalesTariff(flight.TripClass),
 		StartStop:       *startStop,
 		EndStop:         *endStop,
 		DepartureTime:   departureTime,
 		ArrivalTime:     arrivalTime,
-		Price:           flight.Value,
+		Price:           domain.MoneyFromFloat(flight.Value, domain.CurrencyRUB), // Prices are requested in rubles
 		Duration:        time.Duration(flight.Duration) * time.Minute,
 		SeatCount:       100, // Default seat count as not provided by API
 		ReliabilityRate: 90.0, // Default reliability rate for airlines
@@ -93,6 +115,18 @@ func AviasalesFlightToSegment(flight aviasales.Flight, airports map[string]avias
 	}, nil
 }
 
+// aviasalesTariff returns the tariff name of an Aviasales trip class
+func aviasalesTariff(tripClass int) string {
+	switch tripClass {
+	case 1:
+		return "business"
+	case 2:
+		return "first"
+	default:
+		return "economy"
+	}
+}
+
 // estimateDistance estimates distance between two coordinates using Haversine formula
 func estimateDistance(lat1, lon1, lat2, lon2 float64) int {
 	const earthRadius = 6371.0 // Earth radius in km
@@ -10,6 +10,9 @@ import (
 	"github.com/lenalink/backend/pkg/sync/api/gars"
 )
 
+// defaultBusSeats is used when GARS has no seat availability for a trip
+const defaultBusSeats = 40
+
 // GarsStopToDomain converts GARS Stop to domain.Stop
 func GarsStopToDomain(garsStop gars.Stop) (*domain.Stop, error) {
 	lat, lon, err := parseCoordinates(garsStop.Coordinates)
@@ -84,12 +87,14 @@ func GarsScheduleToSegment(
 	duration := arrivalTime.Sub(departureTime)
 
 	// Extract price and seat count
-	price := 0.0
+	price := domain.Money{Currency: domain.CurrencyRUB}
+	tariff := ""
 	if fare != nil {
-		price = fare.Price
+		price = domain.MoneyFromFloat(fare.Price, garsCurrency(fare.Currency))
+		tariff = fare.FareType
 	}
 
-	seatCount := 0
+	seatCount := defaultBusSeats
 	if seats != nil {
 		seatCount = seats.FreeSeats
 	}
@@ -98,6 +103,8 @@ func GarsScheduleToSegment(
 		ID:              schedule.RefKey,
 		TransportType:   domain.TransportBus, // GARS is for buses
 		Provider:        "АвиБус (ГАРС)",
+		Source:          "gars", // sync.ProviderGARS
+		Tariff:          tariff,
 		StartStop:       *startStop,
 		EndStop:         *endStop,
 		DepartureTime:   departureTime,
@@ -107,9 +114,19 @@ func GarsScheduleToSegment(
 		SeatCount:       seatCount,
 		ReliabilityRate: 85.0, // Default reliability rate for GARS
 		Distance:        int(lastStop.Distance),
+		Status:          garsScheduleStatus(schedule.State),
 	}, nil
 }
 
+// garsScheduleStatus maps the GARS schedule state: cancelled and suspended trips do not run
+func garsScheduleStatus(state string) domain.SegmentStatus {
+	state = strings.ToLower(state)
+	if strings.HasPrefix(state, "отмен") || strings.HasPrefix(state, "приостановлен") {
+		return domain.SegmentCancelled
+	}
+	return domain.SegmentScheduled
+}
+
 // parseCoordinates parses "latitude,longitude" string
 func parseCoordinates(coords string) (lat, lon float64, err error) {
 	if coords == "" {
@@ -168,3 +185,53 @@ func parseGarsTime(timeStr string, date time.Time) (time.Time, error) {
 		time.UTC,
 	), nil
 }
+
+// GarsFeesToFareRule builds the GARS fare rule from its fees catalog on top of the
+// default bus rule. Return fees ("возврат") set the penalty of the earliest tier,
+// rebooking fees ("переоформление", "обмен") the change fee; the later tiers keep
+// the federal passenger transport rules. Reports false if no fee applies.
+func GarsFeesToFareRule(fees []gars.Fee, base domain.FareRule) (*domain.FareRule, bool) {
+	rule := base
+	rule.ID = "gars"
+	rule.Provider = "gars" // sync.ProviderGARS
+	rule.Tariff = ""
+	rule.Source = domain.FareRuleSourceGARS
+	rule.UpdatedAt = time.Now()
+	rule.Tiers = append([]domain.PenaltyTier(nil), base.Tiers...)
+
+	found := false
+	for _, fee := range fees {
+		if fee.Archived {
+			continue
+		}
+
+		name := strings.ToLower(fee.Description)
+		percent := fee.CalculationMethod == "Процент"
+
+		switch {
+		case strings.Contains(name, "возврат") && len(rule.Tiers) > 0:
+			if percent {
+				rule.Tiers[0].Penalty = fee.Amount / 100
+			} else {
+				rule.Tiers[0].Fee = domain.Rubles(fee.Amount)
+			}
+			found = true
+		case (strings.Contains(name, "переоформ") || strings.Contains(name, "обмен")) && !percent:
+			rule.ChangeFee = domain.Rubles(fee.Amount)
+			rule.Changeable = true
+			found = true
+		}
+	}
+
+	return &rule, found
+}
+
+// garsCurrency returns the currency of a GARS fare
+// Fares are in rubles unless they name an ISO currency code.
+func garsCurrency(code string) domain.Currency {
+	currency, err := domain.ParseCurrency(code)
+	if err != nil {
+		return domain.CurrencyRUB
+	}
+	return currency
+}
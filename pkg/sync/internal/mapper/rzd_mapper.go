@@ -19,6 +19,17 @@ func RzdStationToDomain(station rzd.Station) (*domain.Stop, error) {
 	}, nil
 }
 
+// RzdStationToCity converts the city of an RZD Station to domain.City
+// The station code is recorded as the city's RZD code.
+func RzdStationToCity(station rzd.Station) *domain.City {
+	city := domain.NewCity(station.City)
+	city.RZDCode = station.Code
+	city.CountryCode = station.Country
+	city.Latitude = station.Latitude
+	city.Longitude = station.Longitude
+	return &city
+}
+
 // RzdTrainToSegment converts RZD Train to domain.Segment
 func RzdTrainToSegment(train rzd.Train, stations map[string]rzd.Station, ticket *rzd.Ticket) (*domain.Segment, error) {
 	// Get origin and destination stations
@@ -60,11 +71,13 @@ func RzdTrainToSegment(train rzd.Train, stations map[string]rzd.Station, ticket
 		ID:              segmentID,
 		TransportType:   domain.TransportRail,
 		Provider:        fmt.Sprintf("РЖД (%s, %s)", train.TrainNumber, carType),
+		Source:          "rzd", // sync.ProviderRZD
+		Tariff:          carType,
 		StartStop:       *startStop,
 		EndStop:         *endStop,
 		DepartureTime:   train.DepartureTime,
 		ArrivalTime:     train.ArrivalTime,
-		Price:           price,
+		Price:           domain.Rubles(price),
 		Duration:        train.ArrivalTime.Sub(train.DepartureTime),
 		SeatCount:       seatCount,
 		ReliabilityRate: 92.0, // Default reliability rate for trains
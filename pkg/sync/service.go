@@ -21,6 +21,11 @@ type service struct {
 	rzdClient       *rzd.MockClient
 	stopRepo        repository.StopRepository
 	segmentRepo     repository.SegmentRepository
+	cityRepo        repository.CityRepository
+	fareRuleRepo    repository.FareRuleRepository
+
+	// cities resolves provider city names to canonical cities (loaded lazily)
+	cities *domain.CityDirectory
 }
 
 // Ensure service implements Syncer interface.
@@ -118,6 +123,10 @@ func (s *service) syncGarsData(ctx context.Context) error {
 			continue
 		}
 
+		if err := s.normalizeStopCity(ctx, domainStop, string(ProviderGARS)); err != nil {
+			log.Printf("Error resolving city of stop %s: %v", domainStop.ID, err)
+		}
+
 		if err := s.stopRepo.Upsert(ctx, domainStop); err != nil {
 			log.Printf("Error saving stop %s: %v", domainStop.ID, err)
 			continue
@@ -127,6 +136,11 @@ func (s *service) syncGarsData(ctx context.Context) error {
 
 	log.Printf("Saved %d stops from GARS", stopsCount)
 
+	// Refund and rebooking fees become the GARS fare rule
+	if err := s.syncGarsFareRules(ctx, garsService); err != nil {
+		log.Printf("Warning: Error syncing GARS fare rules: %v", err)
+	}
+
 	// Fetch schedules for next 30 days
 	startDate := time.Now()
 	endDate := startDate.AddDate(0, 0, 30)
@@ -176,15 +190,23 @@ func (s *service) syncGarsData(ctx context.Context) error {
 				fare = &fares[0]
 			}
 
+			// Try to fetch free seats for the trip date (optional)
+			var seats *gars.SeatAvailability
+			seatFilter := fmt.Sprintf("РейсРасписание_Key eq guid'%s' and Дата eq datetime'%sT00:00:00'", schedule.RefKey, tripDate.Format("2006-01-02"))
+			availability, _, err := garsService.SeatAvailability(ctx, gars.WithFilter(seatFilter), gars.WithTop(1))
+			if err == nil && len(availability) > 0 {
+				seats = &availability[0]
+			}
+
 			// Convert to segment
-			segment, err := mapper.GarsScheduleToSegment(schedule, tripStops, stopMap, fare, nil, tripDate)
+			segment, err := mapper.GarsScheduleToSegment(schedule, tripStops, stopMap, fare, seats, tripDate)
 			if err != nil {
 				log.Printf("Warning: Error converting schedule %s to segment: %v", schedule.RefKey, err)
 				continue
 			}
 
-			// Save segment
-			if err := s.segmentRepo.Save(ctx, segment); err != nil {
+			// Upsert so that a re-sync picks up reschedules and cancellations
+			if err := s.segmentRepo.BatchSave(ctx, []domain.Segment{*segment}); err != nil {
 				log.Printf("Warning: Error saving segment %s: %v", segment.ID, err)
 				continue
 			}
@@ -197,6 +219,28 @@ func (s *service) syncGarsData(ctx context.Context) error {
 	return nil
 }
 
+// syncGarsFareRules derives the GARS fare rule from its fees catalog.
+func (s *service) syncGarsFareRules(ctx context.Context, garsService *gars.Service) error {
+	fees, _, err := garsService.Fees(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching GARS fees: %w", err)
+	}
+
+	base := domain.DefaultFareRules().For("", "", domain.TransportBus)
+	rule, ok := mapper.GarsFeesToFareRule(fees, base)
+	if !ok {
+		log.Printf("No refund or rebooking fees among %d GARS fees, keeping default bus rule", len(fees))
+		return nil
+	}
+
+	if err := s.fareRuleRepo.Upsert(ctx, rule); err != nil {
+		return fmt.Errorf("error saving GARS fare rule: %w", err)
+	}
+
+	log.Printf("Saved GARS fare rule from %d fees", len(fees))
+	return nil
+}
+
 // syncAviasalesData synchronizes data from Aviasales API.
 func (s *service) syncAviasalesData(ctx context.Context) error {
 	log.Println("Syncing Aviasales data...")
@@ -230,6 +274,11 @@ func (s *service) syncAviasalesData(ctx context.Context) error {
 		}
 	}
 
+	// Register Russian cities so airports (which only carry the city code) resolve to them
+	if err := s.syncAviasalesCities(ctx); err != nil {
+		log.Printf("Error syncing Aviasales cities: %v", err)
+	}
+
 	// Save Russian airports to database
 	airportsCount := 0
 	for _, airport := range russianAirports {
@@ -239,6 +288,10 @@ func (s *service) syncAviasalesData(ctx context.Context) error {
 			continue
 		}
 
+		if err := s.normalizeStopCity(ctx, domainStop, string(ProviderAviasales)); err != nil {
+			log.Printf("Error resolving city of airport %s: %v", airport.Code, err)
+		}
+
 		if err := s.stopRepo.Upsert(ctx, domainStop); err != nil {
 			log.Printf("Error saving airport %s: %v", airport.Code, err)
 			continue
@@ -352,6 +405,30 @@ func (s *service) syncAviasalesData(ctx context.Context) error {
 	return nil
 }
 
+// syncAviasalesCities saves Russian cities from Aviasales with their IATA codes.
+func (s *service) syncAviasalesCities(ctx context.Context) error {
+	cities, err := s.aviasalesClient.GetCities(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching cities: %w", err)
+	}
+
+	citiesCount := 0
+	for _, city := range cities {
+		if city.CountryCode != "RU" {
+			continue
+		}
+
+		if _, err := s.saveCity(ctx, mapper.AviasalesCityToDomain(city), string(ProviderAviasales)); err != nil {
+			log.Printf("Error saving city %s: %v", city.Code, err)
+			continue
+		}
+		citiesCount++
+	}
+
+	log.Printf("Saved %d cities from Aviasales", citiesCount)
+	return nil
+}
+
 // syncRzdData synchronizes mock data from RZD client.
 func (s *service) syncRzdData(ctx context.Context) error {
 	log.Println("Syncing RZD mock data...")
@@ -376,6 +453,13 @@ func (s *service) syncRzdData(ctx context.Context) error {
 			continue
 		}
 
+		if _, err := s.saveCity(ctx, mapper.RzdStationToCity(station), string(ProviderRZD)); err != nil {
+			log.Printf("Error saving city of station %s: %v", station.Code, err)
+		}
+		if err := s.normalizeStopCity(ctx, domainStop, string(ProviderRZD)); err != nil {
+			log.Printf("Error resolving city of station %s: %v", station.Code, err)
+		}
+
 		if err := s.stopRepo.Upsert(ctx, domainStop); err != nil {
 			log.Printf("Error saving station %s: %v", station.Code, err)
 			continue
@@ -20,6 +20,8 @@ func New(
 	rzdClient *rzd.MockClient,
 	stopRepo repository.StopRepository,
 	segmentRepo repository.SegmentRepository,
+	cityRepo repository.CityRepository,
+	fareRuleRepo repository.FareRuleRepository,
 ) Syncer {
 	return &service{
 		garsClient:      garsClient,
@@ -27,6 +29,8 @@ func New(
 		rzdClient:       rzdClient,
 		stopRepo:        stopRepo,
 		segmentRepo:     segmentRepo,
+		cityRepo:        cityRepo,
+		fareRuleRepo:    fareRuleRepo,
 	}
 }
 
@@ -70,7 +74,9 @@ func RunSync(ctx context.Context,
 	rzdClient *rzd.MockClient,
 	stopRepo repository.StopRepository,
 	segmentRepo repository.SegmentRepository,
+	cityRepo repository.CityRepository,
+	fareRuleRepo repository.FareRuleRepository,
 ) error {
-	syncer := New(garsClient, aviasalesClient, rzdClient, stopRepo, segmentRepo)
+	syncer := New(garsClient, aviasalesClient, rzdClient, stopRepo, segmentRepo, cityRepo, fareRuleRepo)
 	return syncer.SyncAll(ctx)
 }
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillicToLatin maps lowercase Russian letters to their Latin spelling
+// (simplified BGN/PCGN, the romanization used on most maps and airline systems)
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// latinToCyrillic maps Latin letter groups back to Russian, longest first
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"v", "в"}, {"g", "г"}, {"d", "д"}, {"e", "е"},
+	{"z", "з"}, {"i", "и"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"},
+	{"o", "о"}, {"p", "п"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"},
+	{"f", "ф"}, {"h", "х"}, {"c", "ц"}, {"w", "в"}, {"j", "й"}, {"q", "к"},
+	{"x", "кс"},
+}
+
+// ToLatin transliterates Russian text to Latin letters ("Якутск" -> "Yakutsk")
+// Non-Cyrillic characters are kept as is.
+func ToLatin(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		latin, ok := cyrillicToLatin[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+
+	return b.String()
+}
+
+// ToCyrillic transliterates Latin text to Russian letters ("Neryungri" -> "Нерюнгри")
+// It is a best-effort reverse of ToLatin for names missing from the city directory.
+func ToCyrillic(s string) string {
+	runes := []rune(s)
+	lower := []rune(strings.ToLower(s))
+
+	var b strings.Builder
+	b.Grow(len(s) * 2)
+
+	for i := 0; i < len(lower); {
+		// "y" before a vowel is part of ю/я/ё/е; otherwise it is й after
+		// a vowel ("Batagay", "Mirnyy") and ы elsewhere ("Mirny")
+		if lower[i] == 'y' && (i+1 >= len(lower) || !strings.ContainsRune("uaoe", lower[i+1])) {
+			if i > 0 && isLatinVowel(lower[i-1]) {
+				b.WriteString(matchCase("й", runes[i]))
+			} else {
+				b.WriteString(matchCase("ы", runes[i]))
+			}
+			i++
+			continue
+		}
+
+		matched := false
+		for _, m := range latinToCyrillic {
+			n := len(m.latin)
+			if i+n <= len(lower) && string(lower[i:i+n]) == m.latin {
+				b.WriteString(matchCase(m.cyrillic, runes[i]))
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteRune(runes[i])
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// matchCase capitalizes the first letter of cyrillic if original is uppercase
+func matchCase(cyrillic string, original rune) string {
+	if !unicode.IsUpper(original) {
+		return cyrillic
+	}
+	r := []rune(cyrillic)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func isLatinVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}